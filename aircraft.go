@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+const defaultAircraftAlerts = 50
+
+// AircraftState is the latest known state of a tracked aircraft.
+type AircraftState struct {
+	Aircraft
+	LastSeen time.Time `json:"last_seen"`
+	Seen     float64   `json:"seen"` // Seconds since the last report
+	// Registration and Route are filled in from the registration database
+	// and the routes API.
+	Registration *Registration `json:"registration,omitempty"`
+	Route        *Route        `json:"route,omitempty"`
+}
+
+// newAircraftState returns the state of an aircraft last heard at last,
+// dead-reckoned to now if it has gone quiet.
+func newAircraftState(last Aircraft, now time.Time) AircraftState {
+	state := AircraftState{
+		Aircraft:     last,
+		LastSeen:     last.Timestamp,
+		Seen:         now.Sub(last.Timestamp).Seconds(),
+		Registration: lookupRegistration(last.ICAO),
+		Route:        lookupRoute(last),
+	}
+	if estimate, ok := deadReckon(last, now); ok {
+		state.Aircraft = estimate
+	}
+	return state
+}
+
+// latestState returns the registered state of icao.
+func latestState(icao string, now time.Time) (AircraftState, bool) {
+	last, ok := lookupState(icao)
+	if !ok {
+		return AircraftState{}, false
+	}
+	return newAircraftState(last, now), true
+}
+
+// trackedAircraft returns the latest state of every aircraft heard from
+// within maxAge, ordered by ICAO; with box, only of those inside it.
+func trackedAircraft(maxAge time.Duration, box *geoBox) []AircraftState {
+	now := time.Now()
+	states := []AircraftState{}
+	all := allStates
+	if box != nil {
+		all = func() []Aircraft { return aircraftIn(*box) }
+	}
+	for _, last := range all() {
+		if now.Sub(last.Timestamp) <= maxAge {
+			states = append(states, newAircraftState(last, now))
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ICAO < states[j].ICAO })
+	return states
+}
+
+// AircraftEnrichment is what is known about an aircraft beyond its reports.
+type AircraftEnrichment struct {
+	Zones          []string       `json:"zones"` // Zones the aircraft is inside
+	NearestAirport *NearbyAirport `json:"nearest_airport,omitempty"`
+}
+
+// NearbyAirport is an airport with its distance and bearing from an aircraft.
+type NearbyAirport struct {
+	Airport
+	Distance float64 `json:"distance"` // Nautical miles
+	Bearing  string  `json:"bearing"`  // Compass point from the aircraft
+}
+
+// enrichAircraft looks up the zones and nearest airport of an aircraft.
+// Callers must hold mu.
+func enrichAircraft(aircraft Aircraft) AircraftEnrichment {
+	e := AircraftEnrichment{Zones: append([]string{}, zonesContaining(aircraft)...)}
+	sort.Strings(e.Zones)
+	for _, a := range airports {
+		d := distanceNM(aircraft.Latitude, aircraft.Longitude, a.Latitude, a.Longitude)
+		if e.NearestAirport == nil || d < e.NearestAirport.Distance {
+			e.NearestAirport = &NearbyAirport{Airport: a, Distance: d}
+		}
+	}
+	if n := e.NearestAirport; n != nil {
+		n.Bearing = compassPoint(bearingDeg(aircraft.Latitude, aircraft.Longitude, n.Latitude, n.Longitude))
+	}
+	return e
+}
+
+// AircraftDetail is everything known about one aircraft, for a detail panel.
+type AircraftDetail struct {
+	State      *AircraftState      `json:"state"` // Nil once the aircraft has expired
+	Enrichment *AircraftEnrichment `json:"enrichment,omitempty"`
+	Track      []Aircraft          `json:"track"`  // Recent positions, oldest first
+	Alerts     []Alert             `json:"alerts"` // Newest first
+}
+
+// handleListAircraft returns the latest state of every tracked aircraft,
+// ordered by ICAO, so clients can draw the current picture without waiting
+// for SSE updates. ?max_age= (e.g. 60s) leaves out aircraft not heard from
+// within that time and ?bbox= aircraft outside the box; ?format=geojson
+// returns a FeatureCollection of points.
+// Pollers sending If-None-Match get 304 while nothing has changed.
+func handleListAircraft(c *jacked.Context) error {
+	maxAge := trackRetention
+	if v := c.Request.URL.Query().Get("max_age"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "max_age must be a positive duration, e.g. 60s"})
+		}
+		maxAge = d
+	}
+	var box *geoBox
+	if v := c.Request.URL.Query().Get("bbox"); v != "" {
+		b, err := parseBBox(v)
+		if err == nil && (b[0] > b[2] || b[1] > b[3]) {
+			err = errors.New("bbox must be min_lat,min_lon,max_lat,max_lon with the minimums first")
+		}
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		box = &b
+	}
+	states := trackedAircraft(maxAge, box)
+	if wantsGeoJSON(c) {
+		return writeTagged(c, geoJSONType, featureCollection(aircraftGeoJSON(states)))
+	}
+	return writeTagged(c, "application/json", states)
+}
+
+// handleDeleteAircraft drops a stale or spoofed aircraft from the tracked
+// state and tells stream clients to take it off the map. Its position
+// history is kept, and it comes back if it reports again.
+func handleDeleteAircraft(c *jacked.Context) error {
+	icao := strings.ToUpper(c.Param("icao"))
+	state, ok := latestState(icao, time.Now())
+	if ok && removeState(icao) {
+		mu.Lock()
+		delete(tracks, icao)
+		forgetAircraftState(icao)
+		mu.Unlock()
+		forgetOutlier(icao)
+		hub.broadcast <- []byte("event: aircraftRemoved\ndata: {\"icao\":\"" + icao + "\"}\n\n")
+	}
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Aircraft not found"})
+	}
+	recordAudit(c, "aircraft.delete", icao, fmt.Sprintf("callsign %q, last seen %s", state.Callsign, state.LastSeen.Format(time.RFC3339)))
+	logRequestf(c.Request, "Removed aircraft %s from the tracked state", icao)
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// handleAircraftDetail returns the latest state, enrichment, recent track
+// and alerts of one aircraft. ?alerts= caps the alerts returned (default 50).
+func handleAircraftDetail(c *jacked.Context) error {
+	icao := strings.ToUpper(c.Param("icao"))
+	maxAlerts := defaultAircraftAlerts
+	if v := c.Request.URL.Query().Get("alerts"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid alerts count"})
+		}
+		maxAlerts = n
+	}
+
+	detail := AircraftDetail{Track: []Aircraft{}, Alerts: []Alert{}}
+	mu.RLock()
+	if state, ok := latestState(icao, time.Now()); ok {
+		enrichment := enrichAircraft(state.Aircraft)
+		detail.State = &state
+		detail.Enrichment = &enrichment
+	}
+	detail.Track = append(detail.Track, tracks[icao]...)
+	mu.RUnlock()
+
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		return storeError(c, err)
+	}
+	for i := len(alerts) - 1; i >= 0 && len(detail.Alerts) < maxAlerts; i-- {
+		if alerts[i].Aircraft.ICAO == icao {
+			detail.Alerts = append(detail.Alerts, alerts[i])
+		}
+	}
+	if detail.State == nil && len(detail.Track) == 0 && len(detail.Alerts) == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Aircraft not found"})
+	}
+	return c.JSON(http.StatusOK, detail)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultAirportRadius = 5
+	// An aircraft counts as on the ground below this height above the
+	// airport and this ground speed.
+	groundMaxHeight = 300
+	groundMaxSpeed  = 80
+)
+
+// onGround records the last known ground/airborne phase per (criterion,
+// ICAO) for airport-ops criteria. Guarded by mu.
+var onGround = map[string]bool{}
+
+// validateAirportOps checks the parameters. Callers must hold mu.
+func validateAirportOps(p AirportOpsParams) error {
+	if len(p.Airports) == 0 {
+		return errors.New("airport_ops needs at least one airport")
+	}
+	for _, ident := range p.Airports {
+		if _, ok := airports[strings.ToUpper(ident)]; !ok {
+			return fmt.Errorf("unknown airport %q", ident)
+		}
+	}
+	if p.Radius < 0 {
+		return errors.New("airport_ops radius must not be negative")
+	}
+	return nil
+}
+
+// detectAirportOps reports takeoff and landing transitions of the aircraft
+// near one of the criterion's airports. The first sighting only establishes
+// the phase. Callers must hold mu.
+func detectAirportOps(criterion *AlertCriteria, aircraft Aircraft) (event, message string, ok bool) {
+	p := criterion.AirportOps
+	radius := p.Radius
+	if radius == 0 {
+		radius = defaultAirportRadius
+	}
+
+	var airport Airport
+	found := false
+	for _, ident := range p.Airports {
+		a, exists := airports[strings.ToUpper(ident)]
+		if exists && distanceNM(a.Latitude, a.Longitude, aircraft.Latitude, aircraft.Longitude) <= radius {
+			airport, found = a, true
+			break
+		}
+	}
+	key := criterion.ID + "|" + aircraft.ICAO
+	if !found {
+		delete(onGround, key)
+		return "", "", false
+	}
+
+	ground := aircraft.Altitude-airport.Elevation < groundMaxHeight && aircraft.Speed < groundMaxSpeed
+	wasGround, known := onGround[key]
+	onGround[key] = ground
+	if !known || wasGround == ground {
+		return "", "", false
+	}
+	if ground {
+		return eventLanding, fmt.Sprintf("%s (%s) landed at %s", aircraft.Callsign, aircraft.ICAO, airport.Ident), true
+	}
+	return eventTakeoff, fmt.Sprintf("%s (%s) took off from %s", aircraft.Callsign, aircraft.ICAO, airport.Ident), true
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// bundledAirports is the database used without airports_file: the large
+// airports of OurAirports (public domain), enough to name the airport an
+// airliner is arriving at or leaving but not the local airfields.
+//
+//go:embed data/airports.csv
+var bundledAirports string
+
+// maxNearestAirport is how far the nearest airport is looked for; aircraft
+// farther from any are annotated with none.
+const maxNearestAirport = 100
+
+// Airport is an entry of the airport database.
+type Airport struct {
+	Ident     string  `json:"ident"` // ICAO or local identifier, e.g. KLAX
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Elevation int     `json:"elevation"` // Feet above mean sea level
+	IATA      string  `json:"iata,omitempty"`
+}
+
+// airports holds the loaded airport database keyed by ident. Guarded by mu.
+var airports = map[string]Airport{}
+
+// airportGrid files the airports by cell for nearest-airport lookups. A
+// grid is never modified once built, so updates read it without mu.
+type airportGrid map[geoCell][]Airport
+
+var airportIndex atomic.Pointer[airportGrid]
+
+// loadAirports reads an OurAirports-style CSV file (columns ident, name,
+// latitude_deg, longitude_deg, elevation_ft, iata_code; others are ignored)
+// and replaces the airport database with it.
+func loadAirports(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	list, err := parseAirportsCSV(f)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	setAirports(list)
+	return nil
+}
+
+// loadBundledAirports replaces the airport database with bundledAirports.
+func loadBundledAirports() error {
+	list, err := parseAirportsCSV(strings.NewReader(bundledAirports))
+	if err != nil {
+		return fmt.Errorf("parsing bundled airports: %w", err)
+	}
+	setAirports(list)
+	return nil
+}
+
+func setAirports(list []Airport) {
+	loaded := make(map[string]Airport, len(list))
+	grid := airportGrid{}
+	for _, a := range list {
+		loaded[a.Ident] = a
+		cell := cellOf(a.Latitude, a.Longitude)
+		grid[cell] = append(grid[cell], a)
+	}
+	mu.Lock()
+	airports = loaded
+	mu.Unlock()
+	airportIndex.Store(&grid)
+}
+
+// nearestAirport returns the airport closest to a position within
+// maxNearestAirport and its distance in nautical miles. It searches a small
+// area first and widens it only when that holds no airport close enough to
+// be certainly the nearest.
+func nearestAirport(lat, lon float64) (Airport, float64, bool) {
+	grid := airportIndex.Load()
+	if grid == nil {
+		return Airport{}, 0, false
+	}
+	for _, nm := range []float64{10, 30, maxNearestAirport} {
+		var best Airport
+		bestDist := math.Inf(1)
+		for _, b := range boxesAround(lat, lon, nm) {
+			lo, hi, n := b.cells()
+			visit := func(cell geoCell) {
+				for _, a := range (*grid)[cell] {
+					if d := distanceNM(lat, lon, a.Latitude, a.Longitude); d < bestDist {
+						best, bestDist = a, d
+					}
+				}
+			}
+			if n > len(*grid) {
+				for cell := range *grid {
+					if cell.lat >= lo.lat && cell.lat <= hi.lat && cell.lon >= lo.lon && cell.lon <= hi.lon {
+						visit(cell)
+					}
+				}
+				continue
+			}
+			for y := lo.lat; y <= hi.lat; y++ {
+				for x := lo.lon; x <= hi.lon; x++ {
+					visit(geoCell{y, x})
+				}
+			}
+		}
+		if bestDist <= nm {
+			return best, bestDist, true
+		}
+	}
+	return Airport{}, 0, false
+}
+
+// nearAirport returns the aircraft with its nearest airport and the distance
+// to it filled in.
+func nearAirport(aircraft Aircraft) Aircraft {
+	aircraft.Airport, aircraft.AirportDistance = "", 0
+	if a, d, ok := nearestAirport(aircraft.Latitude, aircraft.Longitude); ok {
+		aircraft.Airport = a.Ident
+		aircraft.AirportDistance = math.Round(d*100) / 100
+	}
+	return aircraft
+}
+
+func parseAirportsCSV(r io.Reader) ([]Airport, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"ident", "latitude_deg", "longitude_deg"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var list []Airport
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lat, err1 := strconv.ParseFloat(field(rec, "latitude_deg"), 64)
+		lon, err2 := strconv.ParseFloat(field(rec, "longitude_deg"), 64)
+		if err1 != nil || err2 != nil || field(rec, "ident") == "" {
+			continue
+		}
+		elev, _ := strconv.Atoi(field(rec, "elevation_ft"))
+		list = append(list, Airport{
+			Ident:     strings.ToUpper(field(rec, "ident")),
+			Name:      field(rec, "name"),
+			Latitude:  lat,
+			Longitude: lon,
+			Elevation: elev,
+			IATA:      strings.ToUpper(field(rec, "iata_code")),
+		})
+	}
+	return list, nil
+}
+
+// nearCriterionAirport reports whether the aircraft is within the
+// criterion's airport radius of its airport. Callers must hold mu.
+func nearCriterionAirport(ac *AlertCriteria, aircraft Aircraft) bool {
+	a, ok := airports[strings.ToUpper(ac.Airport)]
+	if !ok {
+		return false
+	}
+	radius := ac.AirportRadius
+	if radius == 0 {
+		radius = defaultAirportRadius
+	}
+	return distanceNM(aircraft.Latitude, aircraft.Longitude, a.Latitude, a.Longitude) <= radius
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+const (
+	defaultAlertPageSize = 100
+	maxAlertPageSize     = 1000
+)
+
+// alertFilter selects alerts for GET /api/v1/alerts. Empty fields match
+// everything.
+type alertFilter struct {
+	since, until time.Time
+	icao         string
+	callsign     string
+	severities   []string
+	criterion    string
+	zone         *Zone
+	tags         []string
+	acknowledged *bool
+}
+
+// parseAlertFilter reads the filter from the query parameters since and
+// until (RFC 3339), icao, callsign, severity (comma separated), criterion,
+// zone, tag (repeatable) and acknowledged (true or false).
+func parseAlertFilter(q url.Values) (alertFilter, error) {
+	f := alertFilter{
+		icao:      strings.ToUpper(q.Get("icao")),
+		callsign:  strings.ToUpper(strings.TrimSpace(q.Get("callsign"))),
+		criterion: q.Get("criterion"),
+		tags:      q["tag"],
+	}
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &f.since}, {"until", &f.until}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, fmt.Errorf("%s must be an RFC 3339 time", p.name)
+			}
+			*p.t = t
+		}
+	}
+	if v := q.Get("severity"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			if _, ok := severityRank[s]; !ok {
+				return f, fmt.Errorf("unknown severity %q", s)
+			}
+			f.severities = append(f.severities, s)
+		}
+	}
+	if v := q.Get("acknowledged"); v != "" {
+		ack, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("acknowledged must be true or false")
+		}
+		f.acknowledged = &ack
+	}
+	if name := q.Get("zone"); name != "" {
+		mu.RLock()
+		zone, ok := zones[name]
+		mu.RUnlock()
+		if !ok {
+			return f, fmt.Errorf("unknown zone %q", name)
+		}
+		f.zone = &zone
+	}
+	return f, nil
+}
+
+// matches reports whether the alert passes the filter. An alert is in a
+// zone when its criterion watches the zone or the aircraft was inside it.
+func (f alertFilter) matches(a Alert) bool {
+	switch {
+	case !f.since.IsZero() && a.Timestamp.Before(f.since),
+		!f.until.IsZero() && !a.Timestamp.Before(f.until),
+		f.icao != "" && a.Aircraft.ICAO != f.icao,
+		f.callsign != "" && strings.ToUpper(strings.TrimSpace(a.Aircraft.Callsign)) != f.callsign,
+		len(f.severities) > 0 && !slices.Contains(f.severities, a.Severity),
+		f.criterion != "" && a.Criteria.ID != f.criterion,
+		f.acknowledged != nil && a.Acknowledged != *f.acknowledged,
+		!a.Criteria.HasTags(f.tags):
+		return false
+	}
+	if f.zone != nil && a.Criteria.Zone != f.zone.Name && !f.zone.contains(a.Aircraft.Latitude, a.Aircraft.Longitude) {
+		return false
+	}
+	return true
+}
+
+// parsePage reads ?limit= and ?offset=.
+func parsePage(q url.Values) (limit, offset int, err error) {
+	limit = defaultAlertPageSize
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxAlertPageSize {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxAlertPageSize)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must not be negative")
+		}
+	}
+	return limit, offset, nil
+}
+
+// handleListAlerts returns a page of matching alerts, newest first. The
+// total number of matches is in X-Total-Count and the next page, if any,
+// in a Link header. ?format=geojson returns the page as a FeatureCollection
+// of alert locations. Pollers sending If-None-Match get 304 while the page
+// is unchanged.
+func handleListAlerts(c *jacked.Context) error {
+	q := c.Request.URL.Query()
+	filter, err := parseAlertFilter(q)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	limit, offset, err := parsePage(q)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		return storeError(c, err)
+	}
+
+	var matched []Alert
+	for i := len(alerts) - 1; i >= 0; i-- {
+		if filter.matches(alerts[i]) {
+			matched = append(matched, alerts[i])
+		}
+	}
+	page := matched[min(offset, len(matched)):min(offset+limit, len(matched))]
+	c.Response.Header().Set("X-Total-Count", strconv.Itoa(len(matched)))
+	if offset+limit < len(matched) {
+		next := *c.Request.URL
+		nq := next.Query()
+		nq.Set("offset", strconv.Itoa(offset+limit))
+		nq.Set("limit", strconv.Itoa(limit))
+		next.RawQuery = nq.Encode()
+		c.Response.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+	}
+	if wantsGeoJSON(c) {
+		return writeTagged(c, geoJSONType, featureCollection(alertsGeoJSON(page)))
+	}
+	return writeTagged(c, "application/json", append([]Alert{}, page...))
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	alertArchiveEvery = time.Minute
+	alertSpillQueue   = 16384
+)
+
+// AlertRetentionConfig bounds how many alerts are kept live. Alerts past the
+// limits are archived: the postgres backend keeps them flagged in the table,
+// and when ArchiveFile is set they are also appended to it as JSON lines.
+type AlertRetentionConfig struct {
+	MaxAge      time.Duration `yaml:"max_age"`      // Zero disables the age limit
+	MaxCount    int           `yaml:"max_count"`    // Zero disables the count limit
+	ArchiveFile string        `yaml:"archive_file"` // JSON lines file receiving archived alerts
+}
+
+func defaultAlertRetentionConfig() AlertRetentionConfig {
+	return AlertRetentionConfig{MaxAge: 7 * 24 * time.Hour, MaxCount: 10000}
+}
+
+// archiveAlerts periodically moves alerts past the retention limits out of
+// the live alert list.
+func archiveAlerts(cfg AlertRetentionConfig) {
+	if cfg.MaxAge <= 0 && cfg.MaxCount <= 0 {
+		return
+	}
+	ticker := time.NewTicker(alertArchiveEvery)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		var before time.Time
+		if cfg.MaxAge > 0 {
+			before = now.Add(-cfg.MaxAge)
+		}
+		archived, err := store.ArchiveAlerts(before, cfg.MaxCount)
+		if err != nil {
+			log.Printf("Error archiving alerts: %v", err)
+			continue
+		}
+		if len(archived) == 0 {
+			continue
+		}
+		if cfg.ArchiveFile != "" {
+			if err := appendAlertArchive(cfg.ArchiveFile, archived); err != nil {
+				log.Printf("Error writing alert archive %s: %v", cfg.ArchiveFile, err)
+			}
+		}
+		log.Printf("Archived %d alerts", len(archived))
+	}
+}
+
+// appendAlertArchive appends the alerts to path, one JSON object per line.
+func appendAlertArchive(path string, alerts []Alert) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, alert := range alerts {
+		if err := enc.Encode(alert); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// alertSpill queues the alerts the memory backend pushed out to make room
+// for the archive file; it is disabled without one. spilledAlerts counts
+// the alerts pushed out, and droppedAlerts those that never reached the
+// archive.
+var (
+	alertSpill = struct {
+		sync.Mutex
+		enabled bool
+		queue   []Alert
+		wake    chan struct{}
+	}{wake: make(chan struct{}, 1)}
+	spilledAlerts  atomic.Uint64
+	droppedAlerts  atomic.Uint64
+	lastDropLogged atomic.Int64 // Unix nanoseconds
+)
+
+// alertDropLogEvery limits how often lost alerts are logged.
+const alertDropLogEvery = time.Minute
+
+// spillAlert queues an alert pushed out of the memory backend for the
+// archive writer. It runs under the store's lock and often under mu, so it
+// never touches the disk or waits: when the writer has fallen
+// alertSpillQueue alerts behind, the alert is dropped and counted.
+func spillAlert(alert Alert) {
+	if spilledAlerts.Add(1) == 1 {
+		log.Printf("Alert limit of the memory storage reached; the oldest alerts are leaving memory")
+	}
+	q := &alertSpill
+	q.Lock()
+	switch {
+	case !q.enabled:
+		q.Unlock()
+		dropAlerts(1, "no alert_retention.archive_file is set")
+		return
+	case len(q.queue) >= alertSpillQueue:
+		q.Unlock()
+		dropAlerts(1, "the alert archive writer has fallen behind")
+		return
+	}
+	q.queue = append(q.queue, alert)
+	q.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dropAlerts counts n alerts lost from memory and logs the total at most
+// once per alertDropLogEvery.
+func dropAlerts(n int, reason string) {
+	total := droppedAlerts.Add(uint64(n))
+	now := time.Now().UnixNano()
+	last := lastDropLogged.Load()
+	if now-last < int64(alertDropLogEvery) || !lastDropLogged.CompareAndSwap(last, now) {
+		return
+	}
+	log.Printf("Alerts dropped from memory storage so far: %d (%s)", total, reason)
+}
+
+// startAlertSpill appends the alerts spilled from memory to path, in
+// batches as they come.
+func startAlertSpill(path string) {
+	q := &alertSpill
+	q.Lock()
+	q.enabled = true
+	q.Unlock()
+	go func() {
+		for range q.wake {
+			q.Lock()
+			batch := q.queue
+			q.queue = nil
+			q.Unlock()
+			if len(batch) == 0 {
+				continue
+			}
+			if err := appendAlertArchive(path, batch); err != nil {
+				dropAlerts(len(batch), fmt.Sprintf("writing alert archive %s: %v", path, err))
+			}
+		}
+	}()
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Evaluation modes.
+const (
+	evaluateAll        = "all"         // Every matching criterion fires
+	evaluateFirstMatch = "first_match" // Only the highest-priority matching criterion fires
+)
+
+// evaluationMode is set from the configuration at startup.
+var evaluationMode = evaluateAll
+
+// evaluateCriteria runs the enabled criteria against an update and returns
+// the alerts they raise. Exclusion criteria are checked first; if any
+// matches, no criteria alerts are raised for the update and the positive
+// criteria are not evaluated. In first_match mode criteria are tried in
+// descending priority (ties keep their creation order) and evaluation stops
+// at the first alert, so later stateful detectors do not see that update.
+// Callers must hold mu.
+func evaluateCriteria(aircraft Aircraft) []Alert {
+	criteria, err := activeCriteria()
+	if err != nil {
+		log.Printf("Error loading alert criteria: %v", err)
+		return nil
+	}
+
+	positive := make([]AlertCriteria, 0, len(criteria))
+	for _, criterion := range criteria {
+		if !criterion.Enabled {
+			continue
+		}
+		if !criterion.Exclude {
+			positive = append(positive, criterion)
+			continue
+		}
+		if criterion.HasConditions() && criterionMatches(&criterion, aircraft) {
+			now := time.Now()
+			criterion.HitCount++
+			criterion.LastTriggered = &now
+			if err := store.SaveCriterion(criterion); err != nil {
+				log.Printf("Error saving alert criterion %s: %v", criterion.ID, err)
+			}
+			log.Printf("Alerts for %s (%s) suppressed by exclusion criterion %s", aircraft.Callsign, aircraft.ICAO, criterion.ID)
+			return nil
+		}
+	}
+	if evaluationMode == evaluateFirstMatch {
+		sort.SliceStable(positive, func(a, b int) bool {
+			return positive[a].Priority > positive[b].Priority
+		})
+	}
+
+	var alerts []Alert
+	for i := range positive {
+		criterion := &positive[i]
+		if event, message, cpa, ok := evaluateCriterion(criterion, aircraft); ok {
+			alerts = append(alerts, criterionAlert(criterion, aircraft, event, message, cpa))
+			if evaluationMode == evaluateFirstMatch {
+				break
+			}
+		}
+	}
+	return alerts
+}
+
+// Alert categories.
+const (
+	categoryCriteria = "criteria" // Raised by a user-defined criterion
+	categoryAnomaly  = "anomaly"  // Raised by the plausibility checker
+)
+
+// Alert severities.
+const (
+	severityInfo     = "info"
+	severityWarning  = "warning"
+	severityCritical = "critical"
+)
+
+// severityRank orders severities from least to most severe.
+var severityRank = map[string]int{
+	severityInfo:     0,
+	severityWarning:  1,
+	severityCritical: 2,
+}
+
+// triggerAlert raises an alert for criterion outside the evaluators, as the
+// signal loss sweep does, and records it. Callers must hold mu.
+func triggerAlert(criterion *AlertCriteria, aircraft Aircraft, event, message string) Alert {
+	alert := criterionAlert(criterion, aircraft, event, message, nil)
+	recordAlert(context.Background(), alert)
+	return alert
+}
+
+// criterionAlert returns an alert for criterion and updates and saves its
+// hit history. cpa is the closest approach the evaluator predicted, for
+// proximity; other criteria get theirs from pointCPA. Callers must hold mu.
+func criterionAlert(criterion *AlertCriteria, aircraft Aircraft, event, message string, cpa *CPA) Alert {
+	now := time.Now()
+	criterion.HitCount++
+	criterion.LastTriggered = &now
+	if err := store.SaveCriterion(*criterion); err != nil {
+		log.Printf("Error saving alert criterion %s: %v", criterion.ID, err)
+	}
+	severity := criterion.Severity
+	if severity == "" {
+		severity = severityInfo
+	}
+	if event != eventProximity {
+		cpa = pointCPA(criterion, aircraft)
+	}
+	route := lookupRoute(aircraft)
+	message = withRoute(message, aircraft.Callsign, route)
+	if cpa != nil {
+		message += ", " + describeCPA(cpa)
+	}
+
+	return Alert{
+		ID:           newID(),
+		Category:     categoryCriteria,
+		Severity:     severity,
+		Event:        event,
+		Aircraft:     aircraft,
+		Message:      renderAlertMessage(criterion, aircraft, event, message),
+		Criteria:     *criterion,
+		Timestamp:    now,
+		TFR:          zones[criterion.Zone].TFR,
+		CPA:          cpa,
+		Registration: lookupRegistration(aircraft.ICAO),
+		Route:        route,
+	}
+}
+
+// recordAlert stores the alert, broadcasts it to SSE clients and queues it
+// for the configured notifiers and escalation. Callers must hold mu.
+func recordAlert(ctx context.Context, alert Alert) {
+	ctx, span := tracer.Start(ctx, "alert", trace.WithAttributes(
+		attribute.String("alert.id", alert.ID), attribute.String("alert.event", alert.Event)))
+	defer span.End()
+	alert.Trace = traceParent(ctx)
+	startEscalation(&alert)
+	if err := store.AddAlert(alert); err != nil {
+		log.Printf("Error storing alert %s: %v", alert.ID, err)
+	}
+	log.Printf("ALERT: %+v", alert)
+	recordAlertMetrics(alert)
+	notifyAlert(alert)
+
+	alertJSON, err := json.Marshal(alert)
+	if err != nil {
+		log.Printf("Error marshalling alert for SSE: %v", err)
+	} else {
+		hub.broadcast <- []byte("event: alert\ndata: " + string(alertJSON) + "\n\n")
+	}
+}
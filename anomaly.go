@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AnomalyConfig tunes the plausibility checker that flags spoofed or
+// corrupt ADS-B data.
+type AnomalyConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	MaxSpeed        float64 `yaml:"max_speed"`         // Knots; faster implied movement is a position jump
+	MaxVerticalRate float64 `yaml:"max_vertical_rate"` // Feet per minute; faster altitude change is a teleport
+	SpeedTolerance  float64 `yaml:"speed_tolerance"`   // Allowed relative gap between reported and implied speed
+	Cooldown        int     `yaml:"cooldown"`          // Seconds before the same anomaly is reported again for an aircraft
+}
+
+// Anomaly event types.
+const (
+	anomalyPositionJump  = "position_jump"
+	anomalySpeedMismatch = "speed_mismatch"
+	anomalyAltitudeJump  = "altitude_jump"
+	anomalyDuplicateICAO = "duplicate_icao"
+)
+
+const (
+	minAnomalyJumpNM       = 2    // Ignore jumps shorter than this
+	minAnomalyAltitudeJump = 2000 // Feet
+	minSpeedCheckInterval  = 10 * time.Second
+	minSpeedMismatchKnots  = 100
+)
+
+var (
+	anomalyConfig = defaultAnomalyConfig()
+	// lastAnomaly records when each (ICAO, anomaly) pair was last reported.
+	// Guarded by mu.
+	lastAnomaly = map[string]time.Time{}
+)
+
+func defaultAnomalyConfig() AnomalyConfig {
+	return AnomalyConfig{
+		Enabled:         true,
+		MaxSpeed:        1200,
+		MaxVerticalRate: 15000,
+		SpeedTolerance:  0.5,
+		Cooldown:        300,
+	}
+}
+
+// checkAnomalies compares the update with the aircraft's track before it
+// and returns anomaly alerts for physically implausible changes. Callers
+// must hold mu.
+func checkAnomalies(aircraft Aircraft, points []Aircraft) []Alert {
+	if !anomalyConfig.Enabled || len(points) == 0 {
+		return nil
+	}
+	prev := points[len(points)-1]
+	dt := aircraft.Timestamp.Sub(prev.Timestamp)
+	if dt <= 0 {
+		return nil
+	}
+	var alerts []Alert
+	raise := func(event, message string) {
+		if alert, ok := anomalyAlert(aircraft, event, message); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	hours := dt.Hours()
+	dist := distanceNM(prev.Latitude, prev.Longitude, aircraft.Latitude, aircraft.Longitude)
+	implied := dist / hours
+
+	if dist > minAnomalyJumpNM && implied > anomalyConfig.MaxSpeed {
+		if consistentWithEarlier(points[:len(points)-1], aircraft) {
+			raise(anomalyDuplicateICAO, fmt.Sprintf(
+				"Duplicate ICAO %s: reports alternate between positions %.0f nm apart", aircraft.ICAO, dist))
+		} else {
+			raise(anomalyPositionJump, fmt.Sprintf(
+				"Position jump for %s (%s): %.1f nm in %s implies %.0f kt", aircraft.Callsign, aircraft.ICAO, dist, dt.Round(time.Second), implied))
+		}
+		return alerts
+	}
+
+	if dt >= minSpeedCheckInterval && aircraft.Speed > 0 {
+		reported := (aircraft.Speed + prev.Speed) / 2
+		gap := math.Abs(implied - reported)
+		if gap > minSpeedMismatchKnots && gap > anomalyConfig.SpeedTolerance*reported {
+			raise(anomalySpeedMismatch, fmt.Sprintf(
+				"Speed mismatch for %s (%s): reports %.0f kt but moved at %.0f kt", aircraft.Callsign, aircraft.ICAO, reported, implied))
+		}
+	}
+
+	dAlt := math.Abs(float64(aircraft.Altitude - prev.Altitude))
+	if dAlt > minAnomalyAltitudeJump && dAlt/dt.Minutes() > anomalyConfig.MaxVerticalRate {
+		raise(anomalyAltitudeJump, fmt.Sprintf(
+			"Altitude jump for %s (%s): %d ft to %d ft in %s", aircraft.Callsign, aircraft.ICAO, prev.Altitude, aircraft.Altitude, dt.Round(time.Second)))
+	}
+	return alerts
+}
+
+// consistentWithEarlier reports whether the update is plausible relative to
+// one of the few points preceding the last one, which indicates two
+// transmitters sharing an address rather than a single jump.
+func consistentWithEarlier(points []Aircraft, aircraft Aircraft) bool {
+	for i := len(points) - 1; i >= 0 && i >= len(points)-5; i-- {
+		p := points[i]
+		hours := aircraft.Timestamp.Sub(p.Timestamp).Hours()
+		if hours <= 0 {
+			continue
+		}
+		if distanceNM(p.Latitude, p.Longitude, aircraft.Latitude, aircraft.Longitude)/hours <= anomalyConfig.MaxSpeed {
+			return true
+		}
+	}
+	return false
+}
+
+// anomalyAlert returns an anomaly alert unless the same anomaly was reported
+// for the aircraft within the cooldown. Callers must hold mu.
+func anomalyAlert(aircraft Aircraft, event, message string) (Alert, bool) {
+	key := aircraft.ICAO + "|" + event
+	if last, ok := lastAnomaly[key]; ok && aircraft.Timestamp.Sub(last) < time.Duration(anomalyConfig.Cooldown)*time.Second {
+		return Alert{}, false
+	}
+	lastAnomaly[key] = aircraft.Timestamp
+	route := lookupRoute(aircraft)
+	return Alert{
+		ID:           newID(),
+		Category:     categoryAnomaly,
+		Severity:     severityWarning,
+		Event:        event,
+		Aircraft:     aircraft,
+		Message:      withRoute(message, aircraft.Callsign, route),
+		Timestamp:    time.Now(),
+		Registration: lookupRegistration(aircraft.ICAO),
+		Route:        route,
+	}, true
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// apiPrefix is the path prefix of the current API version. Routes are
+// registered under it; apiRouter also serves each one at its unversioned
+// /api path for feeders and scripts written before versioning.
+const apiPrefix = "/api/v1"
+
+// APIError is the body of every /api/v1 error response.
+type APIError struct {
+	Error     APIErrorDetail `json:"error"`
+	RequestID string         `json:"request_id"` // Also in the X-Request-ID header
+}
+
+type APIErrorDetail struct {
+	Code    string `json:"code"`    // Stable and machine-readable, e.g. not_found
+	Message string `json:"message"` // For people; may change between releases
+	// Fields lists the problems with individual fields of the request body.
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// errorCodes names the error statuses the API returns.
+var errorCodes = map[int]string{
+	http.StatusBadRequest:            "invalid_request",
+	http.StatusUnauthorized:          "unauthenticated",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusRequestTimeout:        "timeout",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "too_large",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusTooManyRequests:       "rate_limited",
+	http.StatusInternalServerError:   "internal",
+	http.StatusBadGateway:            "upstream_failed",
+	http.StatusServiceUnavailable:    "unavailable",
+}
+
+func errorCode(status int) string {
+	if code, ok := errorCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return "internal"
+	}
+	return "invalid_request"
+}
+
+// legacyPath returns the unversioned path of a versioned route.
+func legacyPath(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, apiPrefix+"/")
+	if !ok {
+		return "", false
+	}
+	return "/api/" + rest, true
+}
+
+// versioned wraps a /api/v1 handler so its JSON errors are sent as APIError
+// envelopes. Handlers keep replying with {"error": message}, adding
+// "fields" for problems with the body.
+func versioned(h func(*jacked.Context) error) func(*jacked.Context) error {
+	return func(c *jacked.Context) error {
+		w := &envelopeWriter{ResponseWriter: c.Response}
+		c.Response = w
+		err := h(c)
+		c.Response = w.ResponseWriter
+		w.finish(requestID(c.Request))
+		return err
+	}
+}
+
+// legacyRoutesUsed remembers which unversioned routes have been logged.
+var legacyRoutesUsed sync.Map
+
+// legacy wraps the handler of an unversioned route. Responses are unchanged
+// from before versioning, and point clients at the versioned path.
+func legacy(method, path string, h func(*jacked.Context) error) func(*jacked.Context) error {
+	return func(c *jacked.Context) error {
+		successor := apiPrefix + strings.TrimPrefix(c.Request.URL.Path, "/api")
+		c.Response.Header().Set("Deprecation", "true")
+		c.Response.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		if _, seen := legacyRoutesUsed.LoadOrStore(method+" "+path, true); !seen {
+			log.Printf("Deprecated route %s %s used by %s; clients should move to %s", method, path, c.Request.RemoteAddr, apiPrefix)
+		}
+		return h(c)
+	}
+}
+
+// envelopeWriter holds back JSON error responses so finish can rewrite them
+// as APIError envelopes. Everything else, streams included, passes straight
+// through.
+type envelopeWriter struct {
+	http.ResponseWriter
+	status int // Held-back error status; 0 while passing through
+	body   bytes.Buffer
+}
+
+func (w *envelopeWriter) WriteHeader(status int) {
+	if status >= http.StatusBadRequest && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *envelopeWriter) Write(b []byte) (int, error) {
+	if w.status != 0 {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush lets the SSE stream flush through the wrapper.
+func (w *envelopeWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the WebSocket upgrade take over the connection.
+func (w *envelopeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("connection cannot be hijacked")
+	}
+	return h.Hijack()
+}
+
+func (w *envelopeWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// finish sends a held-back error, as an envelope when it was a plain
+// {"error": message}.
+func (w *envelopeWriter) finish(requestID string) {
+	if w.status == 0 {
+		return
+	}
+	body := w.body.Bytes()
+	var plain struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}
+	if json.Unmarshal(body, &plain) == nil && plain.Error != "" {
+		envelope, err := json.Marshal(APIError{
+			Error:     APIErrorDetail{Code: errorCode(w.status), Message: plain.Error, Fields: plain.Fields},
+			RequestID: requestID,
+		})
+		if err == nil {
+			body = append(envelope, '\n')
+		}
+	}
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// auditMemory is how many recent entries GET /api/v1/audit can return.
+const auditMemory = 1000
+
+// AuditConfig keeps a record of manual interventions such as purging an
+// aircraft. Entries are always logged and kept in memory; File keeps them
+// across restarts.
+type AuditConfig struct {
+	File string `yaml:"file"` // JSON lines file every entry is appended to
+}
+
+// AuditEntry records who did what to which object.
+type AuditEntry struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor"`  // e.g. "user alice", "API key ops" or "IP 10.0.0.5"
+	Action    string    `json:"action"` // e.g. aircraft.delete
+	Target    string    `json:"target"`
+	Detail    string    `json:"detail,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+var auditLog = struct {
+	sync.Mutex
+	file    string
+	entries []AuditEntry // Oldest first, at most auditMemory
+}{}
+
+type principalKey struct{}
+
+// withPrincipal records who is making the request, for the audit log.
+func withPrincipal(r *http.Request, name string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, name))
+}
+
+// requestActor names who made a request: the API key or user when it
+// carried one, its address otherwise.
+func requestActor(r *http.Request) string {
+	if name, _ := r.Context().Value(principalKey{}).(string); name != "" {
+		return name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "IP " + host
+}
+
+// recordAudit logs an action taken through the API.
+func recordAudit(c *jacked.Context, action, target, detail string) {
+	entry := AuditEntry{
+		ID:        newID(),
+		Timestamp: time.Now().UTC(),
+		Actor:     requestActor(c.Request),
+		Action:    action,
+		Target:    target,
+		Detail:    detail,
+		RequestID: requestID(c.Request),
+	}
+	logRequestf(c.Request, "Audit: %s %s %s %s", entry.Actor, entry.Action, entry.Target, entry.Detail)
+
+	auditLog.Lock()
+	defer auditLog.Unlock()
+	auditLog.entries = append(auditLog.entries, entry)
+	if len(auditLog.entries) > auditMemory {
+		auditLog.entries = auditLog.entries[len(auditLog.entries)-auditMemory:]
+	}
+	if auditLog.file != "" {
+		if err := appendAuditEntry(auditLog.file, entry); err != nil {
+			log.Printf("Error writing audit log %s: %v", auditLog.file, err)
+		}
+	}
+}
+
+func appendAuditEntry(path string, entry AuditEntry) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(entry); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// handleListAudit returns the most recent audit entries, newest first, at
+// most ?limit= (default 100).
+func handleListAudit(c *jacked.Context) error {
+	limit := 100
+	if v := c.Request.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > auditMemory {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(auditMemory)})
+		}
+		limit = n
+	}
+	auditLog.Lock()
+	entries := make([]AuditEntry, 0, min(limit, len(auditLog.entries)))
+	for i := len(auditLog.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		entries = append(entries, auditLog.entries[i])
+	}
+	auditLog.Unlock()
+	return c.JSON(http.StatusOK, entries)
+}
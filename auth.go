@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Roles of API keys and users. Viewers read aircraft, alerts and criteria and
+// follow the live streams; operators also manage criteria, escalation and
+// alerts; admins also handle backups and notification deliveries. Feeders
+// only submit aircraft data, and only they may.
+const (
+	roleViewer   = "viewer"
+	roleOperator = "operator"
+	roleAdmin    = "admin"
+	roleFeeder   = "feeder"
+)
+
+// roleGrants lists the roles each role satisfies.
+var roleGrants = map[string][]string{
+	roleViewer:   {roleViewer},
+	roleOperator: {roleViewer, roleOperator},
+	roleAdmin:    {roleViewer, roleOperator, roleAdmin},
+	roleFeeder:   {roleFeeder},
+}
+
+// AuthConfig protects the API. Without API keys or users every /api route is
+// open to anyone who can reach the port.
+type AuthConfig struct {
+	APIKeys []APIKey `yaml:"api_keys"`
+	Users   []User   `yaml:"users"` // Accounts for the web UI login
+
+	// JWTSecret signs the session tokens issued by /api/v1/login. When empty a
+	// random secret is used, so every token stops working when the server
+	// restarts. With a secret, access tokens outlive a restart, but the live
+	// refresh tokens are kept in memory only: users log in again once their
+	// access token expires.
+	JWTSecret  string        `yaml:"jwt_secret"`
+	AccessTTL  time.Duration `yaml:"access_ttl"`  // Lifetime of access tokens
+	RefreshTTL time.Duration `yaml:"refresh_ttl"` // Lifetime of refresh tokens
+}
+
+func defaultAuthConfig() AuthConfig {
+	return AuthConfig{AccessTTL: 15 * time.Minute, RefreshTTL: 7 * 24 * time.Hour}
+}
+
+// APIKey is a client credential. Clients send it in an X-API-Key header, as
+// "Authorization: Bearer <key>", or as ?api_key= where headers cannot be set
+// (EventSource, WebSocket). Generate keys with -gen-api-key.
+type APIKey struct {
+	Name  string   `yaml:"name"` // Shown in logs
+	Key   string   `yaml:"key"`
+	Roles []string `yaml:"roles"` // viewer, operator, admin and/or feeder
+}
+
+// User is an account that logs in with a password. Generate the hash with
+// -hash-password.
+type User struct {
+	Username     string   `yaml:"username"`
+	PasswordHash string   `yaml:"password_hash"` // bcrypt
+	Roles        []string `yaml:"roles"`
+}
+
+func validRoles(roles []string) error {
+	if len(roles) == 0 {
+		return errors.New("at least one role is required")
+	}
+	for _, r := range roles {
+		if _, ok := roleGrants[r]; !ok {
+			return fmt.Errorf("unknown role %q", r)
+		}
+	}
+	return nil
+}
+
+func (c *AuthConfig) validate() error {
+	names := map[string]bool{}
+	for i, k := range c.APIKeys {
+		if k.Name == "" {
+			return fmt.Errorf("auth.api_keys[%d]: name is required", i)
+		}
+		if names[k.Name] {
+			return fmt.Errorf("auth.api_keys: duplicate name %q", k.Name)
+		}
+		names[k.Name] = true
+		if len(k.Key) < 16 {
+			return fmt.Errorf("auth.api_keys %s: key must be at least 16 characters", k.Name)
+		}
+		if err := validRoles(k.Roles); err != nil {
+			return fmt.Errorf("auth.api_keys %s: %w", k.Name, err)
+		}
+	}
+	usernames := map[string]bool{}
+	for i, u := range c.Users {
+		if u.Username == "" {
+			return fmt.Errorf("auth.users[%d]: username is required", i)
+		}
+		if usernames[u.Username] {
+			return fmt.Errorf("auth.users: duplicate username %q", u.Username)
+		}
+		usernames[u.Username] = true
+		if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+			return fmt.Errorf("auth.users %s: password_hash is not a bcrypt hash", u.Username)
+		}
+		if err := validRoles(u.Roles); err != nil {
+			return fmt.Errorf("auth.users %s: %w", u.Username, err)
+		}
+	}
+	if c.JWTSecret != "" && len(c.JWTSecret) < 32 {
+		return errors.New("auth.jwt_secret must be at least 32 characters")
+	}
+	if c.AccessTTL <= 0 || c.RefreshTTL <= 0 {
+		return errors.New("auth.access_ttl and auth.refresh_ttl must be positive")
+	}
+	return nil
+}
+
+// enabled reports whether the API requires credentials.
+func (c AuthConfig) enabled() bool {
+	return len(c.APIKeys) > 0 || len(c.Users) > 0
+}
+
+// hasRole reports whether any of roles satisfies the required role.
+func hasRole(roles []string, required string) bool {
+	for _, r := range roles {
+		if slices.Contains(roleGrants[r], required) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the API key matching the given secret.
+func (c AuthConfig) lookup(secret string) (APIKey, bool) {
+	for _, k := range c.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(k.Key), []byte(secret)) == 1 {
+			return k, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// newAPIKey returns a random key for -gen-api-key.
+func newAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// routeRoles overrides the role required by routes that do not follow the
+// default of viewer for GET and operator for everything else.
+var routeRoles = map[string]string{
+	"POST /api/v1/aircraft":                           roleFeeder,
+	"DELETE /api/v1/alerts":                           roleAdmin,
+	"POST /api/v1/graphql":                            roleViewer, // Queries only; changes go through REST
+	"POST /api/v1/alert-criteria/test":                roleViewer, // Dry runs change nothing
+	"GET /api/v1/push/subscriptions":                  roleAdmin,
+	"GET /api/v1/notifications/deliveries":            roleAdmin,
+	"POST /api/v1/notifications/deliveries/:id/retry": roleAdmin,
+	"DELETE /api/v1/notifications/deliveries/:id":     roleAdmin,
+	"GET /api/v1/audit":                               roleAdmin,
+	"GET /api/v1/streams":                             roleAdmin,
+	"GET /api/v1/backup":                              roleAdmin, // Holds the configuration and its secrets
+	"POST /api/v1/restore":                            roleAdmin,
+	"POST /api/v1/admin/reload":                       roleAdmin,
+	"GET /api/v1/debug/*path":                         roleAdmin,
+	"GET /api/v1/openapi.json":                        "",
+	"GET /api/v1/docs":                                "",
+	"GET /api/v1/push/key":                            "",
+	"POST /api/v1/login":                              "",
+	"POST /api/v1/token/refresh":                      "",
+	"POST /api/v1/logout":                             "",
+}
+
+// routeRole returns the role a route requires, or "" for public routes.
+// Only /api routes are protected; the web UI itself is public.
+func routeRole(method, path string) string {
+	if !strings.HasPrefix(path, "/api/") {
+		return ""
+	}
+	if role, ok := routeRoles[method+" "+path]; ok {
+		return role
+	}
+	if method == http.MethodGet {
+		return roleViewer
+	}
+	return roleOperator
+}
+
+// credentialFromRequest returns the API key or session token sent with the
+// request, if any.
+func credentialFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	if token := r.URL.Query().Get("access_token"); token != "" {
+		return token
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// authorize checks that an API key or session token holds the required role,
+// returning the HTTP status and message to reject the request with. name
+// identifies the key or user whenever the credential is valid.
+func (c AuthConfig) authorize(credential, required string) (name string, code int, msg string) {
+	if credential == "" {
+		return "", http.StatusUnauthorized, "API key or session token required"
+	}
+	var roles []string
+	if sessions != nil && isJWT(credential) {
+		claims, err := sessions.verify(credential, false)
+		if err != nil {
+			return "", http.StatusUnauthorized, "Invalid or expired session token"
+		}
+		name, roles = "user "+claims.Subject, claims.Roles
+	} else {
+		key, ok := c.lookup(credential)
+		if !ok {
+			return "", http.StatusUnauthorized, "Invalid API key"
+		}
+		name, roles = "API key "+key.Name, key.Roles
+	}
+	if !hasRole(roles, required) {
+		return name, http.StatusForbidden, fmt.Sprintf("%s lacks the %s role", name, required)
+	}
+	return name, 0, ""
+}
+
+// apiRouter registers routes on the app, wrapping /api handlers with the API
+// key or session check for their role and the rate limits. Routes under
+// /api/v1 are also served at their unversioned /api path. With an admin app
+// the control plane routes are served there instead, and public routes such
+// as login on both.
+type apiRouter struct {
+	app    *jacked.App
+	admin  *jacked.App // nil unless admin_listen is set
+	auth   AuthConfig
+	limits *rateLimits
+}
+
+// controlPlanePrefixes are the routes, besides those requiring the admin
+// role, that admin_listen takes off the main listener: criteria management,
+// server statistics and notifier health.
+var controlPlanePrefixes = []string{
+	"/api/v1/alert-criteria",
+	"/api/v1/escalation-policies",
+	"/api/v1/presets",
+	"/api/v1/stats",
+	"/api/v1/notifiers",
+}
+
+// controlPlane reports whether a route belongs on the admin listener.
+func controlPlane(method, path string) bool {
+	if routeRole(method, path) == roleAdmin {
+		return true
+	}
+	for _, prefix := range controlPlanePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// apps returns the apps serving a route.
+func (r apiRouter) apps(method, path string) []*jacked.App {
+	switch {
+	case r.admin == nil:
+		return []*jacked.App{r.app}
+	case controlPlane(method, path):
+		return []*jacked.App{r.admin}
+	case strings.HasPrefix(path, "/api/") && routeRole(method, path) == "":
+		return []*jacked.App{r.app, r.admin}
+	}
+	return []*jacked.App{r.app}
+}
+
+func (r apiRouter) GET(path string, h func(*jacked.Context) error) {
+	h = r.protect(http.MethodGet, path, h)
+	for _, app := range r.apps(http.MethodGet, path) {
+		app.GET(path, versioned(h))
+		if old, ok := legacyPath(path); ok {
+			app.GET(old, legacy(http.MethodGet, old, h))
+		}
+	}
+}
+
+func (r apiRouter) POST(path string, h func(*jacked.Context) error) {
+	h = r.protect(http.MethodPost, path, h)
+	for _, app := range r.apps(http.MethodPost, path) {
+		app.POST(path, versioned(h))
+		if old, ok := legacyPath(path); ok {
+			app.POST(old, legacy(http.MethodPost, old, h))
+		}
+	}
+}
+
+func (r apiRouter) PUT(path string, h func(*jacked.Context) error) {
+	h = r.protect(http.MethodPut, path, h)
+	for _, app := range r.apps(http.MethodPut, path) {
+		app.PUT(path, versioned(h))
+		if old, ok := legacyPath(path); ok {
+			app.PUT(old, legacy(http.MethodPut, old, h))
+		}
+	}
+}
+
+func (r apiRouter) PATCH(path string, h func(*jacked.Context) error) {
+	h = r.protect(http.MethodPatch, path, h)
+	for _, app := range r.apps(http.MethodPatch, path) {
+		app.PATCH(path, versioned(h))
+		if old, ok := legacyPath(path); ok {
+			app.PATCH(old, legacy(http.MethodPatch, old, h))
+		}
+	}
+}
+
+func (r apiRouter) DELETE(path string, h func(*jacked.Context) error) {
+	h = r.protect(http.MethodDelete, path, h)
+	for _, app := range r.apps(http.MethodDelete, path) {
+		app.DELETE(path, versioned(h))
+		if old, ok := legacyPath(path); ok {
+			app.DELETE(old, legacy(http.MethodDelete, old, h))
+		}
+	}
+}
+
+func (r apiRouter) protect(method, path string, h func(*jacked.Context) error) func(*jacked.Context) error {
+	if !strings.HasPrefix(path, "/api/") {
+		return h
+	}
+	role := routeRole(method, path)
+	checkAuth := role != "" && r.auth.enabled()
+	limiter := r.limits.forRoute(role)
+	if !checkAuth && limiter == nil {
+		return h
+	}
+	return func(c *jacked.Context) error {
+		var name, msg string
+		var code int
+		if checkAuth {
+			name, code, msg = r.auth.authorize(credentialFromRequest(c.Request), role)
+		}
+		if name == "" {
+			name = "IP " + r.limits.clientIP(c.Request)
+		}
+		if ok, wait := limiter.allow(name); !ok {
+			return rejectRateLimited(c, wait)
+		}
+		if code != 0 {
+			if code == http.StatusUnauthorized {
+				c.Response.Header().Set("WWW-Authenticate", `Bearer realm="aircraft-alert"`)
+			}
+			return c.JSON(code, map[string]string{"error": msg})
+		}
+		c.Request = withPrincipal(c.Request, name)
+		return h(c)
+	}
+}
+
+// grpcRoles maps gRPC methods to the role they require, like routeRoles.
+var grpcRoles = map[string]string{
+	"SubmitAircraft":       roleFeeder,
+	"ListAircraft":         roleViewer,
+	"ListAlerts":           roleViewer,
+	"ListAlertCriteria":    roleViewer,
+	"CreateAlertCriterion": roleOperator,
+	"DeleteAlertCriterion": roleOperator,
+	"StreamEvents":         roleViewer,
+}
+
+// grpcAuth checks the API key or session token in the x-api-key or
+// authorization metadata of a gRPC call, and the rate limits.
+func (c AuthConfig) grpcAuth(ctx context.Context, fullMethod string, limits *rateLimits) error {
+	role, ok := grpcRoles[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]
+	if !ok {
+		role = roleAdmin
+	}
+	var name, msg string
+	var code int
+	if c.enabled() {
+		var credential string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get("x-api-key"); len(v) > 0 {
+				credential = v[0]
+			} else if v := md.Get("authorization"); len(v) > 0 {
+				credential = strings.TrimPrefix(v[0], "Bearer ")
+			}
+		}
+		name, code, msg = c.authorize(credential, role)
+	}
+	if name == "" {
+		name = "IP " + peerIP(ctx)
+	}
+	if ok, wait := limits.forRoute(role).allow(name); !ok {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %s", wait.Round(time.Second))
+	}
+	switch code {
+	case 0:
+		return nil
+	case http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, msg)
+	default:
+		return status.Error(codes.Unauthenticated, msg)
+	}
+}
+
+// grpcInterceptors applies grpcAuth to every call.
+func (c AuthConfig) grpcInterceptors(limits *rateLimits) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := c.grpcAuth(ctx, info.FullMethod, limits); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := c.grpcAuth(ss.Context(), info.FullMethod, limits); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// What the hub does when a stream client's buffer is full.
+const (
+	whenFullDisconnect  = "disconnect"   // Drop the client; it reconnects and resumes
+	whenFullDropUpdates = "drop_updates" // Discard its oldest queued position updates
+)
+
+func (c StreamConfig) validate() error {
+	if c.Backlog < 0 {
+		return fmt.Errorf("streams.backlog must not be negative")
+	}
+	if c.ClientBuffer < 1 {
+		return fmt.Errorf("streams.client_buffer must be at least 1")
+	}
+	if c.WhenFull != whenFullDisconnect && c.WhenFull != whenFullDropUpdates {
+		return fmt.Errorf("unknown streams.when_full %q", c.WhenFull)
+	}
+	if c.Heartbeat <= 0 || c.WriteTimeout <= 0 {
+		return fmt.Errorf("streams.heartbeat and streams.write_timeout must be positive")
+	}
+	if c.BatchWindow < 0 {
+		return fmt.Errorf("streams.batch_window must not be negative")
+	}
+	if c.SnapshotInterval <= 0 {
+		return fmt.Errorf("streams.snapshot_interval must be positive")
+	}
+	return nil
+}
+
+// newClient returns a stream client with the configured buffer.
+func (h *Hub) newClient(id string) *Client {
+	return &Client{ID: id, Send: make(chan []byte, h.clientBuffer), Connected: time.Now()}
+}
+
+var (
+	positionUpdate = []byte("\nevent: aircraftUpdate\n")
+	positionBatch  = []byte("\nevent: aircraftBatch\n")
+)
+
+func isPositionUpdate(message []byte) bool {
+	return bytes.Contains(message, positionUpdate) || bytes.Contains(message, positionBatch)
+}
+
+// deliver queues the message for the client, making room under the
+// drop_updates policy. It reports false when the client has to go. Only
+// called from run, which is the only sender on client.Send.
+func (h *Hub) deliver(client *Client, message []byte) bool {
+	select {
+	case client.Send <- message:
+		return true
+	default:
+	}
+	if h.whenFull != whenFullDropUpdates {
+		return false
+	}
+
+	// Take the queue out, drop the oldest position update and put the rest
+	// back in order. The client may read from the queue meanwhile; it only
+	// ever gets events from the front, so the order holds.
+	var queued [][]byte
+drain:
+	for {
+		select {
+		case m := <-client.Send:
+			queued = append(queued, m)
+		default:
+			break drain
+		}
+	}
+	oldest := slices.IndexFunc(queued, isPositionUpdate)
+	if oldest >= 0 {
+		queued = slices.Delete(queued, oldest, oldest+1)
+	}
+	for _, m := range queued {
+		client.Send <- m
+	}
+	select {
+	case client.Send <- message:
+		if oldest < 0 {
+			return true // The client caught up meanwhile
+		}
+	default:
+		if !isPositionUpdate(message) {
+			return false // Only alerts and removals are queued; those are never dropped
+		}
+		// Nothing older to drop, so the new update goes instead.
+	}
+	if client.Dropped.Add(1) == 1 {
+		log.Printf("Client %s is falling behind; dropping its oldest position updates", client.ID)
+	}
+	return true
+}
+
+// StreamClientStats describes one connected stream client.
+type StreamClientStats struct {
+	ID        string    `json:"id"`
+	Connected time.Time `json:"connected"`
+	Queued    int       `json:"queued"`  // Events waiting to be sent: the client's lag
+	Buffer    int       `json:"buffer"`  // Events that may wait before the policy applies
+	Dropped   uint64    `json:"dropped"` // Position updates discarded under drop_updates
+}
+
+// handleListStreams returns the connected SSE, WebSocket, long-poll, gRPC
+// and GraphQL stream clients, most lagged first.
+func handleListStreams(c *jacked.Context) error {
+	reply := make(chan []StreamClientStats, 1)
+	hub.inspect <- reply
+	list := <-reply
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Queued != list[j].Queued {
+			return list[i].Queued > list[j].Queued
+		}
+		return list[i].Connected.Before(list[j].Connected)
+	})
+	return c.JSON(http.StatusOK, list)
+}
+
+// clientStats lists the clients. Only called from run.
+func (h *Hub) clientStats() []StreamClientStats {
+	list := make([]StreamClientStats, 0, len(h.clients))
+	for client := range h.clients {
+		list = append(list, StreamClientStats{
+			ID:        client.ID,
+			Connected: client.Connected,
+			Queued:    len(client.Send),
+			Buffer:    cap(client.Send),
+			Dropped:   client.Dropped.Load(),
+		})
+	}
+	return list
+}
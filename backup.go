@@ -0,0 +1,242 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	maxRestoreSize = 64 << 20
+	// maxRestoreExpanded bounds the members of a restored archive together
+	// once decompressed, so a small upload cannot expand without limit.
+	maxRestoreExpanded = 512 << 20
+)
+
+// activeConfig is the running configuration, included in backups. Reloads
+// update the parts they apply under mu.
+var activeConfig Config
+
+// handleBackup streams a .tar.gz holding criteria.json, alerts.json,
+// zones.json and the running configuration as config.yaml.
+func handleBackup(c *jacked.Context) error {
+	mu.RLock()
+	criteria, err := store.ListCriteria()
+	if err != nil {
+		mu.RUnlock()
+		return storeError(c, err)
+	}
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		mu.RUnlock()
+		return storeError(c, err)
+	}
+	zoneList := make([]Zone, 0, len(zones))
+	for _, z := range zones {
+		zoneList = append(zoneList, z)
+	}
+	config := activeConfig
+	mu.RUnlock()
+	sort.Slice(zoneList, func(i, j int) bool { return zoneList[i].Name < zoneList[j].Name })
+
+	files := make(map[string][]byte, 4)
+	for name, v := range map[string]interface{}{
+		"criteria.json": criteria,
+		"alerts.json":   alerts,
+		"zones.json":    zoneList,
+	} {
+		if files[name], err = json.MarshalIndent(v, "", "  "); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error encoding backup"})
+		}
+	}
+	if files["config.yaml"], err = yaml.Marshal(config); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error encoding backup"})
+	}
+
+	now := time.Now()
+	c.Response.Header().Set("Content-Type", "application/gzip")
+	c.Response.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="aircraft-alert-backup-%s.tar.gz"`, now.UTC().Format("20060102T150405Z")))
+	gz := gzip.NewWriter(c.Response)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"config.yaml", "criteria.json", "alerts.json", "zones.json"} {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			logRequestf(c.Request, "Error writing backup: %v", err)
+			return nil
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			logRequestf(c.Request, "Error writing backup: %v", err)
+			return nil
+		}
+	}
+	if err := tw.Close(); err != nil {
+		logRequestf(c.Request, "Error writing backup: %v", err)
+		return nil
+	}
+	if err := gz.Close(); err != nil {
+		logRequestf(c.Request, "Error writing backup: %v", err)
+	}
+	return nil
+}
+
+// backupContents holds the parts of a backup archive that can be restored.
+type backupContents struct {
+	criteria []AlertCriteria
+	alerts   []Alert
+	zones    []Zone
+	config   bool // Whether the archive carries config.yaml
+}
+
+// readBackup decodes an archive produced by handleBackup. Missing members
+// are left nil; config.yaml is only noted. Archives whose members add up to
+// more than maxRestoreExpanded are refused.
+func readBackup(r io.Reader) (backupContents, error) {
+	var b backupContents
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return b, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	budget := int64(maxRestoreExpanded)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return b, nil
+		} else if err != nil {
+			return b, err
+		}
+		if hdr.Size > budget {
+			return b, fmt.Errorf("%s: archive too large", hdr.Name)
+		}
+		budget -= hdr.Size
+		var v interface{}
+		switch hdr.Name {
+		case "criteria.json":
+			v = &b.criteria
+		case "alerts.json":
+			v = &b.alerts
+		case "zones.json":
+			v = &b.zones
+		case "config.yaml":
+			b.config = true
+			continue
+		default:
+			continue
+		}
+		if err := json.NewDecoder(io.LimitReader(tr, hdr.Size)).Decode(v); err != nil {
+			return b, fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// handleRestore loads a backup archive from the request body. Zones and
+// criteria in the archive replace the current ones; alerts not already
+// stored are added. Detector state starts afresh, as it refers to the
+// replaced zones and criteria.
+//
+// The configuration in the archive is not applied to the running server:
+// most of it takes effect only on startup. An archive carrying config.yaml
+// is refused unless ?skip_config=true acknowledges that, so the config is
+// never dropped unnoticed; copy it over the configuration file by hand.
+func handleRestore(c *jacked.Context) error {
+	defer c.Request.Body.Close()
+	backup, err := readBackup(http.MaxBytesReader(c.Response, c.Request.Body, maxRestoreSize))
+	if err != nil {
+		logRequestf(c.Request, "Error reading backup: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid backup archive"})
+	}
+	if backup.config && c.Request.URL.Query().Get("skip_config") != "true" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "The backup holds config.yaml, which restore does not apply; install it by hand and restore with ?skip_config=true"})
+	}
+
+	restoredZones := make(map[string]Zone, len(backup.zones))
+	for _, z := range backup.zones {
+		if err := z.validate(); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		restoredZones[z.Name] = z
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	previousZones := zones
+	if backup.zones != nil {
+		zones = restoredZones
+		restoreTFRZones()
+	}
+	// Restored zones must suit the criteria staying in place too.
+	criteria := backup.criteria
+	if criteria == nil && backup.zones != nil {
+		if criteria, err = store.ListCriteria(); err != nil {
+			zones = previousZones
+			return storeError(c, err)
+		}
+	}
+	for _, criterion := range criteria {
+		if err := validateCriterion(&criterion); err != nil {
+			zones = previousZones
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("criterion %s: %v", criterion.ID, err)})
+		}
+	}
+	indexZones()
+
+	if backup.criteria != nil {
+		if err := replaceCriteria(backup.criteria); err != nil {
+			zones = previousZones
+			indexZones()
+			return storeError(c, err)
+		}
+	}
+	if backup.zones != nil || backup.criteria != nil {
+		for icao := range tracks {
+			forgetAircraftState(icao)
+		}
+	}
+
+	added := 0
+	for _, alert := range backup.alerts {
+		if _, err := store.GetAlert(alert.ID); err == nil {
+			continue
+		} else if !errors.Is(err, errNotFound) {
+			return storeError(c, err)
+		}
+		if err := store.AddAlert(alert); err != nil {
+			return storeError(c, err)
+		}
+		added++
+	}
+
+	logRequestf(c.Request, "Restored backup: %d zones, %d criteria, %d new alerts", len(backup.zones), len(backup.criteria), added)
+	return c.JSON(http.StatusOK, map[string]int{
+		"zones":    len(backup.zones),
+		"criteria": len(backup.criteria),
+		"alerts":   added,
+	})
+}
+
+// replaceCriteria replaces all stored criteria with the given ones in one
+// store operation, keeping their IDs so restored alerts still refer to
+// them. On error the stored criteria are left as they were.
+// Callers must hold mu.
+func replaceCriteria(criteria []AlertCriteria) error {
+	replaced := make([]AlertCriteria, len(criteria))
+	for i, criterion := range criteria {
+		if criterion.ID == "" {
+			criterion.ID = newID()
+		}
+		replaced[i] = criterion
+	}
+	return store.ReplaceCriteria(replaced)
+}
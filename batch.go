@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"slices"
+	"strconv"
+)
+
+// Position updates for SSE clients streaming with ?batch=true are held by
+// the hub for streams.batch_window and sent as one aircraftBatch event,
+// whose data is a JSON array with the latest update of each aircraft. Any
+// other event sends the held updates first, so the order of events holds.
+
+// batched reports whether the hub holds the event for the client's next
+// batch rather than sending it now.
+func (h *Hub) batched(client *Client, event *streamEvent) bool {
+	return client.Batch && h.batchWindow > 0 && event.name == "aircraftUpdate"
+}
+
+// flushBatch sends the held position updates to the batch clients, each
+// narrowed down by the client's filter. Only called from run.
+func (h *Hub) flushBatch() {
+	if len(h.pending) == 0 {
+		return
+	}
+	pending := h.pending
+	h.pending = nil
+	var shared []byte // For the clients without a filter
+	for client := range h.clients {
+		if !client.Batch {
+			continue
+		}
+		var frame []byte
+		if f := client.Filter.Load(); f != nil {
+			frame = batchFrame(h.pendingID, pending, f)
+		} else {
+			if shared == nil {
+				shared = batchFrame(h.pendingID, pending, nil)
+			}
+			frame = shared
+		}
+		if frame == nil {
+			continue
+		}
+		if !h.deliver(client, frame) {
+			log.Printf("Client %s send buffer full or disconnected. Unregistering.", client.ID)
+			delete(h.clients, client)
+			close(client.Send)
+		}
+	}
+	h.clientCount.Store(int32(len(h.clients)))
+}
+
+// batchFrame returns the aircraftBatch event for the updates passing f, or
+// nil if none do. An aircraft updated more than once is in it once, at the
+// position of its latest update.
+func batchFrame(id uint64, updates []*streamEvent, f *streamFilter) []byte {
+	seen := make(map[string]bool)
+	var latest [][]byte
+	for _, e := range slices.Backward(updates) {
+		if !e.decode() || seen[e.aircraft.ICAO] {
+			continue
+		}
+		seen[e.aircraft.ICAO] = true
+		if f == nil || f.matchesEvent(e) {
+			latest = append(latest, e.data)
+		}
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+	slices.Reverse(latest)
+	var b bytes.Buffer
+	b.WriteString("id: " + strconv.FormatUint(id, 10) + "\nevent: aircraftBatch\ndata: [")
+	b.Write(bytes.Join(latest, []byte(",")))
+	b.WriteString("]\n\n")
+	return b.Bytes()
+}
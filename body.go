@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// Limits on the bodies of the ingest and criteria endpoints, so a broken or
+// hostile client can neither hold a connection trickling a body in nor
+// make the server buffer a huge one.
+const (
+	maxAircraftBody  = 64 << 10
+	maxCriterionBody = 1 << 20
+	bodyReadTimeout  = 10 * time.Second
+)
+
+// readBody reads the request body, failing once it exceeds limit bytes or
+// takes longer than bodyReadTimeout to arrive.
+func readBody(c *jacked.Context, limit int64) ([]byte, error) {
+	rc := http.NewResponseController(c.Response)
+	deadline := rc.SetReadDeadline(time.Now().Add(bodyReadTimeout)) == nil
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response, c.Request.Body, limit))
+	c.Request.Body.Close()
+	if deadline && err == nil {
+		// Lifted so the server's background read of the idle connection
+		// cannot time out while the handler is still working. After a
+		// failed read it stays, as the rest of the body is not wanted.
+		rc.SetReadDeadline(time.Time{})
+	}
+	return body, err
+}
+
+// bodyError replies to a request whose body could not be read or decoded:
+// 413 when it was too large, 408 when it arrived too slowly and otherwise
+// 400 with message.
+func bodyError(c *jacked.Context, err error, message string) error {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		logRequestf(c.Request, "Request body over %d bytes", tooLarge.Limit)
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "Request body too large"})
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		logRequestf(c.Request, "Request body not received within %v", bodyReadTimeout)
+		c.Response.Header().Set("Connection", "close")
+		return c.JSON(http.StatusRequestTimeout, map[string]string{"error": "Request body not received in time"})
+	}
+	logRequestf(c.Request, "%s: %v", message, err)
+	return c.JSON(http.StatusBadRequest, map[string]string{"error": message})
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ClusterConfig lets several replicas run behind a load balancer. Each
+// replica evaluates the updates it receives, and shares the resulting
+// stream events (updates, removals and alerts) with the others over Redis
+// pub/sub, so stream clients see the same events whichever replica they are
+// connected to.
+type ClusterConfig struct {
+	Redis   string `yaml:"redis"`   // redis://[:password@]host:6379/0; empty runs a single instance
+	Channel string `yaml:"channel"` // Pub/sub channel the replicas share
+}
+
+func defaultClusterConfig() ClusterConfig {
+	return ClusterConfig{Channel: "aircraft-alert:events"}
+}
+
+func (c ClusterConfig) validate() error {
+	if c.Redis == "" {
+		return nil
+	}
+	if _, err := redis.ParseURL(c.Redis); err != nil {
+		return fmt.Errorf("cluster.redis: %w", err)
+	}
+	if c.Channel == "" {
+		return errors.New("cluster.channel must not be empty")
+	}
+	return nil
+}
+
+// Broker relays hub events between replicas. Publish sends an event to the
+// other replicas; Subscribe hands deliver the events the others publish
+// until ctx ends or the subscription fails.
+type Broker interface {
+	Publish(ctx context.Context, message []byte) error
+	Subscribe(ctx context.Context, deliver func(message []byte)) error
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// redisBroker is a Broker on a Redis pub/sub channel. Messages are the
+// publishing replica's ID, a space and the event, so replicas can skip
+// their own.
+type redisBroker struct {
+	client  *redis.Client
+	channel string
+	origin  string
+}
+
+func newRedisBroker(cfg ClusterConfig) (*redisBroker, error) {
+	opts, err := redis.ParseURL(cfg.Redis)
+	if err != nil {
+		return nil, err
+	}
+	return &redisBroker{client: redis.NewClient(opts), channel: cfg.Channel, origin: newID()}, nil
+}
+
+func (b *redisBroker) Publish(ctx context.Context, message []byte) error {
+	return b.client.Publish(ctx, b.channel, b.origin+" "+string(message)).Err()
+}
+
+func (b *redisBroker) Subscribe(ctx context.Context, deliver func(message []byte)) error {
+	sub := b.client.Subscribe(ctx, b.channel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+	messages := sub.Channel() // Reconnects by itself
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return errors.New("subscription closed")
+			}
+			origin, event, found := strings.Cut(msg.Payload, " ")
+			if found && origin != b.origin {
+				deliver([]byte(event))
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (b *redisBroker) Ping(ctx context.Context) error { return b.client.Ping(ctx).Err() }
+
+func (b *redisBroker) Close() error { return b.client.Close() }
+
+// brokerRetry is how long the hub waits to subscribe again after the broker
+// failed.
+const brokerRetry = 5 * time.Second
+
+// connect shares the hub's events with the other replicas through b. Call
+// it before run.
+func (h *Hub) connect(b Broker) {
+	h.broker = b
+	h.outbox = make(chan []byte, broadcastQueue)
+	h.relayed = make(chan []byte, broadcastQueue)
+	ctx, cancel := context.WithCancel(context.Background())
+	h.disconnect = cancel
+
+	go func() {
+		for message := range h.outbox {
+			pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			err := b.Publish(pubCtx, message)
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Cluster: error publishing event: %v", err)
+			}
+		}
+	}()
+	go func() {
+		for ctx.Err() == nil {
+			err := b.Subscribe(ctx, func(message []byte) {
+				select {
+				case h.relayed <- message:
+				case <-ctx.Done():
+				}
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Cluster: subscription failed, retrying in %v: %v", brokerRetry, err)
+			select {
+			case <-time.After(brokerRetry):
+			case <-ctx.Done():
+			}
+		}
+	}()
+}
+
+// publish hands a local event to the other replicas without holding up the
+// hub; events are dropped while the broker cannot keep up. Only called from
+// run.
+func (h *Hub) publish(message []byte) {
+	if h.broker == nil {
+		return
+	}
+	select {
+	case h.outbox <- message:
+	default:
+		if h.unpublished.Add(1) == 1 {
+			log.Printf("Cluster: broker falling behind; dropping events for the other replicas")
+		}
+	}
+}
+
+// nextID returns the ID of the next event. In a cluster IDs follow the
+// clock in microseconds, so a client resuming with Last-Event-ID on another
+// replica is sent that replica's events since about the same time.
+func (h *Hub) nextID() uint64 {
+	h.lastID++
+	if h.broker != nil {
+		h.lastID = max(h.lastID, uint64(time.Now().UnixMicro()))
+	}
+	return h.lastID
+}
+
+// stopCluster stops sharing events and closes the broker.
+func stopCluster() {
+	if hub.broker == nil {
+		return
+	}
+	hub.disconnect()
+	if err := hub.broker.Close(); err != nil {
+		log.Printf("Cluster: %v", err)
+	}
+}
+
+// checkCluster pings the broker.
+func checkCluster(ctx context.Context) HealthCheck {
+	if err := hub.broker.Ping(ctx); err != nil {
+		return HealthCheck{Status: healthFail, Error: err.Error()}
+	}
+	return HealthCheck{Status: healthOK}
+}
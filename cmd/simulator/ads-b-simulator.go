@@ -8,42 +8,31 @@ import (
 	"math/rand"
 	"net/http"
 	"time"
-)
 
-// Aircraft struct definition for the simulator.
-// This is a standalone program, so it defines its own struct.
-// Ideally, this would be shared from a common models package in a larger project.
-type Aircraft struct {
-	ICAO      string    `json:"icao"`
-	Callsign  string    `json:"callsign"`
-	Latitude  float64   `json:"lat"`
-	Longitude float64   `json:"lon"`
-	Altitude  int       `json:"alt_baro"`
-	Speed     float64   `json:"gs"`    // Ground speed in knots
-	Track     float64   `json:"track"` // Track angle in degrees
-	Timestamp time.Time `json:"timestamp"`
-}
+	"aircraft-alert/pkg/models"
+)
 
-const serverURL = "http://localhost:8090/api/aircraft"
+const serverURL = "http://localhost:8090/api/v1/aircraft"
 const tickIntervalSeconds = 5
 const earthRadiusKm = 6371.0
 
 // liveAircraft will store the current state of all simulated aircraft
-var liveAircraft []Aircraft
+var liveAircraft []models.Aircraft
 
 func initializeAircraft() {
-	liveAircraft = []Aircraft{
-		{ICAO: "AABBCC", Callsign: "TARGET1", Latitude: 34.0522, Longitude: -118.2437, Altitude: 35000, Speed: 450, Track: 45},
-		{ICAO: "DDEEFF", Callsign: "NORMALFLT", Latitude: 40.7128, Longitude: -74.0060, Altitude: 30000, Speed: 500, Track: 120},
-		{ICAO: "112233", Callsign: "LOWFLYER", Latitude: 34.0000, Longitude: -118.0000, Altitude: 5000, Speed: 180, Track: 270},
-		{ICAO: "TARGET2", Callsign: "SPECIALVIP", Latitude: 48.8566, Longitude: 2.3522, Altitude: 39000, Speed: 480, Track: 310},
-		{ICAO: "FFFF01", Callsign: "CIRCLER", Latitude: 30.0, Longitude: -90.0, Altitude: 10000, Speed: 250, Track: 0},
-		{ICAO: "FFFF02", Callsign: "EASTBOUND", Latitude: 39.8617, Longitude: -104.6731, Altitude: 28000, Speed: 400, Track: 90}, // Denver Intl
+	liveAircraft = []models.Aircraft{
+		{ICAO: "AABBCC", Callsign: "TARGET1", Latitude: 34.0522, Longitude: -118.2437, Altitude: 35000, Speed: 450, Track: 45, Squawk: "4521"},
+		{ICAO: "DDEEFF", Callsign: "NORMAL1", Latitude: 40.7128, Longitude: -74.0060, Altitude: 30000, Speed: 500, Track: 120, Squawk: "2231"},
+		{ICAO: "112233", Callsign: "LOWFLYER", Latitude: 34.0000, Longitude: -118.0000, Altitude: 5000, Speed: 180, Track: 270, Squawk: "1200"},
+		{ICAO: "39AC42", Callsign: "VIP7700", Latitude: 48.8566, Longitude: 2.3522, Altitude: 39000, Speed: 480, Track: 310, Squawk: "7700"},
+		{ICAO: "FFFF01", Callsign: "CIRCLER", Latitude: 30.0, Longitude: -90.0, Altitude: 10000, Speed: 250, Track: 0, Squawk: "1200"},
+		{ICAO: "FFFF02", Callsign: "EASTBOUND", Latitude: 39.8617, Longitude: -104.6731, Altitude: 28000, Speed: 400, Track: 90, Squawk: "3345"}, // Denver Intl
+		{ICAO: "AE1234", Callsign: "RCH401", Latitude: 39.90, Longitude: -104.60, Altitude: 28500, Speed: 420, Track: 270, Squawk: "3346"},       // Military block, close to EASTBOUND
 	}
 }
 
 // updateAircraftPosition calculates new lat/lon based on speed, track, and time interval
-func updateAircraftPosition(ac *Aircraft, dt float64) {
+func updateAircraftPosition(ac *models.Aircraft, dt float64) {
 	// Convert speed from knots to km/s
 	// 1 knot = 0.514444 m/s = 0.000514444 km/s
 	speedKmPerSec := ac.Speed * 0.000514444
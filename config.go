@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the server configuration loaded from a YAML file.
+type Config struct {
+	Listen       string `yaml:"listen"`        // Address the HTTP server binds to
+	GRPCListen   string `yaml:"grpc_listen"`   // Address of the gRPC API; empty disables it
+	AdminListen  string `yaml:"admin_listen"`  // Address serving the control plane routes instead of listen
+	StaticDir    string `yaml:"static_dir"`    // Directory holding the web UI
+	SwaggerUI    bool   `yaml:"swagger_ui"`    // Serve API docs at /api/v1/docs
+	CriteriaFile string `yaml:"criteria_file"` // JSON list of alert criteria loaded at startup
+	ZonesFile    string `yaml:"zones_file"`    // JSON list of named zones usable in criteria
+	AirportsFile string `yaml:"airports_file"` // OurAirports-style CSV used for takeoff/landing detection
+
+	// EvaluationMode is "all" (every matching criterion alerts) or
+	// "first_match" (only the highest-priority matching criterion alerts).
+	EvaluationMode string `yaml:"evaluation_mode"`
+
+	Anomaly AnomalyConfig `yaml:"anomaly_detection"`
+	Storage StorageConfig `yaml:"storage"`
+	History HistoryConfig `yaml:"position_history"`
+	Influx  InfluxConfig  `yaml:"influxdb"`
+
+	AlertRetention AlertRetentionConfig `yaml:"alert_retention"`
+	RawLog         RawLogConfig         `yaml:"raw_log"`
+
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Auth          AuthConfig          `yaml:"auth"`
+	TLS           TLSConfig           `yaml:"tls"`
+	RateLimit     RateLimitConfig     `yaml:"rate_limit"`
+	Streams       StreamConfig        `yaml:"streams"`
+	State         StateConfig         `yaml:"state"`
+	Pipeline      PipelineConfig      `yaml:"pipeline"`
+	Health        HealthConfig        `yaml:"health"`
+	Audit         AuditConfig         `yaml:"audit"`
+	Diagnostics   DiagnosticsConfig   `yaml:"diagnostics"`
+	Tracing       TracingConfig       `yaml:"tracing"`
+	Cluster       ClusterConfig       `yaml:"cluster"`
+	Validation    ValidationConfig    `yaml:"validation"`
+	Receiver      ReceiverConfig      `yaml:"receiver"`
+	Airspace      AirspaceConfig      `yaml:"airspace"`
+	TFR           TFRConfig           `yaml:"tfr"`
+	Elevation     ElevationConfig     `yaml:"elevation"`
+	Registry      RegistryConfig      `yaml:"registry"`
+	Routes        RoutesConfig        `yaml:"routes"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Listen:         ":8080",
+		StaticDir:      "./public",
+		EvaluationMode: evaluateAll,
+		Anomaly:        defaultAnomalyConfig(),
+		Storage:        StorageConfig{Backend: backendMemory, MemoryAlerts: 50000},
+		History:        defaultHistoryConfig(),
+		Influx:         defaultInfluxConfig(),
+		AlertRetention: defaultAlertRetentionConfig(),
+		RawLog:         defaultRawLogConfig(),
+		Auth:           defaultAuthConfig(),
+		TLS:            defaultTLSConfig(),
+		Streams:        defaultStreamConfig(),
+		State:          defaultStateConfig(),
+		Pipeline:       defaultPipelineConfig(),
+		Tracing:        defaultTracingConfig(),
+		Cluster:        defaultClusterConfig(),
+		Validation:     defaultValidationConfig(),
+		TFR:            defaultTFRConfig(),
+		Elevation:      defaultElevationConfig(),
+		Registry:       defaultRegistryConfig(),
+		Routes:         defaultRoutesConfig(),
+	}
+}
+
+// loadConfig reads the YAML file at path on top of the defaults, then
+// applies the AIRCRAFT_ALERT_* environment variables. An empty path reads
+// only the variables. Relative file paths in the config are resolved
+// against the directory containing it; the default static directory stays
+// relative to the working directory.
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	source := "environment"
+	if path != "" {
+		source = path
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		dir := filepath.Dir(path)
+		defaults := defaultConfig()
+		for _, p := range []*string{&cfg.StaticDir, &cfg.CriteriaFile, &cfg.ZonesFile, &cfg.AirportsFile, &cfg.AlertRetention.ArchiveFile, &cfg.RawLog.Dir, &cfg.Storage.BoltPath, &cfg.Audit.File, &cfg.TLS.CertFile, &cfg.TLS.KeyFile, &cfg.TLS.Autocert.CacheDir, &cfg.Elevation.Dir, &cfg.Registry.File} {
+			if *p != "" && !filepath.IsAbs(*p) && *p != defaults.StaticDir {
+				*p = filepath.Join(dir, *p)
+			}
+		}
+		for i, p := range cfg.Airspace.Files {
+			if !filepath.IsAbs(p) {
+				cfg.Airspace.Files[i] = filepath.Join(dir, p)
+			}
+		}
+	}
+	if err := applyEnv(&cfg); err != nil {
+		return cfg, err
+	}
+
+	if cfg.EvaluationMode != evaluateAll && cfg.EvaluationMode != evaluateFirstMatch {
+		return cfg, fmt.Errorf("%s: unknown evaluation_mode %q", source, cfg.EvaluationMode)
+	}
+
+	if cfg.AdminListen != "" && cfg.AdminListen == cfg.Listen {
+		return cfg, fmt.Errorf("%s: admin_listen must differ from listen", source)
+	}
+	if err := cfg.TLS.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Notifications.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Auth.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if cfg.Diagnostics.API && !cfg.Auth.enabled() {
+		return cfg, fmt.Errorf("%s: diagnostics.api requires auth.api_keys or auth.users", source)
+	}
+	if err := cfg.RateLimit.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Streams.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if cfg.Storage.MemoryAlerts < 0 {
+		return cfg, fmt.Errorf("%s: storage.memory_alerts must not be negative", source)
+	}
+	if cfg.State.ExpireAfter <= 0 || cfg.State.ExpireAfter > trackRetention {
+		return cfg, fmt.Errorf("%s: state.expire_after must be between 1s and %s", source, trackRetention)
+	}
+	if cfg.State.DeadReckoning < 0 || cfg.State.DeadReckoning > cfg.State.ExpireAfter {
+		return cfg, fmt.Errorf("%s: state.dead_reckoning must be between 0 and state.expire_after", source)
+	}
+	if err := cfg.Receiver.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Validation.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.TFR.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Elevation.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Registry.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Routes.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Pipeline.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Cluster.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.Tracing.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := cfg.RawLog.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", source, err)
+	}
+	if cfg.Health.IngestTimeout < 0 {
+		return cfg, fmt.Errorf("%s: health.ingest_timeout must not be negative", source)
+	}
+	if cfg.Influx.URL != "" && cfg.Influx.Interval <= 0 {
+		return cfg, fmt.Errorf("%s: influxdb.interval must be positive", source)
+	}
+	return cfg, nil
+}
+
+// loadJSONFile decodes the JSON document at path into v.
+func loadJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// Coverage keeps, for each bearing from the receiver and altitude band, the
+// farthest position heard, for range plots like graphs1090's.
+const (
+	coverageBucketDegrees = 5
+	coverageBuckets       = 360 / coverageBucketDegrees
+	// maxCoverageRange ignores positions beyond radio line of sight, which
+	// are bad decodes or spoofing and would spoil the plot for good.
+	maxCoverageRange = 400
+)
+
+// coverageBands are the lower bounds of the altitude bands in feet; the last
+// band has no upper bound.
+var coverageBands = []int{math.MinInt, 10000, 20000, 30000}
+
+// CoveragePoint is the farthest position heard in one bearing bucket.
+type CoveragePoint struct {
+	Bearing   float64    `json:"bearing"`  // Start of the bucket, degrees from true north
+	Distance  float64    `json:"distance"` // Nautical miles; 0 when nothing was heard
+	Latitude  float64    `json:"lat,omitempty"`
+	Longitude float64    `json:"lon,omitempty"`
+	Altitude  int        `json:"alt_baro,omitempty"`
+	ICAO      string     `json:"icao,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// CoverageBand is the range of an altitude band by bearing.
+type CoverageBand struct {
+	MinAltitude *int            `json:"min_altitude"` // Feet; null for the lowest band
+	MaxAltitude *int            `json:"max_altitude"` // Feet, exclusive; null for the highest band
+	MaxRange    float64         `json:"max_range"`    // Nautical miles
+	Points      []CoveragePoint `json:"points"`       // One per bucket, clockwise from north
+}
+
+// Coverage is the receiver's range by bearing, per altitude band and over
+// all altitudes, since the server started or the last reset.
+type Coverage struct {
+	Receiver      ReceiverConfig `json:"receiver"`
+	BucketDegrees int            `json:"bucket_degrees"`
+	Since         time.Time      `json:"since"`
+	All           CoverageBand   `json:"all"`
+	Bands         []CoverageBand `json:"bands"`
+}
+
+var coverage = struct {
+	sync.Mutex
+	since time.Time
+	// farthest is indexed by band, then bucket; band len(coverageBands)
+	// holds all altitudes.
+	farthest [][coverageBuckets]Aircraft
+}{since: time.Now(), farthest: make([][coverageBuckets]Aircraft, len(coverageBands)+1)}
+
+// recordCoverage counts an update annotated by fromReceiver towards the
+// coverage statistics.
+func recordCoverage(aircraft Aircraft) {
+	if !receiver.set() || aircraft.ReceiverDistance > maxCoverageRange {
+		return
+	}
+	bucket := int(aircraft.ReceiverBearing/coverageBucketDegrees) % coverageBuckets
+	band := 0
+	for i, lower := range coverageBands {
+		if aircraft.Altitude >= lower {
+			band = i
+		}
+	}
+	coverage.Lock()
+	defer coverage.Unlock()
+	for _, b := range []int{band, len(coverageBands)} {
+		if aircraft.ReceiverDistance > coverage.farthest[b][bucket].ReceiverDistance {
+			coverage.farthest[b][bucket] = aircraft
+		}
+	}
+}
+
+func coverageBand(farthest *[coverageBuckets]Aircraft) CoverageBand {
+	band := CoverageBand{Points: make([]CoveragePoint, coverageBuckets)}
+	for i, a := range farthest {
+		p := CoveragePoint{Bearing: float64(i * coverageBucketDegrees)}
+		if a.ReceiverDistance > 0 {
+			p.Distance = a.ReceiverDistance
+			p.Latitude, p.Longitude, p.Altitude, p.ICAO = a.Latitude, a.Longitude, a.Altitude, a.ICAO
+			p.Timestamp = &a.Timestamp
+			band.MaxRange = max(band.MaxRange, a.ReceiverDistance)
+		}
+		band.Points[i] = p
+	}
+	return band
+}
+
+// handleCoverage returns the range of the receiver by bearing and altitude
+// band. ?format=geojson returns a polygon per band through the farthest
+// positions, for drawing range rings on a map.
+func handleCoverage(c *jacked.Context) error {
+	if !receiver.set() {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Coverage needs the receiver location in the server configuration"})
+	}
+	coverage.Lock()
+	result := Coverage{Receiver: receiver, BucketDegrees: coverageBucketDegrees, Since: coverage.since}
+	result.All = coverageBand(&coverage.farthest[len(coverageBands)])
+	for i := range coverageBands {
+		band := coverageBand(&coverage.farthest[i])
+		if i > 0 {
+			band.MinAltitude = &coverageBands[i]
+		}
+		if i+1 < len(coverageBands) {
+			band.MaxAltitude = &coverageBands[i+1]
+		}
+		result.Bands = append(result.Bands, band)
+	}
+	coverage.Unlock()
+
+	if wantsGeoJSON(c) {
+		features := []geoJSONFeature{coverageGeoJSON(result.All, nil, nil)}
+		for _, band := range result.Bands {
+			features = append(features, coverageGeoJSON(band, band.MinAltitude, band.MaxAltitude))
+		}
+		return writeGeoJSON(c, features)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// coverageGeoJSON outlines a band as a polygon; buckets where nothing was
+// heard pull the outline in to the receiver.
+func coverageGeoJSON(band CoverageBand, minAltitude, maxAltitude *int) geoJSONFeature {
+	ring := make([][2]float64, 0, len(band.Points)+1)
+	for _, p := range band.Points {
+		if p.Distance == 0 {
+			ring = append(ring, [2]float64{receiver.Longitude, receiver.Latitude})
+		} else {
+			ring = append(ring, [2]float64{p.Longitude, p.Latitude})
+		}
+	}
+	ring = append(ring, ring[0])
+	return geoJSONFeature{
+		Type:     "Feature",
+		Geometry: geoJSONGeometry{Type: "Polygon", Coordinates: [][][2]float64{ring}},
+		Properties: map[string]interface{}{
+			"min_altitude": minAltitude,
+			"max_altitude": maxAltitude,
+			"max_range":    band.MaxRange,
+		},
+	}
+}
+
+// handleResetCoverage starts the coverage statistics over, e.g. after
+// moving the antenna.
+func handleResetCoverage(c *jacked.Context) error {
+	coverage.Lock()
+	coverage.since = time.Now()
+	coverage.farthest = make([][coverageBuckets]Aircraft, len(coverageBands)+1)
+	coverage.Unlock()
+	recordAudit(c, "coverage.reset", "", "")
+	logRequestf(c.Request, "Reset the coverage statistics")
+	return c.JSON(http.StatusOK, map[string]string{"status": "reset"})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// cpaHorizon is how far ahead closest approaches are predicted; beyond it
+// speeds and tracks will have changed.
+const cpaHorizon = 30 * time.Minute
+
+// pointCPA predicts the aircraft's closest approach to the point a
+// criterion is about: the center of its zone, its airport or, for
+// max_distance, the receiver. Callers must hold mu.
+func pointCPA(criterion *AlertCriteria, aircraft Aircraft) *CPA {
+	zone, inZone := zones[criterion.Zone]
+	airport, atAirport := airports[strings.ToUpper(criterion.Airport)]
+	switch {
+	case inZone:
+		lat, lon := zone.center()
+		return predictCPA(aircraft, Aircraft{Latitude: lat, Longitude: lon, Timestamp: aircraft.Timestamp})
+	case atAirport:
+		return predictCPA(aircraft, Aircraft{Latitude: airport.Latitude, Longitude: airport.Longitude, Timestamp: aircraft.Timestamp})
+	case criterion.MaxDistance > 0 && receiver.set():
+		return predictCPA(aircraft, Aircraft{Latitude: receiver.Latitude, Longitude: receiver.Longitude, Timestamp: aircraft.Timestamp})
+	}
+	return nil
+}
+
+// predictCPA returns when and how close the aircraft and other come if both
+// hold their speed and track, or nil if they are not closing or only would
+// beyond cpaHorizon. other's report is first carried forward to the
+// aircraft's; a fixed point is an other without speed or ICAO. Distances
+// use a flat projection around the aircraft, close enough over the ranges
+// alerts care about.
+func predictCPA(aircraft, other Aircraft) *CPA {
+	if dt := aircraft.Timestamp.Sub(other.Timestamp); dt > 0 && other.Speed > 0 {
+		other.Latitude, other.Longitude = destinationPoint(other.Latitude, other.Longitude, other.Track, other.Speed*dt.Hours())
+	}
+	scale := 60 * math.Cos(aircraft.Latitude*math.Pi/180)
+	dLon := math.Mod(other.Longitude-aircraft.Longitude+540, 360) - 180
+	// Positions in nautical miles east and north, speeds in knots.
+	x, y := dLon*scale, (other.Latitude-aircraft.Latitude)*60
+	velocity := func(a Aircraft) (float64, float64) {
+		t := a.Track * math.Pi / 180
+		return a.Speed * math.Sin(t), a.Speed * math.Cos(t)
+	}
+	ax, ay := velocity(aircraft)
+	bx, by := velocity(other)
+	vx, vy := bx-ax, by-ay
+	closing := vx*vx + vy*vy
+	if closing == 0 {
+		return nil
+	}
+	hours := -(x*vx + y*vy) / closing
+	if hours <= 0 || hours > cpaHorizon.Hours() {
+		return nil
+	}
+	lat, lon := destinationPoint(aircraft.Latitude, aircraft.Longitude, aircraft.Track, aircraft.Speed*hours)
+	return &CPA{
+		Seconds:   int(math.Round(hours * 3600)),
+		Distance:  math.Round(math.Hypot(x+vx*hours, y+vy*hours)*100) / 100,
+		Latitude:  math.Round(lat*1e5) / 1e5,
+		Longitude: math.Round(lon*1e5) / 1e5,
+		Other:     other.ICAO,
+	}
+}
+
+// describeCPA formats the approach for alert messages, e.g. "CPA in 4 min,
+// 1.2 nm".
+func describeCPA(c *CPA) string {
+	when := fmt.Sprintf("%d s", c.Seconds)
+	if c.Seconds >= 90 {
+		when = fmt.Sprintf("%.0f min", math.Round(float64(c.Seconds)/60))
+	}
+	return fmt.Sprintf("CPA in %s, %.1f nm", when, c.Distance)
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// newID returns a random identifier suitable for criteria and alerts.
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		log.Panicf("Error generating ID: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// criterionMatches reports whether the aircraft passes the stateless filters of the
+// criterion. Identity fields (ICAO, callsign, ICAO ranges) match if any of
+// them match; squawks, bounds, the zone and the expression must all hold.
+// Callers must hold mu.
+func criterionMatches(ac *AlertCriteria, aircraft Aircraft) bool {
+	hasIdentity := ac.ICAO != "" || ac.Callsign != "" || len(ac.ICAORanges) > 0
+	if hasIdentity {
+		identity := (ac.ICAO != "" && ac.ICAO == aircraft.ICAO) ||
+			(ac.Callsign != "" && ac.Callsign == aircraft.Callsign) ||
+			icaoInRanges(aircraft.ICAO, ac.ICAORanges)
+		if !identity {
+			return false
+		}
+	}
+	if len(ac.Squawks) > 0 && !slices.Contains(ac.Squawks, aircraft.Squawk) {
+		return false
+	}
+	if ac.MinAltitude != 0 && aircraft.Altitude < ac.MinAltitude {
+		return false
+	}
+	if ac.MaxAltitude != 0 && aircraft.Altitude > ac.MaxAltitude {
+		return false
+	}
+	if ac.MinSpeed != 0 && aircraft.Speed < ac.MinSpeed {
+		return false
+	}
+	if ac.MaxSpeed != 0 && aircraft.Speed > ac.MaxSpeed {
+		return false
+	}
+	if ac.MaxDistance != 0 && (!receiver.set() || aircraft.ReceiverDistance > ac.MaxDistance) {
+		return false
+	}
+	if (ac.MinAGL != 0 || ac.MaxAGL != 0) && aircraft.AltitudeAGL == nil {
+		return false
+	}
+	if ac.MinAGL != 0 && *aircraft.AltitudeAGL < ac.MinAGL {
+		return false
+	}
+	if ac.MaxAGL != 0 && *aircraft.AltitudeAGL > ac.MaxAGL {
+		return false
+	}
+	if ac.Airport != "" && !nearCriterionAirport(ac, aircraft) {
+		return false
+	}
+	if ac.Zone != "" {
+		zone, ok := zones[ac.Zone]
+		if !ok || !zone.containsAircraft(aircraft) {
+			return false
+		}
+	}
+	if ac.Expression != "" {
+		match, err := evalExpression(ac.Expression, aircraft)
+		if err != nil {
+			log.Printf("Error evaluating expression for criterion %s: %v", ac.ID, err)
+			return false
+		}
+		return match
+	}
+	return true
+}
+
+// Alert event types.
+const (
+	eventMatch       = "match"
+	eventLoiter      = "loiter"
+	eventZoneEntered = "zone_entered"
+	eventZoneExited  = "zone_exited"
+	eventSignalLost  = "signal_lost"
+	eventTakeoff     = "takeoff"
+	eventLanding     = "landing"
+	eventProximity   = "proximity"
+)
+
+// evaluateCriterion reports whether the criterion fires for this update and, if so,
+// the event type and alert message, and for proximity the pair's predicted
+// closest approach. Loiter, airport and proximity detection
+// run only for aircraft passing the stateless filters; zone criteria fire
+// once per crossing; signal-loss criteria only fire from the periodic check.
+// Callers must hold mu.
+func evaluateCriterion(ac *AlertCriteria, aircraft Aircraft) (event, message string, cpa *CPA, ok bool) {
+	if !ac.HasConditions() {
+		return "", "", nil, false
+	}
+	matched := criterionMatches(ac, aircraft)
+	switch {
+	case ac.SignalLoss != nil:
+		// Fired by checkSignalLoss; a fresh report ends the outage.
+		delete(signalLost, ac.ID+"|"+aircraft.ICAO)
+		return "", "", nil, false
+	case ac.Loiter != nil:
+		if !matched {
+			return "", "", nil, false
+		}
+		message, ok = detectLoiter(ac, aircraft)
+		return eventLoiter, message, nil, ok
+	case ac.AirportOps != nil:
+		if !matched {
+			return "", "", nil, false
+		}
+		event, message, ok = detectAirportOps(ac, aircraft)
+		return event, message, nil, ok
+	case ac.Proximity != nil:
+		if !matched {
+			return "", "", nil, false
+		}
+		return detectProximity(ac, aircraft)
+	case ac.Zone != "":
+		event, message, ok = zoneTransition(ac, aircraft, matched)
+		return event, message, nil, ok
+	case matched:
+		return eventMatch, "Monitored aircraft detected: " + aircraft.Callsign + " (" + aircraft.ICAO + ")", nil, true
+	}
+	return "", "", nil, false
+}
+
+// validateCriterion checks that the criterion can be evaluated. Callers must hold mu.
+func validateCriterion(ac *AlertCriteria) error {
+	if ac.Exclude && (ac.Loiter != nil || ac.SignalLoss != nil || ac.AirportOps != nil || ac.Proximity != nil) {
+		return errors.New("exclusion criteria only support matching conditions")
+	}
+	if ac.MaxDistance < 0 {
+		return errors.New("max_distance must not be negative")
+	}
+	if ac.MaxDistance > 0 && !receiver.set() {
+		return errors.New("max_distance needs the receiver location in the server configuration")
+	}
+	if (ac.MinAGL != 0 || ac.MaxAGL != 0) && elevation == nil {
+		return errors.New("min_agl and max_agl need elevation data in the server configuration")
+	}
+	if ac.Airport != "" {
+		if _, ok := airports[strings.ToUpper(ac.Airport)]; !ok {
+			return fmt.Errorf("unknown airport %q", ac.Airport)
+		}
+	}
+	if ac.AirportRadius < 0 {
+		return errors.New("airport_radius must not be negative")
+	}
+	if ac.Zone != "" {
+		if _, ok := zones[ac.Zone]; !ok {
+			return fmt.Errorf("unknown zone %q", ac.Zone)
+		}
+	}
+	if ac.Expression != "" {
+		if _, err := compileExpression(ac.Expression); err != nil {
+			return fmt.Errorf("invalid expression: %w", err)
+		}
+	}
+	if ac.Loiter != nil {
+		if err := validateLoiter(*ac.Loiter); err != nil {
+			return err
+		}
+	}
+	if ac.SignalLoss != nil {
+		if err := validateSignalLoss(*ac.SignalLoss); err != nil {
+			return err
+		}
+	}
+	if ac.AirportOps != nil {
+		if err := validateAirportOps(*ac.AirportOps); err != nil {
+			return err
+		}
+	}
+	if ac.Proximity != nil {
+		if err := validateProximity(*ac.Proximity); err != nil {
+			return err
+		}
+	}
+	for _, r := range ac.ICAORanges {
+		if _, _, err := parseICAORange(r); err != nil {
+			return err
+		}
+	}
+	if ac.MessageTemplate != "" {
+		if _, err := compileTemplate(ac.MessageTemplate); err != nil {
+			return fmt.Errorf("invalid message template: %w", err)
+		}
+	}
+	if slack, ok := activeConfig.Notifications.notifier("slack").(*slackNotifier); ok && ac.SlackChannel != "" {
+		if _, ok := slack.cfg.Channels[ac.SlackChannel]; !ok {
+			return fmt.Errorf("unknown slack channel %q", ac.SlackChannel)
+		}
+	}
+	if err := validateNotify(ac); err != nil {
+		return err
+	}
+	switch ac.Severity {
+	case "", severityInfo, severityWarning, severityCritical:
+	default:
+		return fmt.Errorf("unknown severity %q", ac.Severity)
+	}
+	return nil
+}
+
+// addAlertCriterion assigns a stable ID to criterion and stores it.
+func addAlertCriterion(criterion AlertCriteria) (AlertCriteria, error) {
+	criterion.ID = newID()
+	return criterion, store.SaveCriterion(criterion)
+}
+
+// loadAlertCriteria registers the criteria listed in the JSON file at path.
+// Criteria default to enabled unless the file says otherwise, and keep the
+// id the file gives them so a reload can update them.
+func loadAlertCriteria(path string) error {
+	criteria, err := readCriteriaFile(path)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i, criterion := range criteria {
+		if err := validateCriterion(&criterion); err != nil {
+			return fmt.Errorf("%s: criterion %d: %w", path, i, err)
+		}
+		if criterion.ID != "" {
+			err = store.SaveCriterion(criterion)
+		} else {
+			_, err = addAlertCriterion(criterion)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCriteriaFile reads the JSON list of criteria at path.
+func readCriteriaFile(path string) ([]AlertCriteria, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	criteria, err := readCriteriaJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return criteria, nil
+}
+
+// handleGetAlertCriterion returns a single criterion. It also serves
+// /api/v1/alert-criteria/export, which the router cannot register next to :id.
+func handleGetAlertCriterion(c *jacked.Context) error {
+	if c.Param("id") == "export" {
+		return handleExportCriteria(c)
+	}
+	criterion, err := store.GetCriterion(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Criterion not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, criterion)
+}
+
+// handleUpdateAlertCriterion replaces a criterion with the request body.
+func handleUpdateAlertCriterion(c *jacked.Context) error {
+	return updateAlertCriterion(c, func(criterion *AlertCriteria, body []byte) error {
+		*criterion = AlertCriteria{Enabled: true}
+		return json.Unmarshal(body, criterion)
+	})
+}
+
+// handlePatchAlertCriterion applies the request body to the criterion as
+// an RFC 7386 merge patch: fields present replace those stored, e.g.
+// {"enabled": false}, null removes one, e.g. {"zone": null}, and objects
+// such as loiter are merged field by field.
+func handlePatchAlertCriterion(c *jacked.Context) error {
+	return updateAlertCriterion(c, func(criterion *AlertCriteria, body []byte) error {
+		var patch map[string]interface{}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			return err
+		} else if patch == nil {
+			return errors.New("merge patch must be an object")
+		}
+		var stored map[string]interface{}
+		data, err := json.Marshal(criterion)
+		if err == nil {
+			err = json.Unmarshal(data, &stored)
+		}
+		if err == nil {
+			data, err = json.Marshal(mergePatch(stored, patch))
+		}
+		if err != nil {
+			return err
+		}
+		*criterion = AlertCriteria{}
+		return json.Unmarshal(data, criterion)
+	})
+}
+
+// mergePatch applies patch to target, both decoded JSON, as RFC 7386
+// describes.
+func mergePatch(target, patch interface{}) interface{} {
+	fields, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	merged, ok := target.(map[string]interface{})
+	if !ok {
+		merged = map[string]interface{}{}
+	}
+	for name, value := range fields {
+		if value == nil {
+			delete(merged, name)
+		} else {
+			merged[name] = mergePatch(merged[name], value)
+		}
+	}
+	return merged
+}
+
+// updateAlertCriterion applies the request body to the stored criterion
+// with apply and saves the result. The ID and hit history stay as they are.
+func updateAlertCriterion(c *jacked.Context, apply func(criterion *AlertCriteria, body []byte) error) error {
+	body, err := readBody(c, maxCriterionBody)
+	if err != nil {
+		return bodyError(c, err, "Invalid criteria data")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	existing, err := store.GetCriterion(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Criterion not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	criterion := existing
+	if err := apply(&criterion, body); err != nil {
+		logRequestf(c.Request, "Error decoding alert criteria update: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid criteria data"})
+	}
+	criterion.ID = existing.ID
+	criterion.HitCount = existing.HitCount
+	criterion.FalsePositives = existing.FalsePositives
+	criterion.LastTriggered = existing.LastTriggered
+	if err := validateCriterion(&criterion); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := store.SaveCriterion(criterion); err != nil {
+		return storeError(c, err)
+	}
+
+	logRequestf(c.Request, "Updated alert criterion: %+v", criterion)
+	return c.JSON(http.StatusOK, criterion)
+}
+
+// handleDeleteAlertCriterion removes a criterion. Alerts it raised are kept.
+func handleDeleteAlertCriterion(c *jacked.Context) error {
+	mu.Lock()
+	err := store.DeleteCriterion(c.Param("id"))
+	mu.Unlock()
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Criterion not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	logRequestf(c.Request, "Deleted alert criterion %s", c.Param("id"))
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// handleListAlertCriteria returns all criteria, optionally only those
+// carrying every ?tag= given.
+func handleListAlertCriteria(c *jacked.Context) error {
+	tags := c.Request.URL.Query()["tag"]
+	criteria, err := store.ListCriteria()
+	if err != nil {
+		return storeError(c, err)
+	}
+	list := make([]AlertCriteria, 0, len(criteria))
+	for _, criterion := range criteria {
+		if criterion.HasTags(tags) {
+			list = append(list, criterion)
+		}
+	}
+	return c.JSON(http.StatusOK, list)
+}
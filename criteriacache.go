@@ -0,0 +1,52 @@
+package main
+
+import "sync/atomic"
+
+// criteriaSnapshot is the criteria list evaluation works from. It is shared
+// between goroutines and never modified; a write to any criterion makes the
+// next evaluation load a new one.
+type criteriaSnapshot struct {
+	version  uint64
+	criteria []AlertCriteria
+}
+
+var (
+	criteriaVersion atomic.Uint64 // Raised by every criteria write
+	criteriaCache   atomic.Pointer[criteriaSnapshot]
+)
+
+// activeCriteria returns the current criteria without going to the store
+// unless they changed. Callers must copy a criterion before changing it.
+func activeCriteria() ([]AlertCriteria, error) {
+	version := criteriaVersion.Load()
+	if s := criteriaCache.Load(); s != nil && s.version == version {
+		return s.criteria, nil
+	}
+	criteria, err := store.ListCriteria()
+	if err != nil {
+		return nil, err
+	}
+	criteriaCache.Store(&criteriaSnapshot{version: version, criteria: criteria})
+	return criteria, nil
+}
+
+// criteriaStore wraps a backend so criteria writes, whichever handler makes
+// them, invalidate the snapshot.
+type criteriaStore struct {
+	Store
+}
+
+func (s criteriaStore) SaveCriterion(criterion AlertCriteria) error {
+	defer criteriaVersion.Add(1)
+	return s.Store.SaveCriterion(criterion)
+}
+
+func (s criteriaStore) DeleteCriterion(id string) error {
+	defer criteriaVersion.Add(1)
+	return s.Store.DeleteCriterion(id)
+}
+
+func (s criteriaStore) ReplaceCriteria(criteria []AlertCriteria) error {
+	defer criteriaVersion.Add(1)
+	return s.Store.ReplaceCriteria(criteria)
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// maxImportSize bounds criteria import bodies.
+const maxImportSize = 8 << 20
+
+// criteriaCSVColumns are the AlertCriteria fields written to and read from
+// CSV, named by their JSON keys. Hit statistics are left out: they belong to
+// the instance, not the rule set.
+var criteriaCSVColumns = func() []reflect.StructField {
+	var fields []reflect.StructField
+	t := reflect.TypeOf(AlertCriteria{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		switch f.Name {
+		case "HitCount", "FalsePositives", "LastTriggered":
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}()
+
+// csvColumn returns the column name of a field: its JSON key.
+func csvColumn(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
+
+// exportedCriteria returns the criteria in ID order with their hit
+// statistics cleared, so exports of an unchanged rule set are identical.
+func exportedCriteria(tags []string) ([]AlertCriteria, error) {
+	criteria, err := store.ListCriteria()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]AlertCriteria, 0, len(criteria))
+	for _, criterion := range criteria {
+		if !criterion.HasTags(tags) {
+			continue
+		}
+		criterion.HitCount, criterion.FalsePositives, criterion.LastTriggered = 0, 0, nil
+		list = append(list, criterion)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// handleExportCriteria returns every criterion, or those carrying all ?tag=
+// given, as JSON (the POST /api/v1/alert-criteria schema) or with ?format=csv
+// as CSV. Either can be fed back to POST /api/v1/alert-criteria/import.
+func handleExportCriteria(c *jacked.Context) error {
+	query := c.Request.URL.Query()
+	criteria, err := exportedCriteria(query["tag"])
+	if err != nil {
+		return storeError(c, err)
+	}
+	switch query.Get("format") {
+	case "", "json":
+		data, err := json.MarshalIndent(criteria, "", "  ")
+		if err != nil {
+			return err
+		}
+		c.Response.Header().Set("Content-Type", "application/json")
+		c.Response.Header().Set("Content-Disposition", `attachment; filename="criteria.json"`)
+		_, err = c.Response.Write(append(data, '\n'))
+		return err
+	case "csv":
+		var buf bytes.Buffer
+		if err := writeCriteriaCSV(&buf, criteria); err != nil {
+			return err
+		}
+		c.Response.Header().Set("Content-Type", "text/csv")
+		c.Response.Header().Set("Content-Disposition", `attachment; filename="criteria.csv"`)
+		_, err = c.Response.Write(buf.Bytes())
+		return err
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "format must be json or csv"})
+	}
+}
+
+// writeCriteriaCSV writes one row per criterion. Lists are joined with ";"
+// and detector settings (loiter, signal_loss, ...) are JSON objects.
+func writeCriteriaCSV(w io.Writer, criteria []AlertCriteria) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(criteriaCSVColumns))
+	for i, f := range criteriaCSVColumns {
+		header[i] = csvColumn(f)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, criterion := range criteria {
+		v := reflect.ValueOf(criterion)
+		row := make([]string, len(criteriaCSVColumns))
+		for i, f := range criteriaCSVColumns {
+			cell, err := formatCSVCell(v.FieldByIndex(f.Index))
+			if err != nil {
+				return fmt.Errorf("criterion %s: %s: %w", criterion.ID, csvColumn(f), err)
+			}
+			row[i] = cell
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSVCell(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int:
+		if v.Int() == 0 {
+			return "", nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Float64:
+		if v.Float() == 0 {
+			return "", nil
+		}
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Slice:
+		return strings.Join(v.Interface().([]string), ";"), nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "", nil
+		}
+		data, err := json.Marshal(v.Interface())
+		return string(data), err
+	}
+	return "", fmt.Errorf("unsupported field type %s", v.Type())
+}
+
+// readCriteriaCSV parses criteria written by writeCriteriaCSV. Columns may
+// come in any order and be left out; an empty enabled cell means enabled.
+func readCriteriaCSV(r io.Reader) ([]AlertCriteria, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("empty CSV")
+	}
+	fields := make([]*reflect.StructField, len(rows[0]))
+	for i, name := range rows[0] {
+		for j, f := range criteriaCSVColumns {
+			if csvColumn(f) == strings.TrimSpace(name) {
+				fields[i] = &criteriaCSVColumns[j]
+			}
+		}
+		if fields[i] == nil {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	criteria := make([]AlertCriteria, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		criterion := AlertCriteria{Enabled: true}
+		v := reflect.ValueOf(&criterion).Elem()
+		for i, cell := range row {
+			if err := parseCSVCell(v.FieldByIndex(fields[i].Index), strings.TrimSpace(cell)); err != nil {
+				return nil, fmt.Errorf("row %d: %s: %w", n+2, csvColumn(*fields[i]), err)
+			}
+		}
+		criteria = append(criteria, criterion)
+	}
+	return criteria, nil
+}
+
+func parseCSVCell(v reflect.Value, cell string) error {
+	if cell == "" {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(cell)
+	case reflect.Int:
+		n, err := strconv.Atoi(cell)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(cell, ";") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		if err := json.Unmarshal([]byte(cell), p.Interface()); err != nil {
+			return err
+		}
+		v.Set(p)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// readCriteriaJSON parses a JSON list of criteria in the POST
+// /api/v1/alert-criteria schema. Criteria default to enabled.
+func readCriteriaJSON(r io.Reader) ([]AlertCriteria, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	criteria := make([]AlertCriteria, 0, len(raw))
+	for i, data := range raw {
+		criterion := AlertCriteria{Enabled: true}
+		if err := json.Unmarshal(data, &criterion); err != nil {
+			return nil, fmt.Errorf("criterion %d: %w", i, err)
+		}
+		criteria = append(criteria, criterion)
+	}
+	return criteria, nil
+}
+
+// handleImportCriteria loads criteria exported by GET
+// /api/v1/alert-criteria/export, as JSON or CSV (Content-Type text/csv or
+// ?format=csv). Criteria whose ID exists are replaced, keeping their hit
+// statistics, and the rest are added; with ?mode=replace criteria missing
+// from the import are deleted too. Nothing is changed unless every
+// criterion is valid.
+func handleImportCriteria(c *jacked.Context) error {
+	query := c.Request.URL.Query()
+	mode := query.Get("mode")
+	if mode != "" && mode != "merge" && mode != "replace" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "mode must be merge or replace"})
+	}
+	format := query.Get("format")
+	if format == "" {
+		if mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type")); mediaType == "text/csv" {
+			format = "csv"
+		}
+	}
+	if format != "" && format != "json" && format != "csv" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "format must be json or csv"})
+	}
+	body, err := readBody(c, maxImportSize)
+	if err != nil {
+		return bodyError(c, err, "Invalid criteria data")
+	}
+	var criteria []AlertCriteria
+	if format == "csv" {
+		criteria, err = readCriteriaCSV(bytes.NewReader(body))
+	} else {
+		criteria, err = readCriteriaJSON(bytes.NewReader(body))
+	}
+	if err != nil {
+		logRequestf(c.Request, "Error decoding criteria import: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid criteria data: " + err.Error()})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	seen := map[string]bool{}
+	for i, criterion := range criteria {
+		if err := validateCriterion(&criterion); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("criterion %d: %v", i, err)})
+		}
+		if criterion.ID != "" {
+			if seen[criterion.ID] {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("duplicate criterion ID %q", criterion.ID)})
+			}
+			seen[criterion.ID] = true
+		}
+	}
+	created, updated, deleted, err := mergeCriteria(criteria, seen, mode == "replace")
+	if err != nil {
+		return storeError(c, err)
+	}
+
+	logRequestf(c.Request, "Imported criteria: %d created, %d updated, %d deleted", created, updated, deleted)
+	return c.JSON(http.StatusOK, map[string]int{"created": created, "updated": updated, "deleted": deleted})
+}
+
+// mergeCriteria saves imported criteria: those whose ID is stored replace
+// it, keeping its hit statistics, and the rest are added. With replace,
+// stored criteria whose ID is not in seen are deleted. The criteria must be
+// valid. Callers must hold mu.
+func mergeCriteria(criteria []AlertCriteria, seen map[string]bool, replace bool) (created, updated, deleted int, err error) {
+	existing, err := store.ListCriteria()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	current := make(map[string]AlertCriteria, len(existing))
+	for _, criterion := range existing {
+		current[criterion.ID] = criterion
+	}
+
+	for _, criterion := range criteria {
+		if old, ok := current[criterion.ID]; ok {
+			criterion.HitCount, criterion.FalsePositives, criterion.LastTriggered = old.HitCount, old.FalsePositives, old.LastTriggered
+			updated++
+		} else {
+			if criterion.ID == "" {
+				criterion.ID = newID()
+			}
+			criterion.HitCount, criterion.FalsePositives, criterion.LastTriggered = 0, 0, nil
+			created++
+		}
+		if err := store.SaveCriterion(criterion); err != nil {
+			return created, updated, deleted, err
+		}
+	}
+	if replace {
+		for id := range current {
+			if seen[id] {
+				continue
+			}
+			if err := store.DeleteCriterion(id); err != nil {
+				return created, updated, deleted, err
+			}
+			deleted++
+		}
+	}
+	return created, updated, deleted, nil
+}
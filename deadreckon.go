@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"time"
+)
+
+// deadReckonEvery is how often silent aircraft are moved on in the stream,
+// and how long one must be silent before it is.
+const deadReckonEvery = 2 * time.Second
+
+// deadReckon returns last moved along its track at its ground speed to now,
+// flagged as estimated. Only moving aircraft silent for between
+// deadReckonEvery and stateConfig.DeadReckoning are projected; ok is false
+// for the rest.
+func deadReckon(last Aircraft, now time.Time) (Aircraft, bool) {
+	age := now.Sub(last.Timestamp)
+	if stateConfig.DeadReckoning <= 0 || age < deadReckonEvery || age > stateConfig.DeadReckoning || last.Speed <= 0 {
+		return last, false
+	}
+	lat, lon := destinationPoint(last.Latitude, last.Longitude, last.Track, last.Speed*age.Hours())
+	// Five decimals is about a metre, finer than the projection.
+	last.Latitude = math.Round(lat*1e5) / 1e5
+	last.Longitude = math.Round(lon*1e5) / 1e5
+	last = annotateAircraft(last)
+	last.Estimated = true
+	return last, true
+}
+
+// broadcastEstimates sends stream clients an aircraftUpdate with the
+// estimated position of every silent aircraft every deadReckonEvery, so
+// their tracks neither freeze nor jump when reports resume. The estimates
+// go to clients only: the state registry, history and detectors keep the
+// received positions.
+func broadcastEstimates() {
+	ticker := time.NewTicker(deadReckonEvery)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		for _, last := range allStates() {
+			estimate, ok := deadReckon(last, now)
+			if !ok {
+				continue
+			}
+			data, err := json.Marshal(estimate)
+			if err != nil {
+				log.Printf("Error marshalling estimated position for SSE update: %v", err)
+				continue
+			}
+			hub.broadcast <- []byte("event: aircraftUpdate\ndata: " + string(data) + "\n\n")
+		}
+	}
+}
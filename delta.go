@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// deltaEncoder rewrites an SSE client's position updates, single or
+// batched, as aircraftDelta events: a JSON array of objects holding the ICAO
+// address and only the fields that changed since the client was last sent
+// the aircraft, with null for fields that went away. An aircraft the client
+// has not been sent yet goes out in full. Every streams.snapshot_interval
+// the client gets an aircraftSnapshot event, the full picture, and the
+// deltas continue from it.
+type deltaEncoder struct {
+	sent map[string]map[string]json.RawMessage // By ICAO address
+}
+
+func newDeltaEncoder() *deltaEncoder {
+	return &deltaEncoder{sent: make(map[string]map[string]json.RawMessage)}
+}
+
+// encode returns the frame to send the client in place of message, or nil
+// when nothing changed. Events other than position updates pass unchanged.
+func (d *deltaEncoder) encode(message []byte) []byte {
+	event, data := parseSSEFrame(message)
+	var updates []json.RawMessage
+	switch event {
+	case "aircraftUpdate":
+		updates = []json.RawMessage{data}
+	case "aircraftBatch":
+		if json.Unmarshal(data, &updates) != nil {
+			return message
+		}
+	case "aircraftRemoved", "expired":
+		var removed struct {
+			ICAO string `json:"icao"`
+		}
+		if json.Unmarshal(data, &removed) == nil {
+			delete(d.sent, removed.ICAO)
+		}
+		return message
+	default:
+		return message
+	}
+
+	var deltas []map[string]json.RawMessage
+	for _, update := range updates {
+		var fields map[string]json.RawMessage
+		var icao string
+		if json.Unmarshal(update, &fields) != nil || json.Unmarshal(fields["icao"], &icao) != nil {
+			continue
+		}
+		previous := d.sent[icao]
+		d.sent[icao] = fields
+		delta := map[string]json.RawMessage{"icao": fields["icao"]}
+		for k, v := range fields {
+			if !bytes.Equal(previous[k], v) {
+				delta[k] = v
+			}
+		}
+		for k := range previous {
+			if _, ok := fields[k]; !ok {
+				delta[k] = json.RawMessage("null")
+			}
+		}
+		if len(delta) > 1 {
+			deltas = append(deltas, delta)
+		}
+	}
+	if len(deltas) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(deltas)
+	if err != nil {
+		return message
+	}
+	var frame bytes.Buffer
+	if id := sseFrameID(message); id != nil {
+		frame.WriteString("id: ")
+		frame.Write(id)
+		frame.WriteByte('\n')
+	}
+	frame.WriteString("event: aircraftDelta\ndata: ")
+	frame.Write(body)
+	frame.WriteString("\n\n")
+	return frame.Bytes()
+}
+
+// snapshot returns the aircraftSnapshot event of the aircraft passing f and
+// makes them the state the following deltas apply to. It carries no event
+// ID, so it leaves the client's resume position alone.
+func (d *deltaEncoder) snapshot(aircraft []Aircraft, f *streamFilter) []byte {
+	sort.Slice(aircraft, func(i, j int) bool { return aircraft[i].ICAO < aircraft[j].ICAO })
+	list := []json.RawMessage{}
+	d.sent = make(map[string]map[string]json.RawMessage)
+	for _, a := range aircraft {
+		data, err := json.Marshal(a)
+		if err != nil || (f != nil && !f.matchesEvent(&streamEvent{name: "aircraftUpdate", data: data})) {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			continue
+		}
+		d.sent[a.ICAO] = fields
+		list = append(list, data)
+	}
+	body, _ := json.Marshal(list)
+	return []byte("event: aircraftSnapshot\ndata: " + string(body) + "\n\n")
+}
+
+// sseFrameID returns the value of the frame's id: line, if any.
+func sseFrameID(message []byte) []byte {
+	for _, line := range bytes.Split(message, []byte("\n")) {
+		if v, ok := bytes.CutPrefix(line, []byte("id: ")); ok {
+			return v
+		}
+	}
+	return nil
+}
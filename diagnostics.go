@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"expvar"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// DiagnosticsConfig exposes the Go profiler (net/http/pprof) and runtime
+// variables (expvar) for investigating memory growth and stuck streams in
+// production. Both are off by default.
+type DiagnosticsConfig struct {
+	// Listen serves them at /debug/pprof/ and /debug/vars on their own
+	// address without authentication; keep it on localhost.
+	Listen string `yaml:"listen"`
+	// API serves them to admin keys and users at /api/v1/debug/pprof/ and
+	// /api/v1/debug/vars on the main listener, or admin_listen when set. It
+	// requires auth.
+	API bool `yaml:"api"`
+}
+
+func init() {
+	expvar.Publish("aircraft_alert", expvar.Func(func() any {
+		return map[string]any{
+			"goroutines":     runtime.NumGoroutine(),
+			"stream_clients": hub.clientCount.Load(),
+			"aircraft":       len(allStates()),
+			"pipeline":       pipelineStats(),
+		}
+	}))
+}
+
+// diagnosticsHandler serves pprof and expvar under /debug/.
+func diagnosticsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+// serveDiagnostics starts the diagnostics listener and returns it for
+// shutdown. Profiles take up to a minute, so writes are not cut short.
+func serveDiagnostics(addr string) *http.Server {
+	server := &http.Server{Addr: addr, Handler: diagnosticsHandler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Diagnostics: %v", err)
+		}
+	}()
+	log.Printf("Serving pprof and expvar on %s", addr)
+	return server
+}
+
+// handleDiagnostics serves /api/v1/debug/* (and its unversioned twin) from
+// diagnosticsHandler.
+func handleDiagnostics(c *jacked.Context) error {
+	r := c.Request.Clone(c.Request.Context())
+	if i := strings.Index(r.URL.Path, "/debug/"); i >= 0 {
+		r.URL.Path, r.URL.RawPath = r.URL.Path[i:], ""
+	}
+	diagnosticsHandler().ServeHTTP(c.Response, r)
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// dryRunMatch is an alert a candidate criterion would have raised.
+type dryRunMatch struct {
+	Event     string    `json:"event"`
+	Message   string    `json:"message"`
+	Aircraft  Aircraft  `json:"aircraft"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// detectorState bundles the per-criterion detector state so a dry run can
+// evaluate against fresh state and leave the live state untouched.
+type detectorState struct {
+	tracks         map[string][]Aircraft
+	zonePresence   map[string]bool
+	loitering      map[string]bool
+	signalLost     map[string]bool
+	onGround       map[string]bool
+	proximityPairs map[string]bool
+	nearby         func(lat, lon, nm float64) []Aircraft
+}
+
+// swapDetectorState installs st as the live detector state and returns the
+// previous one. Callers must hold mu.
+func swapDetectorState(st detectorState) detectorState {
+	prev := detectorState{tracks, zonePresence, loitering, signalLost, onGround, proximityPairs, nearbyAircraft}
+	tracks, zonePresence, loitering, signalLost, onGround, proximityPairs, nearbyAircraft =
+		st.tracks, st.zonePresence, st.loitering, st.signalLost, st.onGround, st.proximityPairs, st.nearby
+	return prev
+}
+
+func freshDetectorState(tracks map[string][]Aircraft) detectorState {
+	return detectorState{tracks, map[string]bool{}, map[string]bool{}, map[string]bool{}, map[string]bool{}, map[string]bool{}, indexedNearby}
+}
+
+// maxDryRunPoints caps the track points a history dry run replays, so one
+// request holds mu for a bounded time; the newest are kept.
+const maxDryRunPoints = 20000
+
+// dryRunPoints returns the positions a dry run evaluates: the latest of every
+// tracked aircraft or, with history, every retained track point. Callers
+// must hold mu.
+func dryRunPoints(history bool) []Aircraft {
+	var points []Aircraft
+	for _, track := range tracks {
+		if history {
+			points = append(points, track...)
+		} else if len(track) > 0 {
+			points = append(points, track[len(track)-1])
+		}
+	}
+	return points
+}
+
+// dryRunCriterion evaluates a candidate criterion against points, in time
+// order, without recording alerts or touching live detector state. With
+// history the points are replayed from empty tracks, so stateful detectors
+// see the same sequence they would have seen live. Callers must hold mu.
+func dryRunCriterion(candidate *AlertCriteria, points []Aircraft, history bool) []dryRunMatch {
+	state := freshDetectorState(tracks)
+	replayed := newAircraftGrid()
+	if history {
+		state.tracks = map[string][]Aircraft{}
+		state.nearby = func(lat, lon, nm float64) []Aircraft {
+			return replayed.in(boxesAround(lat, lon, nm)...)
+		}
+	}
+	saved := swapDetectorState(state)
+	defer swapDetectorState(saved)
+
+	matches := []dryRunMatch{}
+	add := func(aircraft Aircraft, event, message string) {
+		matches = append(matches, dryRunMatch{
+			Event:     event,
+			Message:   renderAlertMessage(candidate, aircraft, event, message),
+			Aircraft:  aircraft,
+			Timestamp: aircraft.Timestamp,
+		})
+	}
+
+	for _, aircraft := range points {
+		if history {
+			recordTrackPoint(aircraft)
+			replayed.file(aircraft)
+		}
+		if candidate.Exclude {
+			if candidate.HasConditions() && criterionMatches(candidate, aircraft) {
+				add(aircraft, "excluded", "Alerts would be suppressed for "+aircraft.Callsign+" ("+aircraft.ICAO+")")
+			}
+			continue
+		}
+		if event, message, _, ok := evaluateCriterion(candidate, aircraft); ok {
+			add(aircraft, event, message)
+		}
+	}
+	if candidate.SignalLoss != nil && !history {
+		for _, lost := range detectSignalLoss(candidate, time.Now()) {
+			add(lost.aircraft, eventSignalLost, lost.message)
+		}
+	}
+	return matches
+}
+
+// handleTestAlertCriterion evaluates the criterion in the request body
+// against the tracked aircraft (and, with ?history=true, their recent
+// tracks) and returns what would have matched.
+func handleTestAlertCriterion(c *jacked.Context) error {
+	candidate := AlertCriteria{Enabled: true}
+	body, err := readBody(c, maxCriterionBody)
+	if err == nil {
+		err = json.Unmarshal(body, &candidate)
+	}
+	if err != nil {
+		return bodyError(c, err, "Invalid criteria data")
+	}
+	candidate.ID = "dry-run"
+	history := c.Request.URL.Query().Get("history") == "true"
+
+	mu.Lock()
+	err = validateCriterion(&candidate)
+	var points []Aircraft
+	if err == nil {
+		points = dryRunPoints(history)
+	}
+	mu.Unlock()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Sorting and trimming need no lock; only the replay itself does.
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	truncated := len(points) > maxDryRunPoints
+	if truncated {
+		points = points[len(points)-maxDryRunPoints:]
+	}
+	mu.Lock()
+	matches := dryRunCriterion(&candidate, points, history)
+	mu.Unlock()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"criterion": candidate,
+		"history":   history,
+		"truncated": truncated,
+		"matches":   matches,
+	})
+}
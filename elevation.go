@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// ElevationConfig points the server at SRTM terrain tiles, the .hgt files
+// NASA and viewfinderpanoramas.org publish, so every update gets its height
+// above the ground (alt_agl) and criteria can set min_agl and max_agl.
+type ElevationConfig struct {
+	// Dir holds the tiles under their usual names, e.g. N51W001.hgt for
+	// 51°N 1°W, at 1 or 3 arc-seconds. A tile missing from it is taken as
+	// sea level, so the ocean needs none.
+	Dir      string `yaml:"dir"`
+	MaxTiles int    `yaml:"max_tiles"` // Tiles kept in memory, up to 25 MB each
+}
+
+func defaultElevationConfig() ElevationConfig {
+	return ElevationConfig{MaxTiles: 16}
+}
+
+func (c ElevationConfig) validate() error {
+	if c.Dir != "" && c.MaxTiles < 1 {
+		return errors.New("elevation.max_tiles must be at least 1")
+	}
+	return nil
+}
+
+// srtmVoid marks samples without data, such as deep valleys in the 2000
+// survey.
+const srtmVoid = -32768
+
+// srtmTile is one 1° tile: size by size big-endian samples in metres, rows
+// from north to south, overlapping its neighbours by one sample.
+type srtmTile struct {
+	size int // 0 for a tile missing from the directory: sea level
+	data []byte
+	err  error // Why the tile could not be read; its terrain is unknown
+}
+
+// elevationData loads tiles as positions need them and keeps the most
+// recently used.
+type elevationData struct {
+	dir      string
+	maxTiles int
+
+	mu    sync.Mutex
+	tiles map[string]*srtmTile
+	order []string // Least recently used first
+}
+
+// elevation is the configured terrain, or nil without elevation data.
+var elevation *elevationData
+
+func newElevationData(cfg ElevationConfig) (*elevationData, error) {
+	info, err := os.Stat(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", cfg.Dir)
+	}
+	return &elevationData{dir: cfg.Dir, maxTiles: cfg.MaxTiles, tiles: map[string]*srtmTile{}}, nil
+}
+
+// srtmTileName returns the name of the tile covering a position, after its
+// south-west corner.
+func srtmTileName(lat, lon float64) string {
+	ns, ew := 'N', 'E'
+	ilat, ilon := int(math.Floor(lat)), int(math.Floor(lon))
+	if ilat < 0 {
+		ns, ilat = 'S', -ilat
+	}
+	if ilon < 0 {
+		ew, ilon = 'W', -ilon
+	}
+	return fmt.Sprintf("%c%02d%c%03d.hgt", ns, ilat, ew, ilon)
+}
+
+// tile returns the tile of that name, reading it if it is not in memory.
+func (e *elevationData) tile(name string) *srtmTile {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if t, ok := e.tiles[name]; ok {
+		i := slices.Index(e.order, name)
+		e.order = append(slices.Delete(e.order, i, i+1), name)
+		return t
+	}
+
+	t := &srtmTile{}
+	data, err := os.ReadFile(filepath.Join(e.dir, name))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		t.err = err
+	default:
+		size := int(math.Round(math.Sqrt(float64(len(data) / 2))))
+		if size < 2 || size*size*2 != len(data) {
+			t.err = fmt.Errorf("%d bytes is not a square tile", len(data))
+		} else {
+			t.size, t.data = size, data
+		}
+	}
+	if t.err != nil {
+		log.Printf("Error reading elevation tile %s: %v", name, t.err)
+	}
+	if len(e.order) >= e.maxTiles {
+		delete(e.tiles, e.order[0])
+		e.order = e.order[1:]
+	}
+	e.tiles[name] = t
+	e.order = append(e.order, name)
+	return t
+}
+
+// at returns the terrain elevation in metres at a position, interpolated
+// between the four samples around it, and whether it is known.
+func (e *elevationData) at(lat, lon float64) (float64, bool) {
+	t := e.tile(srtmTileName(lat, lon))
+	if t.err != nil {
+		return 0, false
+	}
+	if t.size == 0 {
+		return 0, true
+	}
+	last := float64(t.size - 1)
+	y := (math.Floor(lat) + 1 - lat) * last
+	x := (lon - math.Floor(lon)) * last
+	row, col := min(int(y), t.size-2), min(int(x), t.size-2)
+	sample := func(r, c int) float64 {
+		i := (r*t.size + c) * 2
+		return float64(int16(uint16(t.data[i])<<8 | uint16(t.data[i+1])))
+	}
+	var v [4]float64
+	for i, rc := range [4][2]int{{row, col}, {row, col + 1}, {row + 1, col}, {row + 1, col + 1}} {
+		if v[i] = sample(rc[0], rc[1]); v[i] == srtmVoid {
+			return 0, false
+		}
+	}
+	fy, fx := y-float64(row), x-float64(col)
+	top := v[0] + (v[1]-v[0])*fx
+	bottom := v[2] + (v[3]-v[2])*fx
+	return top + (bottom-top)*fy, true
+}
+
+// aboveGround returns the aircraft with its height above the terrain filled
+// in where the elevation data knows the terrain. Barometric altitude is not
+// corrected for the local pressure, so the height is off by up to a few
+// hundred feet in unusual weather.
+func aboveGround(aircraft Aircraft) Aircraft {
+	aircraft.AltitudeAGL = nil
+	if elevation == nil {
+		return aircraft
+	}
+	if metres, ok := elevation.at(aircraft.Latitude, aircraft.Longitude); ok {
+		agl := aircraft.Altitude - int(math.Round(metres*3.28084))
+		aircraft.AltitudeAGL = &agl
+	}
+	return aircraft
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// envPrefix starts the name of every environment variable the server reads.
+const envPrefix = "AIRCRAFT_ALERT_"
+
+type envSetting struct {
+	name  string // Without envPrefix
+	value any    // Pointer into the Config
+}
+
+// envSettings lists the settings environment variables can override, so
+// container deployments can change them without mounting a config file.
+// Variables win over the file; relative paths in them are resolved against
+// the working directory.
+func envSettings(cfg *Config) []envSetting {
+	return []envSetting{
+		{"LISTEN", &cfg.Listen},
+		{"GRPC_LISTEN", &cfg.GRPCListen},
+		{"ADMIN_LISTEN", &cfg.AdminListen},
+		{"TLS_CERT_FILE", &cfg.TLS.CertFile},
+		{"TLS_KEY_FILE", &cfg.TLS.KeyFile},
+		{"TLS_HTTP_LISTEN", &cfg.TLS.HTTPListen},
+		{"STATIC_DIR", &cfg.StaticDir},
+		{"SWAGGER_UI", &cfg.SwaggerUI},
+		{"CRITERIA_FILE", &cfg.CriteriaFile},
+		{"ZONES_FILE", &cfg.ZonesFile},
+		{"AIRPORTS_FILE", &cfg.AirportsFile},
+		{"EVALUATION_MODE", &cfg.EvaluationMode},
+		{"STORAGE_BACKEND", &cfg.Storage.Backend},
+		{"DB_PATH", &cfg.Storage.BoltPath},
+		{"POSTGRES_DSN", &cfg.Storage.PostgresDSN},
+		{"POSTGIS", &cfg.Storage.PostGIS},
+		{"INFLUXDB_URL", &cfg.Influx.URL},
+		{"INFLUXDB_TOKEN", &cfg.Influx.Token},
+		{"JWT_SECRET", &cfg.Auth.JWTSecret},
+		{"TRUST_PROXY", &cfg.RateLimit.TrustProxy},
+		{"STATE_EXPIRE_AFTER", &cfg.State.ExpireAfter},
+		{"STATE_DEAD_RECKONING", &cfg.State.DeadReckoning},
+		{"PIPELINE_WORKERS", &cfg.Pipeline.Workers},
+		{"PIPELINE_QUEUE", &cfg.Pipeline.Queue},
+		{"AUDIT_FILE", &cfg.Audit.File},
+		{"TRACING_ENDPOINT", &cfg.Tracing.Endpoint},
+		{"CLUSTER_REDIS", &cfg.Cluster.Redis},
+	}
+}
+
+// applyEnv overrides cfg with the AIRCRAFT_ALERT_* variables that are set.
+// A variable set to the empty string clears a text setting, e.g.
+// AIRCRAFT_ALERT_GRPC_LISTEN= disables the gRPC API.
+func applyEnv(cfg *Config) error {
+	for _, s := range envSettings(cfg) {
+		name := envPrefix + s.name
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		var err error
+		switch p := s.value.(type) {
+		case *string:
+			*p = v
+		case *bool:
+			*p, err = strconv.ParseBool(v)
+		case *int:
+			*p, err = strconv.Atoi(v)
+		case *time.Duration:
+			*p, err = time.ParseDuration(v)
+		default:
+			panic(fmt.Sprintf("%s: unsupported setting type %T", name, s.value))
+		}
+		if err != nil {
+			return fmt.Errorf("%s: invalid value %q", name, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// escalationPollInterval is how often due escalation steps are checked.
+const escalationPollInterval = 15 * time.Second
+
+// EscalationPolicy notifies further channels while an alert stays
+// unacknowledged, e.g. ntfy first, SMS after 5 minutes and a phone call
+// after 15. The alert's normal notification is the start of the chain; each
+// step fires AfterMinutes after the alert unless it was acknowledged by then.
+// An alert follows the first enabled policy that matches it.
+type EscalationPolicy struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	MinSeverity string           `json:"min_severity,omitempty"` // Default critical
+	Tags        []string         `json:"tags,omitempty"`         // Criterion tags the alert must all carry
+	Steps       []EscalationStep `json:"steps"`
+	Enabled     bool             `json:"enabled"`
+}
+
+// EscalationStep is one link of an escalation chain.
+type EscalationStep struct {
+	AfterMinutes int      `json:"after_minutes"`
+	Channels     []string `json:"channels"`
+}
+
+func (p *EscalationPolicy) validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if p.MinSeverity == "" {
+		p.MinSeverity = severityCritical
+	}
+	if _, ok := severityRank[p.MinSeverity]; !ok {
+		return fmt.Errorf("unknown min_severity %q", p.MinSeverity)
+	}
+	if len(p.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+	mu.RLock()
+	channels := notificationChannelNames(activeConfig.Notifications)
+	mu.RUnlock()
+	prev := 0
+	for i, step := range p.Steps {
+		if step.AfterMinutes <= prev {
+			return fmt.Errorf("step %d: after_minutes must be positive and increasing", i+1)
+		}
+		prev = step.AfterMinutes
+		if len(step.Channels) == 0 {
+			return fmt.Errorf("step %d has no channels", i+1)
+		}
+		for _, ch := range step.Channels {
+			if !slices.Contains(channels, ch) {
+				return fmt.Errorf("step %d: unknown notification channel %q", i+1, ch)
+			}
+		}
+	}
+	return nil
+}
+
+func (p EscalationPolicy) matches(alert Alert) bool {
+	return p.Enabled && severityRank[alert.Severity] >= severityRank[p.MinSeverity] && alert.Criteria.HasTags(p.Tags)
+}
+
+// escalating maps the IDs of alerts with a pending escalation step to when
+// it is due. It is guarded by mu.
+var escalating = map[string]time.Time{}
+
+// startEscalation attaches the first matching policy to a new alert.
+// Callers must hold mu.
+func startEscalation(alert *Alert) {
+	if alert.Escalation != nil {
+		return // An escalation notification itself
+	}
+	policies, err := store.ListEscalationPolicies()
+	if err != nil {
+		log.Printf("Error loading escalation policies: %v", err)
+		return
+	}
+	for _, p := range policies {
+		if p.matches(*alert) {
+			next := alert.Timestamp.Add(time.Duration(p.Steps[0].AfterMinutes) * time.Minute)
+			alert.Escalation = &AlertEscalation{PolicyID: p.ID, NextAt: &next}
+			escalating[alert.ID] = next
+			return
+		}
+	}
+}
+
+// runEscalations resumes the escalations of stored alerts, then fires due
+// steps until the process exits.
+func runEscalations() {
+	mu.Lock()
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		log.Printf("Error loading alerts for escalation: %v", err)
+	}
+	for _, a := range alerts {
+		if !a.Acknowledged && a.Escalation != nil && a.Escalation.NextAt != nil {
+			escalating[a.ID] = *a.Escalation.NextAt
+		}
+	}
+	mu.Unlock()
+
+	ticker := time.NewTicker(escalationPollInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		mu.Lock()
+		for id, due := range escalating {
+			if !due.After(now) {
+				escalate(id)
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+// escalate notifies the channels of the alert's next step and schedules the
+// one after. Callers must hold mu.
+func escalate(id string) {
+	delete(escalating, id)
+	alert, err := store.GetAlert(id)
+	if err != nil {
+		if !errors.Is(err, errNotFound) {
+			log.Printf("Error loading alert %s for escalation: %v", id, err)
+		}
+		return
+	}
+	esc := alert.Escalation
+	if alert.Acknowledged || esc == nil || esc.NextAt == nil {
+		return
+	}
+	policies, err := store.ListEscalationPolicies()
+	if err != nil {
+		log.Printf("Error loading escalation policies: %v", err)
+		escalating[id] = time.Now().Add(escalationPollInterval)
+		return
+	}
+	i := slices.IndexFunc(policies, func(p EscalationPolicy) bool { return p.ID == esc.PolicyID })
+	esc.NextAt = nil
+	if i >= 0 && policies[i].Enabled && esc.Step < len(policies[i].Steps) {
+		policy := policies[i]
+		step := policy.Steps[esc.Step]
+		esc.Step++
+		notifyChannels(escalationAlert(alert, policy, esc.Step), step.Channels)
+		log.Printf("Alert %s not acknowledged after %d minutes, escalated to %v (policy %s)", id, step.AfterMinutes, step.Channels, policy.Name)
+		if esc.Step < len(policy.Steps) {
+			next := alert.Timestamp.Add(time.Duration(policy.Steps[esc.Step].AfterMinutes) * time.Minute)
+			esc.NextAt = &next
+			escalating[id] = next
+		}
+	}
+	if err := store.UpdateAlert(alert); err != nil {
+		log.Printf("Error saving escalation of alert %s: %v", id, err)
+	}
+}
+
+// escalationAlert is the notification sent for step of the alert's policy.
+// It gets an ID of its own, as some channels drop repeated IDs.
+func escalationAlert(alert Alert, policy EscalationPolicy, step int) Alert {
+	after := policy.Steps[step-1].AfterMinutes
+	alert.Escalation = &AlertEscalation{PolicyID: policy.ID, Step: step, AlertID: alert.ID}
+	alert.ID = newID()
+	alert.Message = fmt.Sprintf("Not acknowledged after %d min: %s", after, alert.Message)
+	return alert
+}
+
+func handleListEscalationPolicies(c *jacked.Context) error {
+	policies, err := store.ListEscalationPolicies()
+	if err != nil {
+		return storeError(c, err)
+	}
+	if policies == nil {
+		policies = []EscalationPolicy{}
+	}
+	return c.JSON(http.StatusOK, policies)
+}
+
+func handleCreateEscalationPolicy(c *jacked.Context) error {
+	policy := EscalationPolicy{Enabled: true}
+	body, err := readBody(c, maxCriterionBody)
+	if err == nil {
+		err = json.Unmarshal(body, &policy)
+	}
+	if err != nil {
+		return bodyError(c, err, "Invalid escalation policy")
+	}
+	if err := policy.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	policy.ID = newID()
+	if err := store.SaveEscalationPolicy(policy); err != nil {
+		return storeError(c, err)
+	}
+	logRequestf(c.Request, "Added escalation policy %s (%s)", policy.ID, policy.Name)
+	return c.JSON(http.StatusCreated, policy)
+}
+
+// handleUpdateEscalationPolicy replaces a policy. Alerts already escalating
+// continue with the new steps.
+func handleUpdateEscalationPolicy(c *jacked.Context) error {
+	policy := EscalationPolicy{Enabled: true}
+	body, err := readBody(c, maxCriterionBody)
+	if err == nil {
+		err = json.Unmarshal(body, &policy)
+	}
+	if err != nil {
+		return bodyError(c, err, "Invalid escalation policy")
+	}
+	if err := policy.validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	policy.ID = c.Param("id")
+	policies, err := store.ListEscalationPolicies()
+	if err != nil {
+		return storeError(c, err)
+	}
+	if !slices.ContainsFunc(policies, func(p EscalationPolicy) bool { return p.ID == policy.ID }) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Escalation policy not found"})
+	}
+	if err := store.SaveEscalationPolicy(policy); err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+// handleDeleteEscalationPolicy removes a policy; alerts following it stop
+// escalating.
+func handleDeleteEscalationPolicy(c *jacked.Context) error {
+	err := store.DeleteEscalationPolicy(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Escalation policy not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// writeTagged replies with v as JSON and an ETag hashed from the body, or
+// with 304 Not Modified when If-None-Match already holds that tag. Clients
+// still revalidate on every request, so polling stays current but cheap.
+func writeTagged(c *jacked.Context, contentType string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	header := c.Response.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "no-cache")
+	if etagMatches(c.Request.Header.Get("If-None-Match"), etag) {
+		c.Response.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	header.Set("Content-Type", contentType)
+	c.Response.WriteHeader(http.StatusOK)
+	_, err = c.Response.Write(append(data, '\n'))
+	return err
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 asks.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package main
+
+import "context"
+
+// Evaluator is one stage of the alert engine. Evaluate sees every accepted
+// update along with the aircraft's track as it was before the update, and
+// returns the alerts the update raises; the engine records, broadcasts and
+// notifies them. Evaluators run in turn under mu, so they may keep state
+// without locking of their own.
+type Evaluator interface {
+	Evaluate(aircraft Aircraft, previous []Aircraft) []Alert
+}
+
+// evaluators is the alert engine: the plausibility checker, then the
+// user-defined criteria. Geofencing, anomaly or expression rules that do
+// not fit a criterion can be added as further stages.
+var evaluators = []Evaluator{anomalyEvaluator{}, criteriaEvaluator{}}
+
+// anomalyEvaluator flags spoofed or corrupt ADS-B data.
+type anomalyEvaluator struct{}
+
+func (anomalyEvaluator) Evaluate(aircraft Aircraft, previous []Aircraft) []Alert {
+	return checkAnomalies(aircraft, previous)
+}
+
+// criteriaEvaluator runs the enabled alert criteria. Its detectors read the
+// track including the update, so it ignores previous.
+type criteriaEvaluator struct{}
+
+func (criteriaEvaluator) Evaluate(aircraft Aircraft, _ []Aircraft) []Alert {
+	return evaluateCriteria(aircraft)
+}
+
+// evaluateUpdate records the update's track point and runs it through the
+// evaluators, recording the alerts they raise as part of the trace in ctx.
+// Callers must hold mu.
+func evaluateUpdate(ctx context.Context, aircraft Aircraft) {
+	previous := tracks[aircraft.ICAO]
+	recordTrackPoint(aircraft)
+	for _, evaluator := range evaluators {
+		for _, alert := range evaluator.Evaluate(aircraft, previous) {
+			recordAlert(ctx, alert)
+		}
+	}
+}
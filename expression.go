@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// Limits applied to user-supplied rule expressions.
+const (
+	maxExpressionLength = 1024
+	expressionCostLimit = 10000
+)
+
+var (
+	celEnv     *cel.Env
+	celEnvErr  error
+	celEnvOnce sync.Once
+
+	exprMu    sync.Mutex
+	exprCache = map[string]cel.Program{}
+)
+
+// expressionEnv returns the CEL environment rule expressions are compiled in.
+// Variables use the same names as the Aircraft JSON fields.
+func expressionEnv() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		celEnv, celEnvErr = cel.NewEnv(
+			cel.CrossTypeNumericComparisons(true),
+			cel.Variable("icao", cel.StringType),
+			cel.Variable("callsign", cel.StringType),
+			cel.Variable("lat", cel.DoubleType),
+			cel.Variable("lon", cel.DoubleType),
+			cel.Variable("alt_baro", cel.IntType),
+			cel.Variable("gs", cel.DoubleType),
+			cel.Variable("track", cel.DoubleType),
+			cel.Variable("r_dst", cel.DoubleType),
+			cel.Variable("r_dir", cel.DoubleType),
+			cel.Function("distance",
+				cel.Overload("distance_double_double_double_double",
+					[]*cel.Type{cel.DoubleType, cel.DoubleType, cel.DoubleType, cel.DoubleType},
+					cel.DoubleType,
+					cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+						return types.Double(distanceNM(
+							float64(args[0].(types.Double)), float64(args[1].(types.Double)),
+							float64(args[2].(types.Double)), float64(args[3].(types.Double)),
+						))
+					}),
+				),
+			),
+		)
+	})
+	return celEnv, celEnvErr
+}
+
+// compileExpression validates expr and returns a program for it, reusing a
+// cached program when the same expression has been compiled before.
+func compileExpression(expr string) (cel.Program, error) {
+	if len(expr) > maxExpressionLength {
+		return nil, fmt.Errorf("expression longer than %d characters", maxExpressionLength)
+	}
+
+	exprMu.Lock()
+	defer exprMu.Unlock()
+	if prg, ok := exprCache[expr]; ok {
+		return prg, nil
+	}
+
+	env, err := expressionEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := env.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, errors.New("expression must evaluate to a boolean")
+	}
+	prg, err := env.Program(ast, cel.CostLimit(expressionCostLimit))
+	if err != nil {
+		return nil, err
+	}
+	exprCache[expr] = prg
+	return prg, nil
+}
+
+// evalExpression reports whether the aircraft satisfies expr. Evaluation
+// errors, including exceeding the cost limit, count as no match.
+func evalExpression(expr string, aircraft Aircraft) (bool, error) {
+	prg, err := compileExpression(expr)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := prg.Eval(map[string]interface{}{
+		"icao":     aircraft.ICAO,
+		"callsign": aircraft.Callsign,
+		"lat":      aircraft.Latitude,
+		"lon":      aircraft.Longitude,
+		"alt_baro": int64(aircraft.Altitude),
+		"gs":       aircraft.Speed,
+		"track":    aircraft.Track,
+		"r_dst":    aircraft.ReceiverDistance,
+		"r_dir":    aircraft.ReceiverBearing,
+	})
+	if err != nil {
+		return false, err
+	}
+	match, _ := out.Value().(bool)
+	return match, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// criterionSuggestion proposes a tightening of a criterion, expressed as a
+// field of AlertCriteria and the value it should be set to.
+type criterionSuggestion struct {
+	Field  string      `json:"field"`
+	Value  interface{} `json:"value"`
+	Reason string      `json:"reason"`
+}
+
+// criterionFeedback summarises operator feedback for a single criterion.
+type criterionFeedback struct {
+	CriterionID       string                `json:"criterion_id"`
+	Alerts            int                   `json:"alerts"`
+	FalsePositives    int                   `json:"false_positives"`
+	FalsePositiveRate float64               `json:"false_positive_rate"`
+	Suggestions       []criterionSuggestion `json:"suggestions"`
+}
+
+func handleMarkFalsePositive(c *jacked.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+	alert, err := store.GetAlert(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Alert not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	if !alert.FalsePositive {
+		alert.FalsePositive = true
+		if err := store.UpdateAlert(alert); err != nil {
+			return storeError(c, err)
+		}
+		if criterion, err := store.GetCriterion(alert.Criteria.ID); err == nil {
+			criterion.FalsePositives++
+			if err := store.SaveCriterion(criterion); err != nil {
+				return storeError(c, err)
+			}
+		}
+		logRequestf(c.Request, "Alert %s marked as false positive (criterion %s)", alert.ID, alert.Criteria.ID)
+	}
+	return c.JSON(http.StatusOK, alert)
+}
+
+// handleAckAlert acknowledges an alert, stopping its escalation.
+func handleAckAlert(c *jacked.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+	alert, err := store.GetAlert(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Alert not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	if !alert.Acknowledged {
+		now := time.Now()
+		alert.Acknowledged = true
+		alert.AcknowledgedAt = &now
+		if alert.Escalation != nil {
+			alert.Escalation.NextAt = nil
+		}
+		if err := store.UpdateAlert(alert); err != nil {
+			return storeError(c, err)
+		}
+		delete(escalating, alert.ID)
+		logRequestf(c.Request, "Alert %s acknowledged", alert.ID)
+	}
+	return c.JSON(http.StatusOK, alert)
+}
+
+// handleDeleteAlert removes a single alert.
+func handleDeleteAlert(c *jacked.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+	err := store.DeleteAlert(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Alert not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	delete(escalating, c.Param("id"))
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// handleClearAlerts deletes every alert matching the GET /api/v1/alerts
+// filters, e.g. ?acknowledged=true. Clearing all alerts takes ?all=true.
+func handleClearAlerts(c *jacked.Context) error {
+	q := c.Request.URL.Query()
+	all, _ := strconv.ParseBool(q.Get("all"))
+	q.Del("all")
+	if len(q) == 0 && !all {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Give filters or all=true"})
+	}
+	filter, err := parseAlertFilter(q)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		return storeError(c, err)
+	}
+	deleted := 0
+	for _, alert := range alerts {
+		if !filter.matches(alert) {
+			continue
+		}
+		if err := store.DeleteAlert(alert.ID); err != nil && !errors.Is(err, errNotFound) {
+			return storeError(c, err)
+		}
+		delete(escalating, alert.ID)
+		deleted++
+	}
+	logRequestf(c.Request, "Cleared %d alerts", deleted)
+	return c.JSON(http.StatusOK, map[string]int{"deleted": deleted})
+}
+
+func handleAlertCriterionFeedback(c *jacked.Context) error {
+	criterion, err := store.GetCriterion(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Criterion not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, buildCriterionFeedback(criterion, alerts))
+}
+
+// buildCriterionFeedback computes the false-positive rate of criterion over
+// alerts and suggests bounds that would have excluded every false positive
+// while keeping every confirmed alert.
+func buildCriterionFeedback(criterion AlertCriteria, alerts []Alert) criterionFeedback {
+	fb := criterionFeedback{CriterionID: criterion.ID, Suggestions: []criterionSuggestion{}}
+	var good, bad []Aircraft
+	for _, alert := range alerts {
+		if alert.Criteria.ID != criterion.ID {
+			continue
+		}
+		fb.Alerts++
+		if alert.FalsePositive {
+			bad = append(bad, alert.Aircraft)
+		} else {
+			good = append(good, alert.Aircraft)
+		}
+	}
+	fb.FalsePositives = len(bad)
+	if fb.Alerts > 0 {
+		fb.FalsePositiveRate = float64(fb.FalsePositives) / float64(fb.Alerts)
+	}
+	if len(bad) == 0 || len(good) == 0 {
+		return fb
+	}
+
+	goodAltMin, goodAltMax := good[0].Altitude, good[0].Altitude
+	goodSpdMin, goodSpdMax := good[0].Speed, good[0].Speed
+	for _, a := range good[1:] {
+		goodAltMin = min(goodAltMin, a.Altitude)
+		goodAltMax = max(goodAltMax, a.Altitude)
+		goodSpdMin = min(goodSpdMin, a.Speed)
+		goodSpdMax = max(goodSpdMax, a.Speed)
+	}
+
+	allFalsePositives := func(pred func(Aircraft) bool) bool {
+		for _, a := range bad {
+			if !pred(a) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case allFalsePositives(func(a Aircraft) bool { return a.Altitude < goodAltMin }):
+		fb.Suggestions = append(fb.Suggestions, criterionSuggestion{
+			Field: "min_altitude", Value: goodAltMin,
+			Reason: "all false positives were below the lowest confirmed alert",
+		})
+	case allFalsePositives(func(a Aircraft) bool { return a.Altitude > goodAltMax }):
+		fb.Suggestions = append(fb.Suggestions, criterionSuggestion{
+			Field: "max_altitude", Value: goodAltMax,
+			Reason: "all false positives were above the highest confirmed alert",
+		})
+	}
+	switch {
+	case allFalsePositives(func(a Aircraft) bool { return a.Speed < goodSpdMin }):
+		fb.Suggestions = append(fb.Suggestions, criterionSuggestion{
+			Field: "min_speed", Value: goodSpdMin,
+			Reason: "all false positives were slower than the slowest confirmed alert",
+		})
+	case allFalsePositives(func(a Aircraft) bool { return a.Speed > goodSpdMax }):
+		fb.Suggestions = append(fb.Suggestions, criterionSuggestion{
+			Field: "max_speed", Value: goodSpdMax,
+			Reason: "all false positives were faster than the fastest confirmed alert",
+		})
+	}
+	return fb
+}
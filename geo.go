@@ -0,0 +1,64 @@
+package main
+
+import "math"
+
+const (
+	earthRadiusNM = 3440.065
+	metersPerNM   = 1852
+)
+
+// distanceNM returns the great-circle distance between two points in nautical miles.
+func distanceNM(lat1, lon1, lat2, lon2 float64) float64 {
+	φ1 := lat1 * math.Pi / 180
+	φ2 := lat2 * math.Pi / 180
+	dφ := (lat2 - lat1) * math.Pi / 180
+	dλ := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dφ/2)*math.Sin(dφ/2) + math.Cos(φ1)*math.Cos(φ2)*math.Sin(dλ/2)*math.Sin(dλ/2)
+	return 2 * earthRadiusNM * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// pointInPolygon reports whether the point lies inside the polygon of
+// [lat, lon] vertices using ray casting. Adequate for zones that do not
+// cross the antimeridian.
+func pointInPolygon(lat, lon float64, polygon [][2]float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		yi, xi := polygon[i][0], polygon[i][1]
+		yj, xj := polygon[j][0], polygon[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// bearingDeg returns the initial great-circle bearing from the first point to
+// the second in degrees clockwise from true north.
+func bearingDeg(lat1, lon1, lat2, lon2 float64) float64 {
+	φ1 := lat1 * math.Pi / 180
+	φ2 := lat2 * math.Pi / 180
+	dλ := (lon2 - lon1) * math.Pi / 180
+	y := math.Sin(dλ) * math.Cos(φ2)
+	x := math.Cos(φ1)*math.Sin(φ2) - math.Sin(φ1)*math.Cos(φ2)*math.Cos(dλ)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+var compassPoints = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// compassPoint converts a bearing in degrees to a 16-point compass direction.
+func compassPoint(deg float64) string {
+	return compassPoints[int(math.Mod(deg+11.25, 360)/22.5)%16]
+}
+
+// destinationPoint returns the point dist nautical miles from the start
+// along the initial bearing (degrees clockwise from true north).
+func destinationPoint(lat, lon, bearing, dist float64) (float64, float64) {
+	φ1 := lat * math.Pi / 180
+	λ1 := lon * math.Pi / 180
+	θ := bearing * math.Pi / 180
+	δ := dist / earthRadiusNM
+	φ2 := math.Asin(math.Sin(φ1)*math.Cos(δ) + math.Cos(φ1)*math.Sin(δ)*math.Cos(θ))
+	λ2 := λ1 + math.Atan2(math.Sin(θ)*math.Sin(δ)*math.Cos(φ1), math.Cos(δ)-math.Sin(φ1)*math.Sin(φ2))
+	return φ2 * 180 / math.Pi, math.Mod(λ2*180/math.Pi+540, 360) - 180
+}
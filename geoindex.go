@@ -0,0 +1,228 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+// geoCellDegrees is the size of the cells the spatial indexes divide the
+// map into, about 15 nm north to south: small enough that a proximity check
+// looks at a handful of cells, large enough that an aircraft changes cell
+// only every few minutes.
+const geoCellDegrees = 0.25
+
+// maxZoneCells is the most cells a zone is filed under; larger zones, such
+// as whole FIRs, are checked for every position instead.
+const maxZoneCells = 1 << 16
+
+// maxBoxCells caps the cells an area query visits one by one; larger boxes
+// are answered by scanning the occupied cells instead.
+const maxBoxCells = 1 << 16
+
+// geoCell identifies one cell of the grid.
+type geoCell struct{ lat, lon int32 }
+
+func cellOf(lat, lon float64) geoCell {
+	return geoCell{int32(math.Floor(lat / geoCellDegrees)), int32(math.Floor(lon / geoCellDegrees))}
+}
+
+// geoBox is a [min_lat, min_lon, max_lat, max_lon] box.
+type geoBox [4]float64
+
+// cells returns the range of cells covering the box and their number,
+// which is 0 for an inverted box and saturates just above maxBoxCells.
+func (b geoBox) cells() (lo, hi geoCell, n int) {
+	lo, hi = cellOf(b[0], b[1]), cellOf(b[2], b[3])
+	lats := int64(hi.lat) - int64(lo.lat) + 1
+	lons := int64(hi.lon) - int64(lo.lon) + 1
+	if lats <= 0 || lons <= 0 {
+		return lo, hi, 0
+	}
+	return lo, hi, int(min(min(lats, maxBoxCells+1)*min(lons, maxBoxCells+1), maxBoxCells+1))
+}
+
+func (b geoBox) contains(lat, lon float64) bool {
+	return lat >= b[0] && lat <= b[2] && lon >= b[1] && lon <= b[3]
+}
+
+// boxesAround returns the boxes covering everything within nm nautical
+// miles of a point: one, or two where the area crosses the antimeridian.
+// Near the poles they span every longitude.
+func boxesAround(lat, lon, nm float64) []geoBox {
+	dLat := nm / 60
+	minLat, maxLat := math.Max(lat-dLat, -90), math.Min(lat+dLat, 90)
+	if minLat == -90 || maxLat == 90 {
+		return []geoBox{{minLat, -180, maxLat, 180}}
+	}
+	dLon := nm / (60 * math.Cos(math.Max(math.Abs(minLat), math.Abs(maxLat))*math.Pi/180))
+	switch {
+	case dLon >= 180:
+		return []geoBox{{minLat, -180, maxLat, 180}}
+	case lon-dLon < -180:
+		return []geoBox{{minLat, -180, maxLat, lon + dLon}, {minLat, lon - dLon + 360, maxLat, 180}}
+	case lon+dLon > 180:
+		return []geoBox{{minLat, lon - dLon, maxLat, 180}, {minLat, -180, maxLat, lon + dLon - 360}}
+	}
+	return []geoBox{{minLat, lon - dLon, maxLat, lon + dLon}}
+}
+
+// aircraftGrid files the latest position of each aircraft by cell, so area
+// queries and proximity checks visit only nearby aircraft.
+type aircraftGrid struct {
+	cells map[geoCell]map[string]Aircraft
+	at    map[string]geoCell
+}
+
+func newAircraftGrid() aircraftGrid {
+	return aircraftGrid{cells: map[geoCell]map[string]Aircraft{}, at: map[string]geoCell{}}
+}
+
+// file files the aircraft under the cell of its position, replacing its
+// earlier one.
+func (g *aircraftGrid) file(aircraft Aircraft) {
+	cell := cellOf(aircraft.Latitude, aircraft.Longitude)
+	if old, ok := g.at[aircraft.ICAO]; ok && old != cell {
+		g.unfile(old, aircraft.ICAO)
+	}
+	if g.cells[cell] == nil {
+		g.cells[cell] = map[string]Aircraft{}
+	}
+	g.cells[cell][aircraft.ICAO] = aircraft
+	g.at[aircraft.ICAO] = cell
+}
+
+// remove drops icao from the grid.
+func (g *aircraftGrid) remove(icao string) {
+	if cell, ok := g.at[icao]; ok {
+		g.unfile(cell, icao)
+		delete(g.at, icao)
+	}
+}
+
+func (g *aircraftGrid) unfile(cell geoCell, icao string) {
+	delete(g.cells[cell], icao)
+	if len(g.cells[cell]) == 0 {
+		delete(g.cells, cell)
+	}
+}
+
+// in returns every aircraft filed inside the boxes, in no particular order.
+func (g *aircraftGrid) in(boxes ...geoBox) []Aircraft {
+	var list []Aircraft
+	add := func(cell geoCell, b geoBox) {
+		for _, aircraft := range g.cells[cell] {
+			if b.contains(aircraft.Latitude, aircraft.Longitude) {
+				list = append(list, aircraft)
+			}
+		}
+	}
+	for _, b := range boxes {
+		lo, hi, n := b.cells()
+		// A box spanning more cells than are occupied is quicker to answer
+		// from the occupied ones, and a huge one must be.
+		if n > len(g.cells) || n > maxBoxCells {
+			for cell := range g.cells {
+				if cell.lat >= lo.lat && cell.lat <= hi.lat && cell.lon >= lo.lon && cell.lon <= hi.lon {
+					add(cell, b)
+				}
+			}
+			continue
+		}
+		for lat := lo.lat; lat <= hi.lat; lat++ {
+			for lon := lo.lon; lon <= hi.lon; lon++ {
+				add(geoCell{lat, lon}, b)
+			}
+		}
+	}
+	return list
+}
+
+// aircraftIndex files the latest position of every aircraft in the state
+// registry. It has its own lock; the registry updates it under its shard
+// locks, so it never takes those.
+var aircraftIndex = struct {
+	sync.RWMutex
+	grid aircraftGrid
+}{grid: newAircraftGrid()}
+
+// indexAircraft files the aircraft under the cell of its position.
+func indexAircraft(aircraft Aircraft) {
+	aircraftIndex.Lock()
+	defer aircraftIndex.Unlock()
+	aircraftIndex.grid.file(aircraft)
+}
+
+// unindexAircraft drops icao from the index.
+func unindexAircraft(icao string) {
+	aircraftIndex.Lock()
+	defer aircraftIndex.Unlock()
+	aircraftIndex.grid.remove(icao)
+}
+
+// aircraftIn returns the latest report of every registered aircraft inside
+// the boxes, in no particular order.
+func aircraftIn(boxes ...geoBox) []Aircraft {
+	aircraftIndex.RLock()
+	defer aircraftIndex.RUnlock()
+	return aircraftIndex.grid.in(boxes...)
+}
+
+var (
+	// zoneCells lists the zones whose bounding box touches each cell, and
+	// largeZones those filed under no cell. Rebuilt by indexZones; guarded
+	// by mu.
+	zoneCells  = map[geoCell][]string{}
+	largeZones []string
+)
+
+// bounds returns the zone's bounding box.
+func (z Zone) bounds() geoBox {
+	if z.Center != nil && z.Radius > 0 {
+		boxes := boxesAround(z.Center[0], z.Center[1], z.Radius)
+		if len(boxes) > 1 {
+			return geoBox{boxes[0][0], -180, boxes[0][2], 180}
+		}
+		return boxes[0]
+	}
+	b := geoBox{90, 180, -90, -180}
+	for _, p := range z.Polygon {
+		b[0], b[1] = math.Min(b[0], p[0]), math.Min(b[1], p[1])
+		b[2], b[3] = math.Max(b[2], p[0]), math.Max(b[3], p[1])
+	}
+	return b
+}
+
+// indexZones files every zone under the cells its bounding box touches. It
+// must run whenever zones changes. Callers must hold mu.
+func indexZones() {
+	zoneCells = map[geoCell][]string{}
+	largeZones = nil
+	for name, z := range zones {
+		lo, hi, n := z.bounds().cells()
+		if n > maxZoneCells || n <= 0 {
+			largeZones = append(largeZones, name)
+			continue
+		}
+		for lat := lo.lat; lat <= hi.lat; lat++ {
+			for lon := lo.lon; lon <= hi.lon; lon++ {
+				cell := geoCell{lat, lon}
+				zoneCells[cell] = append(zoneCells[cell], name)
+			}
+		}
+	}
+}
+
+// zonesContaining returns the names of the zones the aircraft is inside,
+// within their vertical limits, in no particular order. Callers must hold
+// mu.
+func zonesContaining(aircraft Aircraft) []string {
+	var names []string
+	for _, list := range [][]string{zoneCells[cellOf(aircraft.Latitude, aircraft.Longitude)], largeZones} {
+		for _, name := range list {
+			if zones[name].containsAircraft(aircraft) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// GeoJSON (RFC 7946) output for ?format=geojson on the aircraft, track and
+// alert listings, for map libraries and GIS tools. Coordinates are
+// [lon, lat]; altitudes stay in feet in the properties.
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"` // FeatureCollection
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"` // Feature
+	ID         string                 `json:"id,omitempty"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"` // Point or LineString
+	Coordinates interface{} `json:"coordinates"`
+}
+
+func geoJSONPoint(lat, lon float64) geoJSONGeometry {
+	return geoJSONGeometry{Type: "Point", Coordinates: [2]float64{lon, lat}}
+}
+
+const geoJSONType = "application/geo+json"
+
+// wantsGeoJSON reports whether the request asked for ?format=geojson.
+func wantsGeoJSON(c *jacked.Context) bool {
+	return c.Request.URL.Query().Get("format") == "geojson"
+}
+
+func featureCollection(features []geoJSONFeature) geoJSONCollection {
+	if features == nil {
+		features = []geoJSONFeature{}
+	}
+	return geoJSONCollection{Type: "FeatureCollection", Features: features}
+}
+
+func writeGeoJSON(c *jacked.Context, features []geoJSONFeature) error {
+	c.Response.Header().Set("Content-Type", geoJSONType)
+	c.Response.WriteHeader(http.StatusOK)
+	return json.NewEncoder(c.Response).Encode(featureCollection(features))
+}
+
+func aircraftProperties(a Aircraft) map[string]interface{} {
+	return map[string]interface{}{
+		"icao":      a.ICAO,
+		"callsign":  a.Callsign,
+		"alt_baro":  a.Altitude,
+		"gs":        a.Speed,
+		"track":     a.Track,
+		"squawk":    a.Squawk,
+		"timestamp": a.Timestamp,
+	}
+}
+
+// aircraftGeoJSON has a point per aircraft at its latest position.
+func aircraftGeoJSON(states []AircraftState) []geoJSONFeature {
+	features := make([]geoJSONFeature, 0, len(states))
+	for _, s := range states {
+		props := aircraftProperties(s.Aircraft)
+		props["last_seen"] = s.LastSeen
+		props["seen"] = s.Seen
+		features = append(features, geoJSONFeature{Type: "Feature", ID: s.ICAO, Geometry: geoJSONPoint(s.Latitude, s.Longitude), Properties: props})
+	}
+	return features
+}
+
+// trackGeoJSON draws the points as one LineString, with the time and
+// altitude of every vertex in the times and altitudes properties.
+func trackGeoJSON(icao string, points []Aircraft) []geoJSONFeature {
+	if len(points) == 0 {
+		return nil
+	}
+	coords := make([][2]float64, len(points))
+	times := make([]time.Time, len(points))
+	altitudes := make([]int, len(points))
+	for i, p := range points {
+		coords[i] = [2]float64{p.Longitude, p.Latitude}
+		times[i] = p.Timestamp
+		altitudes[i] = p.Altitude
+	}
+	geometry := geoJSONGeometry{Type: "LineString", Coordinates: coords}
+	if len(points) == 1 {
+		geometry = geoJSONPoint(points[0].Latitude, points[0].Longitude)
+	}
+	return []geoJSONFeature{{
+		Type:     "Feature",
+		ID:       icao,
+		Geometry: geometry,
+		Properties: map[string]interface{}{
+			"icao":      icao,
+			"callsign":  points[len(points)-1].Callsign,
+			"start":     points[0].Timestamp,
+			"end":       points[len(points)-1].Timestamp,
+			"times":     times,
+			"altitudes": altitudes,
+		},
+	}}
+}
+
+// alertsGeoJSON has a point per alert where the aircraft was when it fired.
+func alertsGeoJSON(alerts []Alert) []geoJSONFeature {
+	features := make([]geoJSONFeature, 0, len(alerts))
+	for _, a := range alerts {
+		props := aircraftProperties(a.Aircraft)
+		props["alert_id"] = a.ID
+		props["category"] = a.Category
+		props["event"] = a.Event
+		props["severity"] = a.Severity
+		props["message"] = a.Message
+		props["criterion"] = a.Criteria.ID
+		props["acknowledged"] = a.Acknowledged
+		props["timestamp"] = a.Timestamp
+		features = append(features, geoJSONFeature{Type: "Feature", ID: a.ID, Geometry: geoJSONPoint(a.Aircraft.Latitude, a.Aircraft.Longitude), Properties: props})
+	}
+	return features
+}
@@ -0,0 +1,772 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"github.com/gorilla/websocket"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	maxGraphQLBody  = 1 << 20
+	maxGraphQLDepth = 10
+	// maxGraphQLNodes caps the objects one query, or one subscription
+	// event, may return, however its fields are nested or aliased.
+	maxGraphQLNodes = 10000
+)
+
+var errGraphQLTooComplex = fmt.Errorf("query returns more than %d objects; lower the limits or select fewer fields", maxGraphQLNodes)
+
+// graphQLSchemaSource describes the data behind the REST API as one graph,
+// so dashboards can fetch nested aircraft, tracks, alerts and criteria in a
+// single request. It is read-only; changes go through the REST API.
+const graphQLSchemaSource = `
+schema {
+	query: Query
+	subscription: Subscription
+}
+
+"RFC 3339 string or Unix seconds"
+scalar Time
+
+type Query {
+	"Tracked aircraft ordered by ICAO, leaving out those not heard from within maxAge (e.g. 60s)"
+	aircraft(maxAge: String): [Aircraft!]!
+	"A tracked aircraft, or null when it is not being tracked"
+	aircraftByIcao(icao: String!): Aircraft
+	"Matching alerts, newest first, like GET /api/v1/alerts"
+	alerts(since: Time, until: Time, icao: String, callsign: String, severity: [String!], criterion: ID, zone: String, tag: [String!], acknowledged: Boolean, limit: Int = 100, offset: Int = 0): [Alert!]!
+	alert(id: ID!): Alert
+	"Alert criteria in creation order, only those carrying every tag given"
+	criteria(tag: [String!]): [Criterion!]!
+	criterion(id: ID!): Criterion
+}
+
+type Subscription {
+	"Every position update, optionally only of some aircraft or inside [minLat, minLon, maxLat, maxLon]"
+	aircraftUpdated(icao: [String!], bbox: [Float!]): Aircraft!
+	"Every alert as it fires, optionally only from minSeverity up or of some aircraft"
+	alertRaised(minSeverity: String, icao: [String!]): Alert!
+}
+
+type Aircraft {
+	icao: String!
+	callsign: String!
+	lat: Float!
+	lon: Float!
+	altBaro: Int!
+	groundSpeed: Float!
+	track: Float!
+	squawk: String!
+	timestamp: Time!
+	"Nautical miles from the receiver; null when its location is unknown"
+	receiverDistance: Float
+	"Degrees clockwise from true north, seen from the receiver"
+	receiverBearing: Float
+	"Feet above the terrain; null without elevation data for the position"
+	altAgl: Int
+	"Ident of the nearest airport within 100 nm"
+	airport: String
+	"Nautical miles to the nearest airport"
+	airportDistance: Float
+	"Whether the position was projected from the last report rather than received"
+	estimated: Boolean!
+	"Whether the aircraft is currently tracked"
+	live: Boolean!
+	"From the registration database; null when it has no entry"
+	registration: Registration
+	"From the routes API; null when it has no route for the callsign"
+	route: Route
+	"Positions oldest first: the recent track, or with since the position history"
+	positions(since: Time): [Position!]!
+	"Alerts for this aircraft, newest first"
+	alerts(limit: Int = 20): [Alert!]!
+}
+
+type Registration {
+	registration: String
+	typeCode: String
+	type: String
+	operator: String
+	owner: String
+}
+
+type Route {
+	"ICAO codes, e.g. EGLL"
+	origin: String!
+	destination: String!
+	originIata: String
+	destinationIata: String
+}
+
+type Position {
+	lat: Float!
+	lon: Float!
+	altBaro: Int!
+	groundSpeed: Float!
+	track: Float!
+	timestamp: Time!
+}
+
+type Alert {
+	id: ID!
+	category: String!
+	severity: String!
+	event: String!
+	message: String!
+	timestamp: Time!
+	acknowledged: Boolean!
+	acknowledgedAt: Time
+	falsePositive: Boolean!
+	"The aircraft as it was when the alert fired"
+	aircraft: Aircraft!
+	"The criterion as it was when the alert fired; null for anomalies"
+	criterion: Criterion
+}
+
+type Criterion {
+	id: ID!
+	icao: String
+	callsign: String
+	zone: String
+	expression: String
+	severity: String
+	priority: Int!
+	exclude: Boolean!
+	tags: [String!]!
+	enabled: Boolean!
+	hitCount: Int!
+	falsePositives: Int!
+	lastTriggered: Time
+	"The full criterion as JSON, in the POST /api/v1/alert-criteria schema"
+	definition: String!
+	"Alerts raised by this criterion, newest first"
+	alerts(limit: Int = 20): [Alert!]!
+}
+`
+
+var graphQLSchema = graphql.MustParseSchema(graphQLSchemaSource, &gqlResolver{},
+	graphql.UseStringDescriptions(),
+	graphql.MaxDepth(maxGraphQLDepth),
+)
+
+// graphQLRequest is the body of POST /api/v1/graphql and the payload of a
+// WebSocket subscribe message.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// handleGraphQL runs a query sent as JSON, or on GET as ?query=,
+// ?operationName= and ?variables=. WebSocket upgrades carry subscriptions.
+func handleGraphQL(c *jacked.Context) error {
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		return serveGraphQLWS(c)
+	}
+	var req graphQLRequest
+	if c.Request.Method == http.MethodGet {
+		query := c.Request.URL.Query()
+		req.Query, req.OperationName = query.Get("query"), query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid variables"})
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(c.Response, c.Request.Body, maxGraphQLBody)).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid JSON"})
+	}
+	if req.Query == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "query is required"})
+	}
+	// Query errors are reported in the response body, as GraphQL clients
+	// expect.
+	return c.JSON(http.StatusOK, graphQLSchema.Exec(withGQLLoader(c.Request.Context()), req.Query, req.OperationName, req.Variables))
+}
+
+// gqlResolver resolves the Query and Subscription root fields.
+type gqlResolver struct{}
+
+func (gqlResolver) Aircraft(ctx context.Context, args struct{ MaxAge *string }) ([]*gqlAircraft, error) {
+	maxAge := trackRetention
+	if args.MaxAge != nil {
+		d, err := time.ParseDuration(*args.MaxAge)
+		if err != nil || d <= 0 {
+			return nil, errors.New("maxAge must be a positive duration, e.g. 60s")
+		}
+		maxAge = d
+	}
+	l := gqlLoaderFrom(ctx)
+	states := trackedAircraft(maxAge, nil)
+	if err := l.charge(len(states)); err != nil {
+		return nil, err
+	}
+	list := make([]*gqlAircraft, len(states))
+	for i, state := range states {
+		list[i] = &gqlAircraft{gqlPosition{state.Aircraft}, true, l}
+	}
+	return list, nil
+}
+
+func (gqlResolver) AircraftByIcao(ctx context.Context, args struct{ Icao string }) (*gqlAircraft, error) {
+	l := gqlLoaderFrom(ctx)
+	if err := l.charge(1); err != nil {
+		return nil, err
+	}
+	state, ok := latestState(strings.ToUpper(args.Icao), time.Now())
+	if !ok {
+		return nil, nil
+	}
+	return &gqlAircraft{gqlPosition{state.Aircraft}, true, l}, nil
+}
+
+type gqlAlertArgs struct {
+	Since, Until                    *graphql.Time
+	Icao, Callsign, Criterion, Zone *string
+	Severity, Tag                   *[]string
+	Acknowledged                    *bool
+	Limit, Offset                   int32
+}
+
+// Alerts reuses the REST filter by turning the arguments back into query
+// parameters.
+func (gqlResolver) Alerts(ctx context.Context, args gqlAlertArgs) ([]*gqlAlert, error) {
+	q := url.Values{}
+	for name, t := range map[string]*graphql.Time{"since": args.Since, "until": args.Until} {
+		if t != nil {
+			q.Set(name, t.Format(time.RFC3339Nano))
+		}
+	}
+	for name, v := range map[string]*string{"icao": args.Icao, "callsign": args.Callsign, "criterion": args.Criterion, "zone": args.Zone} {
+		if v != nil {
+			q.Set(name, *v)
+		}
+	}
+	if args.Severity != nil {
+		q.Set("severity", strings.Join(*args.Severity, ","))
+	}
+	if args.Tag != nil {
+		q["tag"] = *args.Tag
+	}
+	if args.Acknowledged != nil {
+		q.Set("acknowledged", strconv.FormatBool(*args.Acknowledged))
+	}
+	q.Set("limit", strconv.Itoa(int(args.Limit)))
+	q.Set("offset", strconv.Itoa(int(args.Offset)))
+	filter, err := parseAlertFilter(q)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, err := parsePage(q)
+	if err != nil {
+		return nil, err
+	}
+	return gqlLoaderFrom(ctx).recentAlerts(filter.matches, offset, limit)
+}
+
+func (gqlResolver) Alert(ctx context.Context, args struct{ ID graphql.ID }) (*gqlAlert, error) {
+	l := gqlLoaderFrom(ctx)
+	if err := l.charge(1); err != nil {
+		return nil, err
+	}
+	alert, err := store.GetAlert(string(args.ID))
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &gqlAlert{alert, l}, nil
+}
+
+func (gqlResolver) Criteria(ctx context.Context, args struct{ Tag *[]string }) ([]*gqlCriterion, error) {
+	var tags []string
+	if args.Tag != nil {
+		tags = *args.Tag
+	}
+	l := gqlLoaderFrom(ctx)
+	criteria, err := l.listCriteria()
+	if err != nil {
+		return nil, err
+	}
+	list := []*gqlCriterion{}
+	for _, criterion := range criteria {
+		if criterion.HasTags(tags) {
+			list = append(list, &gqlCriterion{criterion, l})
+		}
+	}
+	if err := l.charge(len(list)); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (gqlResolver) Criterion(ctx context.Context, args struct{ ID graphql.ID }) (*gqlCriterion, error) {
+	l := gqlLoaderFrom(ctx)
+	if err := l.charge(1); err != nil {
+		return nil, err
+	}
+	criterion, err := store.GetCriterion(string(args.ID))
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &gqlCriterion{criterion, l}, nil
+}
+
+func (gqlResolver) AircraftUpdated(ctx context.Context, args struct {
+	Icao *[]string
+	Bbox *[]float64
+}) (<-chan *gqlAircraft, error) {
+	filter := streamFilter{Events: []string{"aircraftUpdate"}}
+	if args.Icao != nil {
+		filter.ICAO = *args.Icao
+	}
+	if args.Bbox != nil {
+		if len(*args.Bbox) != 4 {
+			return nil, errors.New("bbox must be [minLat, minLon, maxLat, maxLon]")
+		}
+		filter.BBox = (*[4]float64)(*args.Bbox)
+	}
+	events, err := subscribeHub(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *gqlAircraft)
+	go func() {
+		defer close(out)
+		for data := range events {
+			var aircraft Aircraft
+			if json.Unmarshal(data, &aircraft) != nil {
+				continue
+			}
+			select {
+			case out <- &gqlAircraft{gqlPosition{aircraft}, true, newGQLLoader()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (gqlResolver) AlertRaised(ctx context.Context, args struct {
+	MinSeverity *string
+	Icao        *[]string
+}) (<-chan *gqlAlert, error) {
+	filter := streamFilter{Events: []string{"alert"}}
+	if args.MinSeverity != nil {
+		filter.MinSeverity = *args.MinSeverity
+	}
+	if args.Icao != nil {
+		filter.ICAO = *args.Icao
+	}
+	events, err := subscribeHub(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *gqlAlert)
+	go func() {
+		defer close(out)
+		for data := range events {
+			var alert Alert
+			if json.Unmarshal(data, &alert) != nil {
+				continue
+			}
+			select {
+			case out <- &gqlAlert{alert, newGQLLoader()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// subscribeHub relays the data of the hub events passing filter until ctx
+// ends or the hub drops the subscriber for being too slow.
+func subscribeHub(ctx context.Context, filter streamFilter) (<-chan []byte, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	client := hub.newClient("graphql subscription " + newID())
+	client.Filter.Store(&filter)
+	hub.register <- client
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		defer func() { hub.unregister <- client }()
+		for {
+			select {
+			case message, open := <-client.Send:
+				if !open {
+					return
+				}
+				event, data := parseSSEFrame(message)
+				if event == "server_shutdown" {
+					continue
+				}
+				select {
+				case out <- data:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+type gqlLoaderKey struct{}
+
+// gqlLoader reads the store for one query, or one subscription event, and
+// shares what it read between the resolvers: nested alerts fields look up
+// their aircraft or criterion in an index built once rather than listing
+// the alerts again at every level. It also counts the objects resolved,
+// failing the query past maxGraphQLNodes. Subscriptions give every event
+// a loader of its own, so each is read fresh and has the whole budget.
+type gqlLoader struct {
+	mu           sync.Mutex
+	nodes        int
+	alerts       []Alert
+	alertsErr    error
+	alertsRead   bool
+	byAircraft   map[string][]Alert
+	byCriterion  map[string][]Alert
+	criteria     []AlertCriteria
+	criteriaErr  error
+	criteriaRead bool
+}
+
+func newGQLLoader() *gqlLoader { return &gqlLoader{} }
+
+// withGQLLoader returns ctx carrying a new loader for the query run with it.
+func withGQLLoader(ctx context.Context) context.Context {
+	return context.WithValue(ctx, gqlLoaderKey{}, newGQLLoader())
+}
+
+func gqlLoaderFrom(ctx context.Context) *gqlLoader {
+	if l, ok := ctx.Value(gqlLoaderKey{}).(*gqlLoader); ok {
+		return l
+	}
+	return newGQLLoader()
+}
+
+// charge counts n more objects resolved.
+func (l *gqlLoader) charge(n int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nodes += n
+	if l.nodes > maxGraphQLNodes {
+		return errGraphQLTooComplex
+	}
+	return nil
+}
+
+// loadAlerts lists the stored alerts on first use. l.mu must be held.
+func (l *gqlLoader) loadAlerts() error {
+	if !l.alertsRead {
+		l.alerts, l.alertsErr = store.ListAlerts()
+		l.alertsRead = true
+	}
+	return l.alertsErr
+}
+
+// indexAlerts groups the alerts by aircraft and criterion on first use.
+// l.mu must be held.
+func (l *gqlLoader) indexAlerts() error {
+	if err := l.loadAlerts(); err != nil || l.byAircraft != nil {
+		return err
+	}
+	l.byAircraft, l.byCriterion = map[string][]Alert{}, map[string][]Alert{}
+	for _, alert := range l.alerts {
+		l.byAircraft[alert.Aircraft.ICAO] = append(l.byAircraft[alert.Aircraft.ICAO], alert)
+		if alert.Criteria.ID != "" {
+			l.byCriterion[alert.Criteria.ID] = append(l.byCriterion[alert.Criteria.ID], alert)
+		}
+	}
+	return nil
+}
+
+func (l *gqlLoader) listCriteria() ([]AlertCriteria, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.criteriaRead {
+		l.criteria, l.criteriaErr = store.ListCriteria()
+		l.criteriaRead = true
+	}
+	return l.criteria, l.criteriaErr
+}
+
+// recentAlerts returns the alerts passing match, newest first, skipping
+// offset and returning at most limit.
+func (l *gqlLoader) recentAlerts(match func(Alert) bool, offset, limit int) ([]*gqlAlert, error) {
+	l.mu.Lock()
+	err := l.loadAlerts()
+	alerts := l.alerts
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return l.newest(alerts, match, offset, limit)
+}
+
+// aircraftAlerts returns the newest alerts for icao, at most limit.
+func (l *gqlLoader) aircraftAlerts(icao string, limit int) ([]*gqlAlert, error) {
+	l.mu.Lock()
+	err := l.indexAlerts()
+	alerts := l.byAircraft[icao]
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return l.newest(alerts, nil, 0, limit)
+}
+
+// criterionAlerts returns the newest alerts raised by the criterion id,
+// at most limit.
+func (l *gqlLoader) criterionAlerts(id string, limit int) ([]*gqlAlert, error) {
+	l.mu.Lock()
+	err := l.indexAlerts()
+	alerts := l.byCriterion[id]
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return l.newest(alerts, nil, 0, limit)
+}
+
+// newest returns the alerts, stored oldest first, that pass match (all of
+// them for a nil match), newest first, skipping offset and returning at
+// most limit.
+func (l *gqlLoader) newest(alerts []Alert, match func(Alert) bool, offset, limit int) ([]*gqlAlert, error) {
+	list := []*gqlAlert{}
+	for i := len(alerts) - 1; i >= 0 && len(list) < limit; i-- {
+		if match != nil && !match(alerts[i]) {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		list = append(list, &gqlAlert{alerts[i], l})
+	}
+	if err := l.charge(len(list)); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// gqlPosition resolves a Position.
+type gqlPosition struct{ a Aircraft }
+
+func (p *gqlPosition) Lat() float64            { return p.a.Latitude }
+func (p *gqlPosition) Lon() float64            { return p.a.Longitude }
+func (p *gqlPosition) AltBaro() int32          { return int32(p.a.Altitude) }
+func (p *gqlPosition) GroundSpeed() float64    { return p.a.Speed }
+func (p *gqlPosition) Track() float64          { return p.a.Track }
+func (p *gqlPosition) Timestamp() graphql.Time { return graphql.Time{Time: p.a.Timestamp} }
+
+// gqlAircraft resolves an Aircraft: a position with its identity.
+type gqlAircraft struct {
+	gqlPosition
+	live bool
+	l    *gqlLoader
+}
+
+func (a *gqlAircraft) Icao() string     { return a.a.ICAO }
+func (a *gqlAircraft) Callsign() string { return a.a.Callsign }
+func (a *gqlAircraft) Squawk() string   { return a.a.Squawk }
+func (a *gqlAircraft) Estimated() bool  { return a.a.Estimated }
+
+func (a *gqlAircraft) AltAgl() *int32 {
+	if a.a.AltitudeAGL == nil {
+		return nil
+	}
+	v := int32(*a.a.AltitudeAGL)
+	return &v
+}
+
+func (a *gqlAircraft) Airport() *string {
+	if a.a.Airport == "" {
+		return nil
+	}
+	return &a.a.Airport
+}
+
+func (a *gqlAircraft) AirportDistance() *float64 {
+	if a.a.Airport == "" {
+		return nil
+	}
+	return &a.a.AirportDistance
+}
+
+func (a *gqlAircraft) ReceiverDistance() *float64 { return a.fromReceiver(a.a.ReceiverDistance) }
+func (a *gqlAircraft) ReceiverBearing() *float64  { return a.fromReceiver(a.a.ReceiverBearing) }
+
+// fromReceiver returns v, or nil when the aircraft has no distance or
+// bearing from the receiver.
+func (a *gqlAircraft) fromReceiver(v float64) *float64 {
+	if a.a.ReceiverDistance == 0 && a.a.ReceiverBearing == 0 {
+		return nil
+	}
+	return &v
+}
+
+// Live reports whether the aircraft is still tracked; aircraft reached
+// through alerts may not be.
+func (a *gqlAircraft) Live() bool {
+	if a.live {
+		return true
+	}
+	_, ok := lookupState(a.a.ICAO)
+	return ok
+}
+
+func (a *gqlAircraft) Positions(args struct{ Since *graphql.Time }) ([]*gqlPosition, error) {
+	if err := a.l.charge(1); err != nil {
+		return nil, err
+	}
+	var points []Aircraft
+	if args.Since != nil && historyConfig.Enabled {
+		var err error
+		if points, err = store.Positions(a.a.ICAO, args.Since.Time); err != nil {
+			return nil, err
+		}
+	} else {
+		mu.RLock()
+		points = append(points, tracks[a.a.ICAO]...)
+		mu.RUnlock()
+	}
+	list := []*gqlPosition{}
+	for _, p := range points {
+		if args.Since == nil || !p.Timestamp.Before(args.Since.Time) {
+			list = append(list, &gqlPosition{p})
+		}
+	}
+	if err := a.l.charge(len(list)); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (a *gqlAircraft) Alerts(args struct{ Limit int32 }) ([]*gqlAlert, error) {
+	return a.l.aircraftAlerts(a.a.ICAO, int(args.Limit))
+}
+
+func (a *gqlAircraft) Registration() *gqlRegistration {
+	reg := lookupRegistration(a.a.ICAO)
+	if reg == nil {
+		return nil
+	}
+	return &gqlRegistration{*reg}
+}
+
+func (a *gqlAircraft) Route() *gqlRoute {
+	route := lookupRoute(a.a)
+	if route == nil {
+		return nil
+	}
+	return &gqlRoute{*route}
+}
+
+// gqlRoute resolves a Route.
+type gqlRoute struct{ r Route }
+
+func (r *gqlRoute) Origin() string           { return r.r.Origin }
+func (r *gqlRoute) Destination() string      { return r.r.Destination }
+func (r *gqlRoute) OriginIata() *string      { return optionalString(r.r.OriginIATA) }
+func (r *gqlRoute) DestinationIata() *string { return optionalString(r.r.DestinationIATA) }
+
+// gqlRegistration resolves a Registration.
+type gqlRegistration struct{ r Registration }
+
+func (r *gqlRegistration) Registration() *string { return optionalString(r.r.Registration) }
+func (r *gqlRegistration) TypeCode() *string     { return optionalString(r.r.TypeCode) }
+func (r *gqlRegistration) Type() *string         { return optionalString(r.r.Type) }
+func (r *gqlRegistration) Operator() *string     { return optionalString(r.r.Operator) }
+func (r *gqlRegistration) Owner() *string        { return optionalString(r.r.Owner) }
+
+// gqlAlert resolves an Alert.
+type gqlAlert struct {
+	a Alert
+	l *gqlLoader
+}
+
+func (a *gqlAlert) ID() graphql.ID          { return graphql.ID(a.a.ID) }
+func (a *gqlAlert) Category() string        { return a.a.Category }
+func (a *gqlAlert) Severity() string        { return a.a.Severity }
+func (a *gqlAlert) Event() string           { return a.a.Event }
+func (a *gqlAlert) Message() string         { return a.a.Message }
+func (a *gqlAlert) Timestamp() graphql.Time { return graphql.Time{Time: a.a.Timestamp} }
+func (a *gqlAlert) Acknowledged() bool      { return a.a.Acknowledged }
+func (a *gqlAlert) FalsePositive() bool     { return a.a.FalsePositive }
+func (a *gqlAlert) AcknowledgedAt() *graphql.Time {
+	return optionalTime(a.a.AcknowledgedAt)
+}
+
+func (a *gqlAlert) Aircraft() *gqlAircraft {
+	return &gqlAircraft{gqlPosition: gqlPosition{a.a.Aircraft}, l: a.l}
+}
+
+func (a *gqlAlert) Criterion() *gqlCriterion {
+	if a.a.Criteria.ID == "" {
+		return nil
+	}
+	return &gqlCriterion{a.a.Criteria, a.l}
+}
+
+// gqlCriterion resolves a Criterion.
+type gqlCriterion struct {
+	c AlertCriteria
+	l *gqlLoader
+}
+
+func (c *gqlCriterion) ID() graphql.ID               { return graphql.ID(c.c.ID) }
+func (c *gqlCriterion) Icao() *string                { return optionalString(c.c.ICAO) }
+func (c *gqlCriterion) Callsign() *string            { return optionalString(c.c.Callsign) }
+func (c *gqlCriterion) Zone() *string                { return optionalString(c.c.Zone) }
+func (c *gqlCriterion) Expression() *string          { return optionalString(c.c.Expression) }
+func (c *gqlCriterion) Severity() *string            { return optionalString(c.c.Severity) }
+func (c *gqlCriterion) Priority() int32              { return int32(c.c.Priority) }
+func (c *gqlCriterion) Exclude() bool                { return c.c.Exclude }
+func (c *gqlCriterion) Tags() []string               { return append([]string{}, c.c.Tags...) }
+func (c *gqlCriterion) Enabled() bool                { return c.c.Enabled }
+func (c *gqlCriterion) HitCount() int32              { return int32(c.c.HitCount) }
+func (c *gqlCriterion) FalsePositives() int32        { return int32(c.c.FalsePositives) }
+func (c *gqlCriterion) LastTriggered() *graphql.Time { return optionalTime(c.c.LastTriggered) }
+
+func (c *gqlCriterion) Definition() (string, error) {
+	data, err := json.Marshal(c.c)
+	return string(data), err
+}
+
+func (c *gqlCriterion) Alerts(args struct{ Limit int32 }) ([]*gqlAlert, error) {
+	return c.l.criterionAlerts(c.c.ID, int(args.Limit))
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
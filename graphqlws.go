@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"github.com/gorilla/websocket"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// graphQLWSProtocol is the graphql-ws library's subprotocol, spoken by
+// Apollo, urql and GraphiQL.
+const graphQLWSProtocol = "graphql-transport-ws"
+
+// graphQLInitTimeout is how long a client has to send connection_init.
+const graphQLInitTimeout = 10 * time.Second
+
+var graphQLUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	Subprotocols:    []string{graphQLWSProtocol},
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// graphQLWSMessage is a graphql-transport-ws frame.
+type graphQLWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// serveGraphQLWS runs subscriptions (and one-off queries) over a WebSocket
+// until the client goes away. Each subscribe message starts an operation
+// under the client's ID; complete stops it.
+func serveGraphQLWS(c *jacked.Context) error {
+	conn, err := graphQLUpgrader.Upgrade(c.Response, c.Request, nil)
+	if err != nil {
+		logRequestf(c.Request, "GraphQL: upgrade failed for %s: %v", c.Request.RemoteAddr, err)
+		return nil // Upgrade has already replied
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != graphQLWSProtocol {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4406, "Subprotocol not acceptable"), time.Now().Add(wsWriteWait))
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var (
+		out        = make(chan graphQLWSMessage, 16)
+		opsMu      sync.Mutex
+		operations = map[string]context.CancelFunc{}
+		done       = make(chan struct{}) // Closed when the reader stops
+	)
+	send := func(msg graphQLWSMessage) {
+		select {
+		case out <- msg:
+		case <-ctx.Done():
+		}
+	}
+	payload := func(v interface{}) json.RawMessage {
+		data, _ := json.Marshal(v)
+		return data
+	}
+
+	go func() {
+		defer close(done)
+		conn.SetReadLimit(maxGraphQLBody)
+		conn.SetReadDeadline(time.Now().Add(graphQLInitTimeout))
+		conn.SetPongHandler(func(string) error { return conn.SetReadDeadline(time.Now().Add(wsPongWait)) })
+		initialised := false
+		for {
+			var msg graphQLWSMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			switch msg.Type {
+			case "connection_init":
+				if initialised {
+					conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4429, "Too many initialisation requests"), time.Now().Add(wsWriteWait))
+					return
+				}
+				initialised = true
+				conn.SetReadDeadline(time.Now().Add(wsPongWait))
+				send(graphQLWSMessage{Type: "connection_ack"})
+			case "ping":
+				send(graphQLWSMessage{Type: "pong"})
+			case "pong":
+				conn.SetReadDeadline(time.Now().Add(wsPongWait))
+			case "subscribe":
+				if !initialised {
+					conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4401, "Unauthorized"), time.Now().Add(wsWriteWait))
+					return
+				}
+				var req graphQLRequest
+				if err := json.Unmarshal(msg.Payload, &req); err != nil || msg.ID == "" {
+					conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4400, "Invalid subscribe message"), time.Now().Add(wsWriteWait))
+					return
+				}
+				opsMu.Lock()
+				if _, exists := operations[msg.ID]; exists {
+					opsMu.Unlock()
+					conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4409, "Subscriber for "+msg.ID+" already exists"), time.Now().Add(wsWriteWait))
+					return
+				}
+				opCtx, stop := context.WithCancel(withGQLLoader(ctx))
+				operations[msg.ID] = stop
+				opsMu.Unlock()
+
+				go func(id string) {
+					defer func() {
+						opsMu.Lock()
+						delete(operations, id)
+						opsMu.Unlock()
+						stop()
+					}()
+					responses, err := graphQLSchema.Subscribe(opCtx, req.Query, req.OperationName, req.Variables)
+					if err != nil {
+						send(graphQLWSMessage{ID: id, Type: "error", Payload: payload([]map[string]string{{"message": err.Error()}})})
+						return
+					}
+					for r := range responses {
+						resp := r.(*graphql.Response)
+						if resp.Data == nil && len(resp.Errors) > 0 {
+							send(graphQLWSMessage{ID: id, Type: "error", Payload: payload(resp.Errors)})
+							return
+						}
+						send(graphQLWSMessage{ID: id, Type: "next", Payload: payload(resp)})
+					}
+					if opCtx.Err() == nil {
+						send(graphQLWSMessage{ID: id, Type: "complete"})
+					}
+				}(msg.ID)
+			case "complete":
+				opsMu.Lock()
+				if stop, ok := operations[msg.ID]; ok {
+					stop()
+				}
+				opsMu.Unlock()
+			default:
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4400, "Unknown message type"), time.Now().Add(wsWriteWait))
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	log.Printf("GraphQL: client %s connected", c.Request.RemoteAddr)
+	for {
+		var err error
+		select {
+		case msg := <-out:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = conn.WriteJSON(msg)
+		case <-ping.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+		case <-done:
+			log.Printf("GraphQL: client %s disconnected", c.Request.RemoteAddr)
+			return nil
+		}
+		if err != nil {
+			log.Printf("GraphQL: error writing to client %s: %v", c.Request.RemoteAddr, err)
+			return nil
+		}
+	}
+}
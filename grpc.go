@@ -0,0 +1,360 @@
+package main
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/aircraftalert/v1/aircraftalert.proto
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	pb "aircraft-alert/proto/aircraftalert/v1"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcServer implements the gRPC API defined in
+// proto/aircraftalert/v1/aircraftalert.proto.
+type grpcServer struct {
+	pb.UnimplementedAircraftAlertServiceServer
+}
+
+// serveGRPC starts serving the gRPC API on addr and returns the server for
+// shutdown. API keys and rate limits are checked as for the REST API, with
+// keys sent as x-api-key metadata. tlsCfg, when set, is the HTTPS
+// server's, so both APIs use the same certificate.
+func serveGRPC(addr string, auth AuthConfig, limits *rateLimits, tlsCfg *tls.Config) *grpc.Server {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("gRPC: %v", err)
+	}
+	opts := append(auth.grpcInterceptors(limits), grpcRecovery()...)
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+	srv := grpc.NewServer(opts...)
+	pb.RegisterAircraftAlertServiceServer(srv, grpcServer{})
+	log.Printf("gRPC API listening on %s", addr)
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			log.Fatalf("gRPC: %v", err)
+		}
+	}()
+	return srv
+}
+
+func (grpcServer) SubmitAircraft(ctx context.Context, req *pb.SubmitAircraftRequest) (*pb.SubmitAircraftResponse, error) {
+	if req.GetAircraft().GetIcao() == "" {
+		return nil, status.Error(codes.InvalidArgument, "aircraft.icao is required")
+	}
+	aircraft := aircraftFromProto(req.Aircraft)
+	if problems, ok := checkAircraft(&aircraft); !ok {
+		return nil, invalidAircraftStatus(problems)
+	}
+	if err := receiveAircraft(grpcTraceContext(ctx), aircraft, sourceGRPC); err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &pb.SubmitAircraftResponse{}, nil
+}
+
+func (grpcServer) ListAircraft(ctx context.Context, req *pb.ListAircraftRequest) (*pb.ListAircraftResponse, error) {
+	maxAge := trackRetention
+	if req.MaxAgeSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_age_seconds must not be negative")
+	} else if req.MaxAgeSeconds > 0 {
+		maxAge = time.Duration(req.MaxAgeSeconds) * time.Second
+	}
+	resp := &pb.ListAircraftResponse{}
+	for _, s := range trackedAircraft(maxAge, nil) {
+		resp.Aircraft = append(resp.Aircraft, aircraftToProto(s.Aircraft))
+	}
+	return resp, nil
+}
+
+func (grpcServer) ListAlerts(ctx context.Context, req *pb.ListAlertsRequest) (*pb.ListAlertsResponse, error) {
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultAlertPageSize
+	}
+	if limit < 0 || limit > maxAlertPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxAlertPageSize)
+	}
+	filter := alertFilter{icao: strings.ToUpper(req.Icao), criterion: req.CriterionId}
+	for _, s := range req.Severities {
+		if _, ok := severityRank[s]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown severity %q", s)
+		}
+		filter.severities = append(filter.severities, s)
+	}
+	if req.Since != nil {
+		filter.since = req.Since.AsTime()
+	}
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &pb.ListAlertsResponse{}
+	for i := len(alerts) - 1; i >= 0 && len(resp.Alerts) < limit; i-- {
+		if filter.matches(alerts[i]) {
+			resp.Alerts = append(resp.Alerts, alertToProto(alerts[i]))
+		}
+	}
+	return resp, nil
+}
+
+func (grpcServer) ListAlertCriteria(ctx context.Context, req *pb.ListAlertCriteriaRequest) (*pb.ListAlertCriteriaResponse, error) {
+	criteria, err := store.ListCriteria()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &pb.ListAlertCriteriaResponse{}
+	for _, criterion := range criteria {
+		if criterion.HasTags(req.Tags) {
+			resp.Criteria = append(resp.Criteria, criterionToProto(criterion))
+		}
+	}
+	return resp, nil
+}
+
+func (grpcServer) CreateAlertCriterion(ctx context.Context, req *pb.CreateAlertCriterionRequest) (*pb.AlertCriterion, error) {
+	if req.Criterion == nil {
+		return nil, status.Error(codes.InvalidArgument, "criterion is required")
+	}
+	criterion := criterionFromProto(req.Criterion)
+	mu.Lock()
+	if err := validateCriterion(&criterion); err != nil {
+		mu.Unlock()
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	criterion, err := addAlertCriterion(criterion)
+	mu.Unlock()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.Printf("Added new alert criterion over gRPC: %+v", criterion)
+	return criterionToProto(criterion), nil
+}
+
+func (grpcServer) DeleteAlertCriterion(ctx context.Context, req *pb.DeleteAlertCriterionRequest) (*pb.DeleteAlertCriterionResponse, error) {
+	mu.Lock()
+	err := store.DeleteCriterion(req.Id)
+	mu.Unlock()
+	if errors.Is(err, errNotFound) {
+		return nil, status.Error(codes.NotFound, "criterion not found")
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.Printf("Deleted alert criterion %s", req.Id)
+	return &pb.DeleteAlertCriterionResponse{}, nil
+}
+
+// StreamEvents relays the hub's events, like the SSE and WebSocket
+// endpoints.
+func (grpcServer) StreamEvents(req *pb.StreamEventsRequest, stream pb.AircraftAlertService_StreamEventsServer) error {
+	filter := streamFilter{ICAO: req.Icao, MinSeverity: req.MinSeverity}
+	if req.AircraftUpdates {
+		filter.Events = append(filter.Events, "aircraftUpdate")
+	}
+	if req.Alerts {
+		filter.Events = append(filter.Events, "alert")
+	}
+	if b := req.Bbox; b != nil {
+		filter.BBox = &[4]float64{b.MinLat, b.MinLon, b.MaxLat, b.MaxLon}
+	}
+	if err := filter.validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	client := hub.newClient(peerAddr(stream.Context()) + " (grpc)")
+	client.Filter.Store(&filter)
+	hub.register <- client
+	defer func() { hub.unregister <- client }()
+
+	for {
+		select {
+		case message, open := <-client.Send:
+			if !open && hub.stopped.Load() {
+				return status.Error(codes.Unavailable, "server shutting down")
+			} else if !open {
+				return status.Error(codes.ResourceExhausted, "client too slow, dropped")
+			}
+			event, data := parseSSEFrame(message)
+			if event == "aircraftRemoved" || event == "expired" || event == "server_shutdown" {
+				continue // The proto has no removal event yet
+			}
+			out, err := eventToProto(event, data)
+			if err != nil {
+				log.Printf("gRPC: error decoding %s event: %v", event, err)
+				continue
+			}
+			if out == nil {
+				continue
+			}
+			if err := stream.Send(out); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+// peerIP returns the caller's address without the port.
+func peerIP(ctx context.Context) string {
+	addr := peerAddr(ctx)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func eventToProto(event string, data []byte) (*pb.Event, error) {
+	if event == "alert" {
+		var alert Alert
+		if err := json.Unmarshal(data, &alert); err != nil {
+			return nil, err
+		}
+		return &pb.Event{Event: &pb.Event_Alert{Alert: alertToProto(alert)}}, nil
+	}
+	var aircraft Aircraft
+	if err := json.Unmarshal(data, &aircraft); err != nil {
+		return nil, err
+	}
+	if aircraft.Estimated {
+		return nil, nil // The proto cannot flag estimated positions yet
+	}
+	return &pb.Event{Event: &pb.Event_AircraftUpdate{AircraftUpdate: aircraftToProto(aircraft)}}, nil
+}
+
+func aircraftToProto(a Aircraft) *pb.Aircraft {
+	return &pb.Aircraft{
+		Icao:      a.ICAO,
+		Callsign:  a.Callsign,
+		Lat:       a.Latitude,
+		Lon:       a.Longitude,
+		AltBaro:   int32(a.Altitude),
+		Gs:        a.Speed,
+		Track:     a.Track,
+		Squawk:    a.Squawk,
+		Timestamp: timestamppb.New(a.Timestamp),
+	}
+}
+
+func aircraftFromProto(a *pb.Aircraft) Aircraft {
+	return Aircraft{
+		ICAO:      a.Icao,
+		Callsign:  a.Callsign,
+		Latitude:  a.Lat,
+		Longitude: a.Lon,
+		Altitude:  int(a.AltBaro),
+		Speed:     a.Gs,
+		Track:     a.Track,
+		Squawk:    a.Squawk,
+	}
+}
+
+func criterionToProto(ac AlertCriteria) *pb.AlertCriterion {
+	out := &pb.AlertCriterion{
+		Id:              ac.ID,
+		Icao:            ac.ICAO,
+		Callsign:        ac.Callsign,
+		IcaoRanges:      ac.ICAORanges,
+		Squawks:         ac.Squawks,
+		Zone:            ac.Zone,
+		MinAltitude:     int32(ac.MinAltitude),
+		MaxAltitude:     int32(ac.MaxAltitude),
+		MinSpeed:        ac.MinSpeed,
+		MaxSpeed:        ac.MaxSpeed,
+		Expression:      ac.Expression,
+		Exclude:         ac.Exclude,
+		Priority:        int32(ac.Priority),
+		MessageTemplate: ac.MessageTemplate,
+		Severity:        ac.Severity,
+		Tags:            ac.Tags,
+		Notify:          ac.Notify,
+		Enabled:         proto.Bool(ac.Enabled),
+		HitCount:        int32(ac.HitCount),
+		FalsePositives:  int32(ac.FalsePositives),
+	}
+	if ac.LastTriggered != nil {
+		out.LastTriggered = timestamppb.New(*ac.LastTriggered)
+	}
+	return out
+}
+
+// criterionFromProto converts a criterion for creation; the ID and hit
+// counts are left for the server to set.
+func criterionFromProto(ac *pb.AlertCriterion) AlertCriteria {
+	return AlertCriteria{
+		ICAO:            ac.Icao,
+		Callsign:        ac.Callsign,
+		ICAORanges:      ac.IcaoRanges,
+		Squawks:         ac.Squawks,
+		Zone:            ac.Zone,
+		MinAltitude:     int(ac.MinAltitude),
+		MaxAltitude:     int(ac.MaxAltitude),
+		MinSpeed:        ac.MinSpeed,
+		MaxSpeed:        ac.MaxSpeed,
+		Expression:      ac.Expression,
+		Exclude:         ac.Exclude,
+		Priority:        int(ac.Priority),
+		MessageTemplate: ac.MessageTemplate,
+		Severity:        ac.Severity,
+		Tags:            ac.Tags,
+		Notify:          ac.Notify,
+		Enabled:         ac.Enabled == nil || *ac.Enabled,
+	}
+}
+
+func alertToProto(a Alert) *pb.Alert {
+	out := &pb.Alert{
+		Id:            a.ID,
+		Category:      a.Category,
+		Severity:      a.Severity,
+		Event:         a.Event,
+		Aircraft:      aircraftToProto(a.Aircraft),
+		Message:       a.Message,
+		Timestamp:     timestamppb.New(a.Timestamp),
+		FalsePositive: a.FalsePositive,
+		Acknowledged:  a.Acknowledged,
+	}
+	if a.Criteria.ID != "" {
+		out.Criterion = criterionToProto(a.Criteria)
+	}
+	return out
+}
+
+// invalidAircraftStatus reports the problems with a submitted aircraft as
+// an InvalidArgument status carrying a BadRequest detail per field.
+func invalidAircraftStatus(problems []FieldError) error {
+	st := status.New(codes.InvalidArgument, "invalid aircraft: "+fieldErrorSummary(problems))
+	details := &errdetails.BadRequest{}
+	for _, p := range problems {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       "aircraft." + p.Field,
+			Description: p.Message,
+		})
+	}
+	if withDetails, err := st.WithDetails(details); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
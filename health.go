@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// healthCheckTimeout bounds each check, so a hung dependency fails the probe
+// instead of stalling it.
+const healthCheckTimeout = 2 * time.Second
+
+// Health check states.
+const (
+	healthOK   = "ok"
+	healthFail = "fail"
+)
+
+// HealthConfig tunes the readiness probe.
+type HealthConfig struct {
+	// IngestTimeout fails readiness when no aircraft update has arrived from
+	// any source for this long. Zero only reports when the last one did, as
+	// quiet skies are not an outage for most receivers.
+	IngestTimeout time.Duration `yaml:"ingest_timeout"`
+}
+
+var healthConfig HealthConfig
+
+// HealthCheck is the result of one check.
+type HealthCheck struct {
+	Status    string  `json:"status"` // ok or fail
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+
+	// Ingest only: when the last update arrived, overall and by source.
+	LastUpdate *time.Time           `json:"last_update,omitempty"`
+	Sources    map[string]time.Time `json:"sources,omitempty"`
+}
+
+// HealthReport is returned by /healthz and /readyz, with status 503 when any
+// check fails.
+type HealthReport struct {
+	Status        string                 `json:"status"` // ok or fail
+	Checks        map[string]HealthCheck `json:"checks"`
+	UptimeSeconds int64                  `json:"uptime_seconds"`
+}
+
+// timedCheck runs check and records how long it took.
+func timedCheck(check func(ctx context.Context) HealthCheck) HealthCheck {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	start := time.Now()
+	result := check(ctx)
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	return result
+}
+
+// checkStorage pings the storage backend.
+func checkStorage(ctx context.Context) HealthCheck {
+	if err := store.Ping(ctx); err != nil {
+		return HealthCheck{Status: healthFail, Error: err.Error()}
+	}
+	return HealthCheck{Status: healthOK}
+}
+
+// checkHub makes sure the stream hub still takes messages; a stuck hub
+// blocks every update.
+func checkHub(ctx context.Context) HealthCheck {
+	reply := make(chan struct{})
+	select {
+	case hub.ping <- reply:
+	case <-ctx.Done():
+		return HealthCheck{Status: healthFail, Error: "hub not responding"}
+	}
+	<-reply
+	return HealthCheck{Status: healthOK}
+}
+
+// checkIngest reports when updates last arrived from each source.
+func checkIngest(context.Context) HealthCheck {
+	result := HealthCheck{Status: healthOK, Sources: map[string]time.Time{}}
+	messageStats.Lock()
+	for source, r := range messageStats.sources {
+		result.Sources[source] = r.latest
+		if result.LastUpdate == nil || r.latest.After(*result.LastUpdate) {
+			latest := r.latest
+			result.LastUpdate = &latest
+		}
+	}
+	messageStats.Unlock()
+
+	if timeout := healthConfig.IngestTimeout; timeout > 0 {
+		// Give feeders the timeout to connect after a restart.
+		last := startTime
+		if result.LastUpdate != nil {
+			last = *result.LastUpdate
+		}
+		if time.Since(last) > timeout {
+			result.Status = healthFail
+			result.Error = "no aircraft updates for " + time.Since(last).Truncate(time.Second).String()
+		}
+	}
+	return result
+}
+
+// healthResponse runs the checks and replies 200 when all pass, 503
+// otherwise.
+func healthResponse(c *jacked.Context, checks map[string]func(context.Context) HealthCheck) error {
+	report := HealthReport{
+		Status:        healthOK,
+		Checks:        map[string]HealthCheck{},
+		UptimeSeconds: int64(time.Since(startTime).Seconds()),
+	}
+	for name, check := range checks {
+		result := timedCheck(check)
+		if result.Status != healthOK {
+			report.Status = healthFail
+		}
+		report.Checks[name] = result
+	}
+	c.Response.Header().Set("Cache-Control", "no-store")
+	status := http.StatusOK
+	if report.Status != healthOK {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
+
+// handleHealthz is the liveness probe: it fails only when the process is
+// wedged and needs restarting.
+func handleHealthz(c *jacked.Context) error {
+	return healthResponse(c, map[string]func(context.Context) HealthCheck{
+		"hub": checkHub,
+	})
+}
+
+// handleReadyz is the readiness probe: it fails while the server cannot do
+// its job, such as when the storage backend is unreachable.
+func handleReadyz(c *jacked.Context) error {
+	checks := map[string]func(context.Context) HealthCheck{
+		"storage": checkStorage,
+		"ingest":  checkIngest,
+		"hub":     checkHub,
+	}
+	if hub.broker != nil {
+		checks["cluster"] = checkCluster
+	}
+	return healthResponse(c, checks)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+const (
+	historyPruneEvery   = time.Hour
+	alertHistoryDefault = 10 * time.Minute
+)
+
+// HistoryConfig controls the long-term position history kept in the store.
+type HistoryConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Retention time.Duration `yaml:"retention"` // e.g. 168h; zero keeps positions forever
+}
+
+func defaultHistoryConfig() HistoryConfig {
+	return HistoryConfig{Enabled: true, Retention: 7 * 24 * time.Hour}
+}
+
+// historyConfig is set from the configuration at startup.
+var historyConfig = defaultHistoryConfig()
+
+// recordHistory stores an accepted position in the history store.
+func recordHistory(aircraft Aircraft) {
+	if !historyConfig.Enabled {
+		return
+	}
+	if err := store.AddPosition(aircraft); err != nil {
+		log.Printf("Error recording position history for %s: %v", aircraft.ICAO, err)
+	}
+}
+
+// pruneHistory periodically deletes positions older than the retention.
+func pruneHistory() {
+	if !historyConfig.Enabled || historyConfig.Retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(historyPruneEvery)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := store.PrunePositions(time.Now().Add(-historyConfig.Retention)); err != nil {
+			log.Printf("Error pruning position history: %v", err)
+		}
+	}
+}
+
+// positionsBetween returns the stored positions of icao in [since, until].
+// A zero until means now.
+func positionsBetween(icao string, since, until time.Time) ([]Aircraft, error) {
+	positions, err := store.Positions(icao, since)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Aircraft, 0, len(positions))
+	for _, p := range positions {
+		if until.IsZero() || !p.Timestamp.After(until) {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+// handleAircraftHistory returns the recorded positions of one aircraft,
+// by default over the last hour. ?since= and ?until= take Unix seconds or
+// RFC 3339.
+func handleAircraftHistory(c *jacked.Context) error {
+	since, until, err := parseTimeRange(c.Request.URL.Query())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	positions, err := positionsBetween(strings.ToUpper(c.Param("icao")), since, until)
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, positions)
+}
+
+// parseTimeRange reads ?since= (default an hour ago) and ?until= (default
+// now, returned as zero) in Unix seconds or RFC 3339.
+func parseTimeRange(query url.Values) (since, until time.Time, err error) {
+	since = time.Now().Add(-time.Hour)
+	if v := query.Get("since"); v != "" {
+		if since, err = parseTimeParam(v); err != nil {
+			return since, until, errors.New("since must be Unix seconds or an RFC 3339 time")
+		}
+	}
+	if v := query.Get("until"); v != "" {
+		if until, err = parseTimeParam(v); err != nil {
+			return since, until, errors.New("until must be Unix seconds or an RFC 3339 time")
+		}
+	}
+	return since, until, nil
+}
+
+// handleAircraftTrack returns the flight path of one aircraft for drawing,
+// oldest first. It takes the ?since= and ?until= of the history endpoint and
+// can be thinned out with ?interval= (minimum spacing, e.g. 30s) and
+// ?max_points=. Without a history store it falls back to the recent track
+// kept in memory. ?format=geojson returns the path as a LineString feature.
+func handleAircraftTrack(c *jacked.Context) error {
+	query := c.Request.URL.Query()
+	since, until, err := parseTimeRange(query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	var interval time.Duration
+	if v := query.Get("interval"); v != "" {
+		if interval, err = time.ParseDuration(v); err != nil || interval < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid interval"})
+		}
+	}
+	maxPoints := 0
+	if v := query.Get("max_points"); v != "" {
+		if maxPoints, err = strconv.Atoi(v); err != nil || maxPoints < 2 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "max_points must be at least 2"})
+		}
+	}
+
+	icao := strings.ToUpper(c.Param("icao"))
+	var points []Aircraft
+	if historyConfig.Enabled {
+		if points, err = positionsBetween(icao, since, until); err != nil {
+			return storeError(c, err)
+		}
+	} else {
+		mu.RLock()
+		for _, p := range recentTrack(icao, since) {
+			if until.IsZero() || !p.Timestamp.After(until) {
+				points = append(points, p)
+			}
+		}
+		mu.RUnlock()
+	}
+	points = downsampleTrack(points, interval, maxPoints)
+	if wantsGeoJSON(c) {
+		return writeGeoJSON(c, trackGeoJSON(icao, points))
+	}
+	return c.JSON(http.StatusOK, append([]Aircraft{}, points...))
+}
+
+// downsampleTrack keeps points at least interval apart, then at most
+// maxPoints of them evenly spread. The first and last points always stay,
+// so the path still ends at the latest position. Zero disables either step.
+func downsampleTrack(points []Aircraft, interval time.Duration, maxPoints int) []Aircraft {
+	if interval > 0 && len(points) > 2 {
+		kept := []Aircraft{points[0]}
+		for _, p := range points[1 : len(points)-1] {
+			if p.Timestamp.Sub(kept[len(kept)-1].Timestamp) >= interval {
+				kept = append(kept, p)
+			}
+		}
+		points = append(kept, points[len(points)-1])
+	}
+	if maxPoints > 0 && len(points) > maxPoints {
+		step := float64(len(points)-1) / float64(maxPoints-1)
+		kept := make([]Aircraft, maxPoints)
+		for i := range kept {
+			kept[i] = points[int(float64(i)*step+0.5)]
+		}
+		points = kept
+	}
+	return points
+}
+
+// handleAlertHistory returns the track of the alerted aircraft around the
+// time of an alert, ?window= seconds either side (default ten minutes).
+func handleAlertHistory(c *jacked.Context) error {
+	window := alertHistoryDefault
+	if v := c.Request.URL.Query().Get("window"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid window"})
+		}
+		window = time.Duration(secs) * time.Second
+	}
+
+	alert, err := store.GetAlert(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Alert not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	positions, err := positionsBetween(alert.Aircraft.ICAO, alert.Timestamp.Add(-window), alert.Timestamp.Add(window))
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"alert":     alert,
+		"positions": positions,
+	})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxInfluxLines = 50000
+
+// InfluxConfig configures the optional time-series writer. Any endpoint that
+// accepts InfluxDB line protocol works, e.g. InfluxDB 2
+// (/api/v2/write?org=..&bucket=..), InfluxDB 1 (/write?db=..) or Telegraf's
+// http_listener_v2.
+type InfluxConfig struct {
+	URL      string        `yaml:"url"`      // Write endpoint; empty disables the writer
+	Token    string        `yaml:"token"`    // Sent as "Authorization: Token <token>" when set
+	Interval time.Duration `yaml:"interval"` // How often buffered points are flushed
+}
+
+func defaultInfluxConfig() InfluxConfig {
+	return InfluxConfig{Interval: 10 * time.Second}
+}
+
+// influxMetrics buffers points between flushes.
+var influxMetrics = struct {
+	sync.Mutex
+	enabled   bool
+	lines     []string
+	positions int
+	aircraft  map[string]bool
+	alerts    map[[2]string]int // [category, severity] -> count
+}{
+	aircraft: map[string]bool{},
+	alerts:   map[[2]string]int{},
+}
+
+// influxEscape escapes a tag key or value for line protocol.
+var influxEscape = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`).Replace
+
+// recordPositionMetrics buffers the altitude and speed of a position.
+func recordPositionMetrics(aircraft Aircraft) {
+	m := &influxMetrics
+	m.Lock()
+	defer m.Unlock()
+	if !m.enabled {
+		return
+	}
+	m.positions++
+	m.aircraft[aircraft.ICAO] = true
+	if len(m.lines) >= maxInfluxLines {
+		return
+	}
+	tags := "icao=" + influxEscape(aircraft.ICAO)
+	if aircraft.Callsign != "" {
+		tags += ",callsign=" + influxEscape(aircraft.Callsign)
+	}
+	m.lines = append(m.lines, fmt.Sprintf("aircraft,%s altitude=%di,speed=%g,track=%g %d",
+		tags, aircraft.Altitude, aircraft.Speed, aircraft.Track, aircraft.Timestamp.UnixNano()))
+}
+
+// recordAlertMetrics counts an alert towards the next flush.
+func recordAlertMetrics(alert Alert) {
+	m := &influxMetrics
+	m.Lock()
+	defer m.Unlock()
+	if m.enabled {
+		m.alerts[[2]string{alert.Category, alert.Severity}]++
+	}
+}
+
+// runInfluxWriter flushes buffered points to the configured endpoint until
+// the process exits. Points that fail to send are dropped.
+func runInfluxWriter(cfg InfluxConfig) {
+	m := &influxMetrics
+	m.Lock()
+	m.enabled = true
+	m.Unlock()
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		body := takeInfluxLines(now)
+		req, err := http.NewRequest(http.MethodPost, cfg.URL, strings.NewReader(body))
+		if err != nil {
+			log.Printf("InfluxDB: invalid write URL: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		if cfg.Token != "" {
+			req.Header.Set("Authorization", "Token "+cfg.Token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Printf("InfluxDB: write failed: %v", err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			log.Printf("InfluxDB: write rejected with status %s", resp.Status)
+		}
+	}
+}
+
+// takeInfluxLines drains the buffer into a line protocol body, adding the
+// position and alert counters for the interval ending at now.
+func takeInfluxLines(now time.Time) string {
+	m := &influxMetrics
+	m.Lock()
+	defer m.Unlock()
+
+	var b bytes.Buffer
+	for _, line := range m.lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	ts := now.UnixNano()
+	fmt.Fprintf(&b, "airspace positions=%di,aircraft=%di %d\n", m.positions, len(m.aircraft), ts)
+
+	keys := make([][2]string, 0, len(m.alerts))
+	for k := range m.alerts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0]+keys[i][1] < keys[j][0]+keys[j][1]
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&b, "alerts,category=%s,severity=%s count=%di %d\n",
+			influxEscape(k[0]), influxEscape(k[1]), m.alerts[k], ts)
+	}
+
+	m.lines = m.lines[:0]
+	m.positions = 0
+	clear(m.aircraft)
+	clear(m.alerts)
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultLoiterTurnRate = 0.5
+	defaultLoiterDuration = 300
+	defaultLoiterOrbits   = 1
+	defaultLoiterRadius   = 5
+)
+
+// loitering remembers which (criterion, ICAO) pairs are currently orbiting so
+// that one sustained orbit produces a single alert. Guarded by mu.
+var loitering = map[string]bool{}
+
+func loiterDefaults(p LoiterParams) LoiterParams {
+	if p.MinTurnRate == 0 {
+		p.MinTurnRate = defaultLoiterTurnRate
+	}
+	if p.MinDuration == 0 {
+		p.MinDuration = defaultLoiterDuration
+	}
+	if p.MinOrbits == 0 {
+		p.MinOrbits = defaultLoiterOrbits
+	}
+	if p.MaxRadius == 0 {
+		p.MaxRadius = defaultLoiterRadius
+	}
+	return p
+}
+
+func validateLoiter(p LoiterParams) error {
+	if p.MinTurnRate < 0 || p.MinDuration < 0 || p.MinOrbits < 0 || p.MaxRadius < 0 {
+		return fmt.Errorf("loiter parameters must not be negative")
+	}
+	return nil
+}
+
+// detectLoiter analyses the recent track of the aircraft and reports when it
+// starts orbiting. Callers must hold mu and have recorded the current point.
+func detectLoiter(criterion *AlertCriteria, aircraft Aircraft) (string, bool) {
+	p := loiterDefaults(*criterion.Loiter)
+	key := criterion.ID + "|" + aircraft.ICAO
+	window := time.Duration(p.MinDuration) * time.Second
+
+	orbits, lat, lon, ok := measureOrbit(tracks[aircraft.ICAO], aircraft.Timestamp.Add(-window), p)
+	if !ok || orbits < p.MinOrbits {
+		delete(loitering, key)
+		return "", false
+	}
+	if loitering[key] {
+		return "", false
+	}
+	loitering[key] = true
+	return fmt.Sprintf("Aircraft loitering: %s (%s) completed %.1f orbits near %.4f, %.4f",
+		aircraft.Callsign, aircraft.ICAO, orbits, lat, lon), true
+}
+
+// measureOrbit returns the number of orbits flown since the given time and
+// the centre of the orbit. ok is false when the track does not cover the
+// whole window, turns too slowly, or drifts outside the allowed radius.
+func measureOrbit(points []Aircraft, since time.Time, p LoiterParams) (orbits, lat, lon float64, ok bool) {
+	if len(points) < 3 || points[0].Timestamp.After(since) {
+		return 0, 0, 0, false
+	}
+	start := 0
+	for start < len(points)-1 && points[start+1].Timestamp.Before(since) {
+		start++
+	}
+	points = points[start:]
+
+	var turned float64
+	for i := 1; i < len(points); i++ {
+		d := math.Mod(points[i].Track-points[i-1].Track+540, 360) - 180
+		turned += d
+		lat += points[i].Latitude
+		lon += points[i].Longitude
+	}
+	lat /= float64(len(points) - 1)
+	lon /= float64(len(points) - 1)
+
+	span := points[len(points)-1].Timestamp.Sub(points[0].Timestamp).Seconds()
+	if span <= 0 || math.Abs(turned)/span < p.MinTurnRate {
+		return 0, 0, 0, false
+	}
+	for _, pt := range points[1:] {
+		if distanceNM(lat, lon, pt.Latitude, pt.Longitude) > p.MaxRadius {
+			return 0, 0, 0, false
+		}
+	}
+	return math.Abs(turned) / 360, lat, lon, true
+}
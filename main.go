@@ -1,16 +1,26 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	webpush "github.com/SherClockHolmes/webpush-go"
 	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 )
 
 // setSecurityHeaders sets appropriate security headers.
@@ -33,67 +43,439 @@ func setSecurityHeaders(w http.ResponseWriter) {
 	w.Header().Set("X-XSS-Protection", "1; mode=block")
 	w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
 	w.Header().Set("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
+	if tlsEnabled {
+		w.Header().Set("Strict-Transport-Security", "max-age=31536000")
+	}
 }
 
 var (
-	alertCriteria   []AlertCriteria
-	triggeredAlerts []Alert
-	mu              sync.Mutex
-	hub             *Hub
+	// mu serialises alert evaluation and guards the detector state. Handlers
+	// that only read it take the read lock.
+	mu  sync.RWMutex
+	hub *Hub
 )
 
-// Client represents a single SSE client connection.
+// StreamConfig configures the SSE, WebSocket and gRPC event streams.
+type StreamConfig struct {
+	// Backlog is how many recent events are kept for SSE clients resuming
+	// with Last-Event-ID; zero disables resuming.
+	Backlog int `yaml:"backlog"`
+	// ClientBuffer is how many events may wait for a slow client before
+	// WhenFull applies: "disconnect" drops the client, "drop_updates"
+	// discards its oldest position updates but never alerts.
+	ClientBuffer int    `yaml:"client_buffer"`
+	WhenFull     string `yaml:"when_full"`
+	// Heartbeat is how often SSE clients are sent a keepalive comment, so
+	// proxies and NAT do not time out quiet streams.
+	Heartbeat time.Duration `yaml:"heartbeat"`
+	// WriteTimeout bounds each write to an SSE client; a client that takes
+	// no event or heartbeat within it is dropped.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// BatchWindow is how long position updates are held for SSE clients
+	// asking for batches; zero sends them one by one.
+	BatchWindow time.Duration `yaml:"batch_window"`
+	// SnapshotInterval is how often SSE clients asking for deltas are sent
+	// the full picture.
+	SnapshotInterval time.Duration `yaml:"snapshot_interval"`
+}
+
+func defaultStreamConfig() StreamConfig {
+	return StreamConfig{Backlog: 1000, ClientBuffer: 256, WhenFull: whenFullDisconnect, Heartbeat: 15 * time.Second, WriteTimeout: 10 * time.Second, BatchWindow: 250 * time.Millisecond, SnapshotInterval: time.Minute}
+}
+
+// Client represents a single SSE or WebSocket client connection.
 type Client struct {
-	ID   string
-	Send chan []byte
+	ID        string
+	Send      chan []byte
+	Connected time.Time
+	Dropped   atomic.Uint64 // Position updates discarded while it lagged
+	// Filter, when set, keeps the hub from queueing events the client would
+	// not send, so they cannot fill its buffer. It may be replaced at any
+	// time. The resume backlog is not filtered.
+	Filter atomic.Pointer[streamFilter]
+
+	// Batch, when set before registering, has position updates sent in
+	// aircraftBatch events.
+	Batch bool
+
+	// Resume, when set, receives the backlogged events after LastEventID as
+	// the client registers, before any new event is sent.
+	LastEventID uint64
+	Resume      chan [][]byte
 }
 
+// broadcastQueue is how many events may wait for the hub, so code holding mu
+// rarely blocks on a broadcast.
+const broadcastQueue = 1024
+
 // Hub maintains the set of active clients and broadcasts messages to the clients.
 type Hub struct {
 	clients    map[*Client]bool
 	broadcast  chan []byte
 	register   chan *Client
 	unregister chan *Client
+
+	clientCount atomic.Int32 // len(clients), readable outside run
+	ping        chan chan struct{}
+	inspect     chan chan []StreamClientStats
+	stop        chan chan struct{}
+	stopped     atomic.Bool // Set once shutdown has closed the clients
+
+	// Every event gets the next ID as an SSE "id:" line; the newest
+	// backlogSize events are kept for clients resuming with Last-Event-ID.
+	lastID      uint64
+	backlog     [][]byte
+	backlogSize int
+
+	clientBuffer int
+	whenFull     string
+	heartbeat    time.Duration
+	writeTimeout time.Duration
+	snapshot     time.Duration
+
+	// Position updates held for the batch clients; pendingID is the ID of
+	// the newest.
+	batchWindow time.Duration
+	pending     []*streamEvent
+	pendingID   uint64
+
+	// With a broker, local events are also published to the other
+	// replicas through outbox, and theirs arrive on relayed.
+	broker      Broker
+	outbox      chan []byte
+	relayed     chan []byte
+	disconnect  context.CancelFunc
+	unpublished atomic.Uint64
 }
 
-func newHub() *Hub {
+func newHub(cfg StreamConfig) *Hub {
 	return &Hub{
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		clients:    make(map[*Client]bool),
+		broadcast:    make(chan []byte, broadcastQueue),
+		register:     make(chan *Client),
+		unregister:   make(chan *Client),
+		ping:         make(chan chan struct{}),
+		inspect:      make(chan chan []StreamClientStats),
+		stop:         make(chan chan struct{}),
+		clients:      make(map[*Client]bool),
+		backlogSize:  cfg.Backlog,
+		clientBuffer: cfg.ClientBuffer,
+		whenFull:     cfg.WhenFull,
+		heartbeat:    cfg.Heartbeat,
+		writeTimeout: cfg.WriteTimeout,
+		snapshot:     cfg.SnapshotInterval,
+		batchWindow:  cfg.BatchWindow,
+	}
+}
+
+// eventsAfter returns the backlogged events with IDs above id. Only called
+// from run.
+func (h *Hub) eventsAfter(id uint64) [][]byte {
+	if id >= h.lastID {
+		return nil
 	}
+	n := h.lastID - id
+	if n > uint64(len(h.backlog)) {
+		n = uint64(len(h.backlog))
+	}
+	return append([][]byte(nil), h.backlog[uint64(len(h.backlog))-n:]...)
+}
+
+// shutdown sends every client a final server_shutdown event and closes it.
+// Clients registering afterwards are closed straight away.
+func (h *Hub) shutdown() {
+	done := make(chan struct{})
+	h.stop <- done
+	<-done
 }
 
 func (h *Hub) run() {
+	var flush <-chan time.Time
+	if h.batchWindow > 0 {
+		ticker := time.NewTicker(h.batchWindow)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
 	for {
 		select {
 		case client := <-h.register:
+			if client.Resume != nil {
+				client.Resume <- h.eventsAfter(client.LastEventID)
+			}
+			if h.stopped.Load() {
+				close(client.Send)
+				continue
+			}
 			h.clients[client] = true
+			h.clientCount.Store(int32(len(h.clients)))
 			log.Printf("Client registered: %s", client.ID)
 		case client := <-h.unregister:
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
 				close(client.Send)
+				h.clientCount.Store(int32(len(h.clients)))
 				log.Printf("Client unregistered: %s", client.ID)
 			}
-		case message := <-h.broadcast:
+		case reply := <-h.ping:
+			close(reply)
+		case reply := <-h.inspect:
+			reply <- h.clientStats()
+		case <-flush:
+			h.flushBatch()
+		case done := <-h.stop:
+			h.flushBatch()
+			h.stopped.Store(true)
+			final := []byte("id: " + strconv.FormatUint(h.nextID(), 10) + "\nevent: server_shutdown\ndata: {}\n\n")
 			for client := range h.clients {
 				select {
-				case client.Send <- message:
+				case client.Send <- final:
 				default:
-					log.Printf("Client %s send buffer full or disconnected. Unregistering.", client.ID)
-					delete(h.clients, client)
-					close(client.Send)
 				}
+				close(client.Send)
 			}
+			h.clients = make(map[*Client]bool)
+			h.clientCount.Store(0)
+			log.Printf("Closed all stream clients for shutdown")
+			close(done)
+		case message := <-h.broadcast:
+			h.publish(message)
+			h.send(message)
+		case message := <-h.relayed:
+			h.send(message)
 		}
 	}
 }
 
+// send numbers an event, adds it to the backlog and queues it for the
+// clients whose filters it passes. Only called from run.
+func (h *Hub) send(message []byte) {
+	message = append([]byte("id: "+strconv.FormatUint(h.nextID(), 10)+"\n"), message...)
+	if h.backlogSize > 0 {
+		if len(h.backlog) >= h.backlogSize {
+			h.backlog = h.backlog[1:]
+		}
+		h.backlog = append(h.backlog, message)
+	}
+	event := newStreamEvent(message)
+	if event.name != "aircraftUpdate" {
+		h.flushBatch()
+	} else if h.batchWindow > 0 {
+		h.pending = append(h.pending, event)
+		h.pendingID = h.lastID
+	}
+	for client := range h.clients {
+		if h.batched(client, event) {
+			continue
+		}
+		if f := client.Filter.Load(); f != nil && !f.matchesEvent(event) {
+			continue
+		}
+		if !h.deliver(client, message) {
+			log.Printf("Client %s send buffer full or disconnected. Unregistering.", client.ID)
+			delete(h.clients, client)
+			close(client.Send)
+		}
+	}
+	h.clientCount.Store(int32(len(h.clients)))
+}
+
+// annotateAircraft fills in what the server derives from an update's
+// position: the distance and bearing from the receiver, the height above the
+// terrain and the nearest airport.
+func annotateAircraft(aircraft Aircraft) Aircraft {
+	return nearAirport(aboveGround(fromReceiver(aircraft)))
+}
+
+// processAircraft runs an accepted update through the state registry, the
+// history store, SSE clients, anomaly detection and the alert criteria.
+// Only the detectors run under mu; everything before them has its own
+// locking, so concurrent feeders do not queue behind serialisation or I/O.
+func processAircraft(ctx context.Context, aircraft Aircraft) {
+	ctx, span := tracer.Start(ctx, "process", trace.WithAttributes(icaoAttr(aircraft.ICAO)))
+	defer span.End()
+	aircraft = annotateAircraft(aircraft)
+	// Queues the route lookup, so the route is known before most alerts.
+	lookupRoute(aircraft)
+	updateState(aircraft)
+	recordHistory(aircraft)
+	recordPositionMetrics(aircraft)
+	recordCoverage(aircraft)
+	if p := mqttClient.Load(); p != nil {
+		p.publishAircraft(aircraft)
+	}
+	_, broadcast := tracer.Start(ctx, "broadcast")
+	aircraftUpdateJSON, err := json.Marshal(aircraft)
+	if err != nil {
+		log.Printf("Error marshalling aircraft data for SSE update: %v", err)
+	} else {
+		hub.broadcast <- []byte("event: aircraftUpdate\ndata: " + string(aircraftUpdateJSON) + "\n\n")
+	}
+	endSpan(broadcast, err)
+
+	// The span includes the wait for mu, which is where contention shows.
+	ctx, evaluate := tracer.Start(ctx, "evaluate")
+	defer evaluate.End()
+	mu.Lock()
+	defer mu.Unlock()
+	evaluateUpdate(ctx, aircraft)
+}
+
+// receiveAircraft timestamps a live position report from the ingest APIs
+// and queues it for evaluation; source names the API for the message
+// statistics. It returns errPipelineFull if the update was refused.
+func receiveAircraft(ctx context.Context, aircraft Aircraft, source string) (err error) {
+	ctx, span := tracer.Start(ctx, "ingest", trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(icaoAttr(aircraft.ICAO), attribute.String("ingest.source", source)))
+	defer func() { endSpan(span, err) }()
+	aircraft.Timestamp = time.Now()
+	countMessage(source)
+	log.Printf("Received aircraft data: %+v", aircraft)
+	if err := enqueueAircraft(ctx, aircraft); err != nil {
+		return err
+	}
+	if rawLog != nil {
+		rawLog.write(aircraft)
+	}
+	return nil
+}
+
 func main() {
-	hub = newHub()
+	configPath := flag.String("config", "", "Path to YAML configuration file")
+	initDir := flag.String("init", "", "Write an example config, zones and criteria to this directory and exit")
+	replayFile := flag.String("replay", "", "Feed recorded aircraft updates (JSON lines) through the alert engine")
+	replaySpeed := flag.String("speed", "1x", "Replay speed multiplier, e.g. 10x")
+	vapidKeys := flag.Bool("vapid-keys", false, "Print a new VAPID key pair for web push notifications and exit")
+	genAPIKey := flag.Bool("gen-api-key", false, "Print a new random API key and exit")
+	hashPasswordFlag := flag.Bool("hash-password", false, "Read a password from stdin and print its bcrypt hash for auth.users, then exit")
+	flag.Parse()
+
+	if *initDir != "" {
+		if err := writeExampleConfig(*initDir); err != nil {
+			log.Fatalf("Error writing example configuration: %v", err)
+		}
+		return
+	}
+	if *vapidKeys {
+		private, public, err := webpush.GenerateVAPIDKeys()
+		if err != nil {
+			log.Fatalf("Error generating VAPID keys: %v", err)
+		}
+		fmt.Printf("vapid_public_key: %q\nvapid_private_key: %q\n", public, private)
+		return
+	}
+	if *genAPIKey {
+		key, err := newAPIKey()
+		if err != nil {
+			log.Fatalf("Error generating API key: %v", err)
+		}
+		fmt.Println(key)
+		return
+	}
+	if *hashPasswordFlag {
+		if err := hashPassword(); err != nil {
+			log.Fatalf("Error hashing password: %v", err)
+		}
+		return
+	}
+	speed, err := parseReplaySpeed(*replaySpeed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	activeConfig = cfg
+	configFile = *configPath
+	store, err = openStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Error opening %s storage: %v", cfg.Storage.Backend, err)
+	}
+	defer store.Close()
+	log.Printf("Using %s storage", cfg.Storage.Backend)
+	if cfg.AlertRetention.ArchiveFile != "" {
+		startAlertSpill(cfg.AlertRetention.ArchiveFile)
+	}
+	anomalyConfig = cfg.Anomaly
+	historyConfig = cfg.History
+	healthConfig = cfg.Health
+	stateConfig = cfg.State
+	validationConfig = cfg.Validation
+	routesConfig = cfg.Routes
+	receiver = cfg.Receiver
+	if cfg.Elevation.Dir != "" {
+		if elevation, err = newElevationData(cfg.Elevation); err != nil {
+			log.Fatalf("Error opening elevation data: %v", err)
+		}
+		log.Printf("Using elevation tiles from %s", cfg.Elevation.Dir)
+	}
+	auditLog.file = cfg.Audit.File
+	evaluationMode = cfg.EvaluationMode
+	if loaded, err := readZoneSources(cfg); err != nil {
+		log.Fatalf("Error loading zones: %v", err)
+	} else if loaded != nil {
+		zones = loaded
+		indexZones()
+		log.Printf("Loaded %d zones", len(zones))
+	}
+	if cfg.AirportsFile != "" {
+		if err := loadAirports(cfg.AirportsFile); err != nil {
+			log.Fatalf("Error loading airports: %v", err)
+		}
+		log.Printf("Loaded %d airports from %s", len(airports), cfg.AirportsFile)
+	} else if err := loadBundledAirports(); err != nil {
+		log.Fatalf("Error loading airports: %v", err)
+	}
+
+	if cfg.Tracing.Endpoint != "" {
+		if err := startTracing(cfg.Tracing); err != nil {
+			log.Fatalf("Error setting up tracing: %v", err)
+		}
+	}
+
+	hub = newHub(cfg.Streams)
+	if cfg.Cluster.Redis != "" {
+		broker, err := newRedisBroker(cfg.Cluster)
+		if err != nil {
+			log.Fatalf("Error setting up the cluster broker: %v", err)
+		}
+		hub.connect(broker)
+		log.Printf("Sharing stream events with other replicas on Redis channel %s", cfg.Cluster.Channel)
+	}
 	go hub.run()
+	startPipeline(cfg.Pipeline)
+	go pruneTracks()
+	go expireStates()
+	if cfg.State.DeadReckoning > 0 {
+		go broadcastEstimates()
+	}
+	go watchSignalLoss()
+	go pruneHistory()
+	go archiveAlerts(cfg.AlertRetention)
+	go runEscalations()
+	startNotifiers(cfg.Notifications)
+	go reloadOnSignal()
+	if cfg.RawLog.Dir != "" {
+		if rawLog, err = newRawLogger(cfg.RawLog); err != nil {
+			log.Fatalf("Error opening raw log: %v", err)
+		}
+		defer rawLog.Close()
+		log.Printf("Logging received updates to %s", cfg.RawLog.Dir)
+	}
+	if cfg.TFR.Enabled {
+		go runTFRPoller(cfg.TFR)
+		log.Printf("Polling %s for flight restrictions every %v", cfg.TFR.URL, cfg.TFR.Interval)
+	}
+	if cfg.Registry.Enabled {
+		go runRegistry(cfg.Registry)
+	}
+	if cfg.Routes.Enabled {
+		go runRouteLookups(cfg.Routes)
+	}
+	if cfg.Influx.URL != "" {
+		go runInfluxWriter(cfg.Influx)
+		log.Printf("Writing metrics to %s every %v", cfg.Influx.URL, cfg.Influx.Interval)
+	}
 
 	customJackedConfig := jacked.DefaultConfig()
 
@@ -101,129 +483,305 @@ func main() {
 	customJackedConfig.IdleTimeout = 30 * time.Minute
 
 	app := jacked.NewWithConfig(customJackedConfig)
+	limits := newRateLimits(cfg.RateLimit)
+	api := apiRouter{app: app, auth: cfg.Auth, limits: limits}
+	if cfg.AdminListen != "" {
+		api.admin = jacked.NewWithConfig(customJackedConfig)
+	}
+	if !cfg.Auth.enabled() {
+		log.Printf("Warning: no API keys or users configured, the API is open to anyone who can reach %s", cfg.Listen)
+	}
+	if len(cfg.Auth.Users) > 0 {
+		if sessions, err = newSessionManager(cfg.Auth); err != nil {
+			log.Fatalf("Error setting up sessions: %v", err)
+		}
+	}
 
-	alertCriteria = append(alertCriteria, AlertCriteria{Callsign: "TARGET1"})
-	alertCriteria = append(alertCriteria, AlertCriteria{ICAO: "AABBCC"})
+	// Persistent backends keep their criteria across restarts; the criteria
+	// file and built-in defaults only seed an empty store.
+	existing, err := store.ListCriteria()
+	if err != nil {
+		log.Fatalf("Error loading stored alert criteria: %v", err)
+	}
+	if len(existing) > 0 {
+		log.Printf("Using %d stored alert criteria", len(existing))
+	} else if cfg.CriteriaFile != "" {
+		if err := loadAlertCriteria(cfg.CriteriaFile); err != nil {
+			log.Fatalf("Error loading alert criteria: %v", err)
+		}
+		log.Printf("Loaded alert criteria from %s", cfg.CriteriaFile)
+	} else {
+		for _, criterion := range []AlertCriteria{
+			{Callsign: "TARGET1", Enabled: true},
+			{ICAO: "AABBCC", Enabled: true},
+		} {
+			if _, err := addAlertCriterion(criterion); err != nil {
+				log.Fatalf("Error adding default alert criteria: %v", err)
+			}
+		}
+	}
 
-	staticDir := "./public"
+	staticDir := cfg.StaticDir
 
 	app.GET("/", func(c *jacked.Context) error {
 		setSecurityHeaders(c.Response)
-		start := time.Now()
-		filePath := staticDir + "/index.html"
-		log.Printf("Serving file for /: %s", filePath)
-		http.ServeFile(c.Response, c.Request, filePath)
-		log.Printf("Served file for / in %v", time.Since(start))
+		http.ServeFile(c.Response, c.Request, staticDir+"/index.html")
 		return nil
 	})
 
 	staticPath := "/static/*filepath"
 	app.GET(staticPath, func(c *jacked.Context) error {
-		start := time.Now()
-		log.Printf("Serving static file for path: %s", c.Request.URL.Path)
 		fs := http.StripPrefix("/static/", http.FileServer(http.Dir(staticDir)))
 		fs.ServeHTTP(c.Response, c.Request)
-		log.Printf("Served static file for path %s in %v", c.Request.URL.Path, time.Since(start))
 		return nil
 	})
 
-	app.POST("/api/aircraft", func(c *jacked.Context) error {
+	// Probes stay outside /api so orchestrators need no credentials and are
+	// never rate limited.
+	app.GET("/healthz", handleHealthz)
+	app.GET("/readyz", handleReadyz)
+	if api.admin != nil {
+		api.admin.GET("/healthz", handleHealthz)
+		api.admin.GET("/readyz", handleReadyz)
+	}
+
+	api.GET("/api/v1/openapi.json", handleOpenAPI)
+	if sessions != nil {
+		api.POST("/api/v1/login", handleLogin)
+		api.POST("/api/v1/token/refresh", handleRefreshToken)
+		api.POST("/api/v1/logout", handleLogout)
+	}
+	if cfg.SwaggerUI {
+		api.GET("/api/v1/docs", handleAPIDocs)
+	}
+	api.GET("/api/v1/aircraft", handleListAircraft)
+	api.POST("/api/v1/aircraft", func(c *jacked.Context) error {
 		var aircraft Aircraft
-		if err := json.NewDecoder(c.Request.Body).Decode(&aircraft); err != nil {
-			log.Printf("Error decoding aircraft data: %v", err)
-			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid aircraft data"})
+		body, err := readBody(c, maxAircraftBody)
+		if err == nil {
+			err = json.Unmarshal(body, &aircraft)
 		}
-		defer c.Request.Body.Close()
-
-		aircraft.Timestamp = time.Now()
-		log.Printf("Received aircraft data: %+v", aircraft)
-
-		mu.Lock()
-		aircraftUpdateJSON, err := json.Marshal(aircraft)
 		if err != nil {
-			log.Printf("Error marshalling aircraft data for SSE update: %v", err)
-		} else {
-			hub.broadcast <- []byte("event: aircraftUpdate\ndata: " + string(aircraftUpdateJSON) + "\n\n")
+			return bodyError(c, err, "Invalid aircraft data")
 		}
 
-		for _, criterion := range alertCriteria {
-			match := false
-			if criterion.ICAO != "" && criterion.ICAO == aircraft.ICAO {
-				match = true
-			}
-			if criterion.Callsign != "" && criterion.Callsign == aircraft.Callsign {
-				match = true
-			}
-
-			if match {
-				alert := Alert{
-					Aircraft:  aircraft,
-					Message:   "Monitored aircraft detected: " + aircraft.Callsign + " (" + aircraft.ICAO + ")",
-					Criteria:  criterion,
-					Timestamp: time.Now(),
-				}
-				triggeredAlerts = append(triggeredAlerts, alert)
-				log.Printf("ALERT: %+v", alert)
-
-				alertJSON, err := json.Marshal(alert)
-				if err != nil {
-					log.Printf("Error marshalling alert for SSE: %v", err)
-				} else {
-					hub.broadcast <- []byte("event: alert\ndata: " + string(alertJSON) + "\n\n")
-				}
-			}
+		problems, ok := checkAircraft(&aircraft)
+		if !ok {
+			logRequestf(c.Request, "Rejected aircraft data for %q: %s", aircraft.ICAO, fieldErrorSummary(problems))
+			return c.JSON(http.StatusBadRequest, map[string]any{"error": "Invalid aircraft data", "fields": problems})
 		}
-		mu.Unlock()
-
-		return c.JSON(http.StatusOK, map[string]string{"status": "received"})
+		if err := receiveAircraft(requestTraceContext(c.Request), aircraft, sourceHTTP); err != nil {
+			c.Response.Header().Set("Retry-After", "1")
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Update not accepted: " + err.Error()})
+		}
+		return c.JSON(http.StatusOK, ingestStatus{Status: "received", Warnings: problems})
 	})
 
-	app.GET("/api/alerts", func(c *jacked.Context) error {
-		mu.Lock()
-		defer mu.Unlock()
-		alertsToReturn := make([]Alert, len(triggeredAlerts))
-		copy(alertsToReturn, triggeredAlerts)
-		return c.JSON(http.StatusOK, alertsToReturn)
-	})
+	api.GET("/api/v1/alerts", handleListAlerts)
+	api.DELETE("/api/v1/alerts", handleClearAlerts)
+	api.DELETE("/api/v1/alerts/:id", handleDeleteAlert)
 
-	app.POST("/api/alert-criteria", func(c *jacked.Context) error {
-		var criterion AlertCriteria
-		if err := json.NewDecoder(c.Request.Body).Decode(&criterion); err != nil {
-			log.Printf("Error decoding alert criteria: %v", err)
-			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid criteria data"})
+	api.POST("/api/v1/alert-criteria", func(c *jacked.Context) error {
+		criterion := AlertCriteria{Enabled: true}
+		body, err := readBody(c, maxCriterionBody)
+		if err == nil {
+			err = json.Unmarshal(body, &criterion)
+		}
+		if err != nil {
+			return bodyError(c, err, "Invalid criteria data")
 		}
-		defer c.Request.Body.Close()
 
 		mu.Lock()
-		alertCriteria = append(alertCriteria, criterion)
+		if err := validateCriterion(&criterion); err != nil {
+			mu.Unlock()
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		criterion, err = addAlertCriterion(criterion)
 		mu.Unlock()
+		if err != nil {
+			return storeError(c, err)
+		}
 
-		log.Printf("Added new alert criterion: %+v", criterion)
+		logRequestf(c.Request, "Added new alert criterion: %+v", criterion)
 		return c.JSON(http.StatusCreated, criterion)
 	})
 
-	app.GET("/api/events", func(c *jacked.Context) error {
+	api.GET("/api/v1/alert-criteria", handleListAlertCriteria)
+	api.GET("/api/v1/alert-criteria/:id", handleGetAlertCriterion)
+	api.PUT("/api/v1/alert-criteria/:id", handleUpdateAlertCriterion)
+	api.PATCH("/api/v1/alert-criteria/:id", handlePatchAlertCriterion)
+	api.DELETE("/api/v1/alert-criteria/:id", handleDeleteAlertCriterion)
+	api.GET("/api/v1/alert-criteria/:id/feedback", handleAlertCriterionFeedback)
+	api.GET("/api/v1/presets", handleListPresets)
+	api.POST("/api/v1/alert-criteria/presets/:name", handleApplyPreset)
+	api.POST("/api/v1/alert-criteria/import", handleImportCriteria)
+	api.POST("/api/v1/alert-criteria/test", handleTestAlertCriterion)
+	api.POST("/api/v1/alerts/:id/false-positive", handleMarkFalsePositive)
+	api.POST("/api/v1/alerts/:id/ack", handleAckAlert)
+	api.GET("/api/v1/escalation-policies", handleListEscalationPolicies)
+	api.POST("/api/v1/escalation-policies", handleCreateEscalationPolicy)
+	api.PUT("/api/v1/escalation-policies/:id", handleUpdateEscalationPolicy)
+	api.DELETE("/api/v1/escalation-policies/:id", handleDeleteEscalationPolicy)
+	api.GET("/api/v1/zones/:name/positions", handleZonePositions)
+	api.GET("/api/v1/tfrs", handleTFRs)
+	api.GET("/api/v1/aircraft/:icao", handleAircraftDetail)
+	api.DELETE("/api/v1/aircraft/:icao", handleDeleteAircraft)
+	api.GET("/api/v1/aircraft/:icao/history", handleAircraftHistory)
+	api.GET("/api/v1/aircraft/:icao/track", handleAircraftTrack)
+	api.GET("/api/v1/alerts/:id/history", handleAlertHistory)
+	api.GET("/api/v1/search", handleSearch)
+	api.GET("/api/v1/stats", handleStats)
+	api.GET("/api/v1/coverage", handleCoverage)
+	api.DELETE("/api/v1/coverage", handleResetCoverage)
+	api.GET("/api/v1/notifiers", handleListNotifiers)
+	api.GET("/api/v1/push/key", handlePushKey)
+	api.GET("/api/v1/push/subscriptions", handleListPushSubscriptions)
+	api.POST("/api/v1/push/subscriptions", handleSubscribePush)
+	api.DELETE("/api/v1/push/subscriptions/:id", handleDeletePushSubscription)
+	api.GET("/api/v1/notifications/deliveries", handleListDeliveries)
+	api.POST("/api/v1/notifications/deliveries/:id/retry", handleRetryDelivery)
+	api.DELETE("/api/v1/notifications/deliveries/:id", handleDeleteDelivery)
+	api.GET("/api/v1/audit", handleListAudit)
+	api.GET("/api/v1/streams", handleListStreams)
+	api.GET("/api/v1/backup", handleBackup)
+	api.POST("/api/v1/restore", handleRestore)
+	api.POST("/api/v1/admin/reload", handleReload)
+	if cfg.Diagnostics.API {
+		api.GET("/api/v1/debug/*path", handleDiagnostics)
+	}
+
+	api.GET("/api/v1/events/poll", handlePollEvents)
+	api.GET("/api/v1/ws", handleWebSocket)
+	api.GET("/api/v1/graphql", handleGraphQL)
+	api.POST("/api/v1/graphql", handleGraphQL)
+	api.GET("/api/v1/events", func(c *jacked.Context) error {
 		c.Response.Header().Set("Content-Type", "text/event-stream")
 		c.Response.Header().Set("Cache-Control", "no-cache")
 		c.Response.Header().Set("Connection", "keep-alive")
 		c.Response.Header().Set("Access-Control-Allow-Origin", "*")
 
-		flusher, ok := c.Response.(http.Flusher)
-		if !ok {
+		if _, ok := c.Response.(http.Flusher); !ok {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Streaming unsupported!"})
 		}
 
-		client := &Client{
-			ID:   c.Request.RemoteAddr,
-			Send: make(chan []byte, 256),
+		var alertsSince time.Time
+		if v := c.Request.URL.Query().Get("alerts_since"); v != "" {
+			t, err := parseTimeParam(v)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid alerts_since timestamp"})
+			}
+			alertsSince = t
+		}
+		filter, err := parseStreamFilter(c.Request.URL.Query())
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+
+		client := hub.newClient(c.Request.RemoteAddr)
+		client.Batch = c.Request.URL.Query().Get("batch") == "true"
+		// Browsers reconnecting after an error send the ID of the last event
+		// they saw; the hub replays what they missed from its backlog.
+		lastEventID := c.Request.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Request.URL.Query().Get("last_event_id")
+		}
+		if lastEventID != "" {
+			id, err := strconv.ParseUint(lastEventID, 10, 64)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid Last-Event-ID"})
+			}
+			client.LastEventID = id
+			client.Resume = make(chan [][]byte, 1)
+		}
+		client.Filter.Store(filter)
+
+		// Snapshot missed alerts and register under the same lock so no alert
+		// fired in between is lost or delivered twice. A resumed stream
+		// already gets them from the backlog.
+		var missed []Alert
+		mu.Lock()
+		if !alertsSince.IsZero() && client.Resume == nil {
+			alerts, err := store.ListAlerts()
+			if err != nil {
+				mu.Unlock()
+				return storeError(c, err)
+			}
+			for _, alert := range alerts {
+				if alert.Timestamp.After(alertsSince) {
+					missed = append(missed, alert)
+				}
+			}
 		}
 		hub.register <- client
+		mu.Unlock()
+
+		// send writes and flushes a frame within the write timeout, which
+		// replaces the server's for the life of the stream. Clients asking
+		// for deltas get position updates as changes only.
+		var deltas *deltaEncoder
+		var snapshot <-chan time.Time
+		if c.Request.URL.Query().Get("delta") == "true" {
+			deltas = newDeltaEncoder()
+			ticker := time.NewTicker(hub.snapshot)
+			defer ticker.Stop()
+			snapshot = ticker.C
+		}
+		rc := http.NewResponseController(c.Response)
+		send := func(message []byte) error {
+			if deltas != nil {
+				if message = deltas.encode(message); message == nil {
+					return nil
+				}
+			}
+			rc.SetWriteDeadline(time.Now().Add(hub.writeTimeout))
+			if _, err := c.Response.Write(message); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+
+		if client.Resume != nil {
+			resumed := 0
+			for _, message := range <-client.Resume {
+				if event, data := parseSSEFrame(message); !filter.matches(event, data) {
+					continue
+				}
+				if err := send(message); err != nil {
+					log.Printf("SSE: Error writing backlog to client %s: %v", client.ID, err)
+					hub.unregister <- client
+					return nil
+				}
+				resumed++
+			}
+			log.Printf("SSE: Resumed client %s after event %d with %d events", client.ID, client.LastEventID, resumed)
+		}
+
+		for _, alert := range missed {
+			alertJSON, err := json.Marshal(alert)
+			if err != nil {
+				log.Printf("Error marshalling alert for SSE catch-up: %v", err)
+				continue
+			}
+			if !filter.matches("alert", alertJSON) {
+				continue
+			}
+			if err := send([]byte("event: alert\ndata: " + string(alertJSON) + "\n\n")); err != nil {
+				log.Printf("SSE: Error writing catch-up to client %s: %v", client.ID, err)
+				hub.unregister <- client
+				return nil
+			}
+		}
+		if len(missed) > 0 {
+			log.Printf("SSE: Sent %d missed alerts to client %s", len(missed), client.ID)
+		}
 
 		defer func() {
 			hub.unregister <- client
 			log.Printf("SSE client %s connection closed (handler defer).", client.ID)
 		}()
 
+		heartbeat := time.NewTicker(hub.heartbeat)
+		defer heartbeat.Stop()
 		log.Printf("SSE: Client %s entering send loop.", client.ID)
 		for {
 			select {
@@ -232,12 +790,20 @@ func main() {
 					log.Printf("SSE: Client %s send channel closed. Exiting loop.", client.ID)
 					return nil
 				}
-				_, err := c.Response.Write(message)
-				if err != nil {
+				if err := send(message); err != nil {
 					log.Printf("SSE: Error writing to client %s: %v. Exiting loop.", client.ID, err)
 					return nil
 				}
-				flusher.Flush()
+			case <-snapshot:
+				if err := send(deltas.snapshot(allStates(), client.Filter.Load())); err != nil {
+					log.Printf("SSE: Error sending snapshot to client %s: %v. Exiting loop.", client.ID, err)
+					return nil
+				}
+			case <-heartbeat.C:
+				if err := send([]byte(": keepalive\n\n")); err != nil {
+					log.Printf("SSE: Error sending heartbeat to client %s: %v. Exiting loop.", client.ID, err)
+					return nil
+				}
 			case <-c.Request.Context().Done():
 				log.Printf("SSE: Client %s context done. Exiting loop.", client.ID)
 				return nil
@@ -245,19 +811,89 @@ func main() {
 		}
 	})
 
-	listenAddr := ":8080"
+	listenAddr := cfg.Listen
+	server := &http.Server{
+		Addr:         listenAddr,
+		Handler:      logRequests(recoverPanics(app), limits),
+		WriteTimeout: customJackedConfig.WriteTimeout,
+		IdleTimeout:  customJackedConfig.IdleTimeout,
+		// Bodies get their own deadline from the handlers that read them.
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	servers := []*http.Server{server}
+	if cfg.TLS.enabled() {
+		tlsCfg, redirect, err := newTLS(cfg.TLS, listenAddr)
+		if err != nil {
+			log.Fatalf("Error setting up TLS: %v", err)
+		}
+		server.TLSConfig = tlsCfg
+		tlsEnabled = true
+		log.Printf("Serving HTTPS on %s", listenAddr)
+		if cfg.TLS.HTTPListen != "" {
+			plain := &http.Server{Addr: cfg.TLS.HTTPListen, Handler: redirect, ReadHeaderTimeout: 10 * time.Second}
+			servers = append(servers, plain)
+			go func() {
+				if err := plain.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Fatal(err)
+				}
+			}()
+			log.Printf("Redirecting plain HTTP on %s to HTTPS", cfg.TLS.HTTPListen)
+		}
+	}
 	log.Printf("Aircraft Alert Server starting on %s (with custom timeouts for SSE)", listenAddr)
-
 	go func() {
-		if err := app.ListenAndServe(listenAddr); err != nil {
+		var err error
+		if tlsEnabled {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal(err)
 		}
 	}()
 
+	if api.admin != nil {
+		admin := &http.Server{
+			Addr:              cfg.AdminListen,
+			Handler:           logRequests(recoverPanics(api.admin), limits),
+			WriteTimeout:      customJackedConfig.WriteTimeout,
+			IdleTimeout:       customJackedConfig.IdleTimeout,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		servers = append(servers, admin)
+		go func() {
+			if err := admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+		log.Printf("Serving the control plane on %s", cfg.AdminListen)
+	}
+	if cfg.Diagnostics.Listen != "" {
+		servers = append(servers, serveDiagnostics(cfg.Diagnostics.Listen))
+	}
+
+	var grpcSrv *grpc.Server
+	if cfg.GRPCListen != "" {
+		grpcSrv = serveGRPC(cfg.GRPCListen, cfg.Auth, limits, server.TLSConfig)
+	}
+
+	if *replayFile != "" {
+		log.Printf("Replaying %s at %gx", *replayFile, speed)
+		go func() {
+			if err := replayUpdates(*replayFile, speed); err != nil {
+				log.Printf("Replay of %s failed: %v", *replayFile, err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	shutdown(ctx, servers, grpcSrv)
 	log.Println("Server exiting")
 }
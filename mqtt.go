@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	registerNotifier("mqtt", func(node *yaml.Node) ([]Notifier, error) {
+		cfg, err := decodeNotifierConfig[MQTTConfig](node)
+		if err != nil {
+			return nil, err
+		}
+		return []Notifier{&mqttPublisher{cfg: cfg}}, nil
+	})
+}
+
+// MQTTConfig publishes alerts to <topic_prefix>/alerts and, with
+// PublishAircraft, every update to <topic_prefix>/aircraft/<icao>.
+type MQTTConfig struct {
+	Broker          string `yaml:"broker"` // e.g. tcp://localhost:1883 or ssl://host:8883
+	ClientID        string `yaml:"client_id"`
+	Username        string `yaml:"username"`
+	Password        string `yaml:"password"`
+	TopicPrefix     string `yaml:"topic_prefix"` // Default aircraft-alert
+	QoS             byte   `yaml:"qos"`
+	RetainAircraft  bool   `yaml:"retain_aircraft"` // Keep the last position per aircraft on the broker
+	PublishAircraft bool   `yaml:"publish_aircraft"`
+	Retries         *int   `yaml:"retries"`
+}
+
+func (c *MQTTConfig) validate() error {
+	if c.Broker == "" {
+		return errors.New("mqtt broker is required")
+	}
+	if c.QoS > 2 {
+		return errors.New("mqtt qos must be 0, 1 or 2")
+	}
+	if c.TopicPrefix == "" {
+		c.TopicPrefix = "aircraft-alert"
+	}
+	if c.ClientID == "" {
+		c.ClientID = "aircraft-alert-" + newID()
+	}
+	return nil
+}
+
+// mqttPublisher is the MQTT notifier; it also publishes aircraft updates.
+type mqttPublisher struct {
+	cfg    MQTTConfig
+	client mqtt.Client
+}
+
+// mqttClient holds the active publisher, nil when MQTT is not configured.
+var mqttClient atomic.Pointer[mqttPublisher]
+
+// start connects in the background and makes p the active publisher; the
+// client reconnects on its own if the broker goes away.
+func (p *mqttPublisher) start() {
+	cfg := p.cfg
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Printf("MQTT: connection to %s lost: %v", cfg.Broker, err)
+		}).
+		SetOnConnectHandler(func(mqtt.Client) {
+			log.Printf("MQTT: connected to %s", cfg.Broker)
+		})
+	p.client = mqtt.NewClient(opts)
+	p.client.Connect()
+	mqttClient.Store(p)
+}
+
+// stop disconnects, waiting briefly for publishes in flight.
+func (p *mqttPublisher) stop() {
+	mqttClient.CompareAndSwap(p, nil)
+	p.client.Disconnect(250)
+}
+
+func (p *mqttPublisher) Name() string  { return "mqtt" }
+func (p *mqttPublisher) retries() *int { return p.cfg.Retries }
+
+// Health reports whether the broker connection is up.
+func (p *mqttPublisher) Health() error {
+	if p.client == nil || !p.client.IsConnectionOpen() {
+		return fmt.Errorf("not connected to %s", p.cfg.Broker)
+	}
+	return nil
+}
+
+func (p *mqttPublisher) Notify(ctx context.Context, alert Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return permanentError{err}
+	}
+	token := p.client.Publish(p.cfg.TopicPrefix+"/alerts", p.cfg.QoS, false, payload)
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return errors.New("publish timed out")
+	}
+}
+
+// publishAircraft publishes an update without waiting for delivery; updates
+// are dropped while the broker is unreachable.
+func (p *mqttPublisher) publishAircraft(aircraft Aircraft) {
+	if !p.cfg.PublishAircraft || !p.client.IsConnectionOpen() {
+		return
+	}
+	payload, err := json.Marshal(aircraft)
+	if err != nil {
+		log.Printf("MQTT: error marshalling aircraft update: %v", err)
+		return
+	}
+	topic := fmt.Sprintf("%s/aircraft/%s", p.cfg.TopicPrefix, aircraft.ICAO)
+	p.client.Publish(topic, p.cfg.QoS, p.cfg.RetainAircraft, payload)
+}
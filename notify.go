@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NotificationsConfig lists the channels alerts are pushed to in addition to
+// SSE clients. Every key other than routes, default_channels, channels and
+// quiet_hours configures the notifier registered under that name, e.g. slack
+// or webhooks.
+type NotificationsConfig struct {
+	// Routes pick channels for alerts by tag and severity. Alerts no route
+	// or criterion "notify" list applies to go to DefaultChannels, or to
+	// every channel when that is empty.
+	Routes          []NotificationRoute `yaml:"routes"`
+	DefaultChannels []string            `yaml:"default_channels"`
+
+	// Channels sets rate limits and digest mode per channel name.
+	Channels map[string]ChannelPolicy `yaml:"channels"`
+	// QuietHours holds back non-critical notifications on every channel
+	// without quiet hours of its own.
+	QuietHours *QuietHours `yaml:"quiet_hours"`
+
+	sections  []notifierSection    // Notifier sections in file order
+	notifiers []configuredNotifier // Built from sections by validate
+}
+
+// notifierSection is the raw configuration of one registered notifier kind.
+type notifierSection struct {
+	kind string
+	node *yaml.Node
+}
+
+// configuredNotifier is a notifier built from the configuration.
+type configuredNotifier struct {
+	kind     string
+	notifier Notifier
+}
+
+// UnmarshalYAML keeps the notifier sections as nodes; validate decodes them
+// with the registered factories.
+func (c *NotificationsConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("notifications must be a mapping")
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		var err error
+		switch key {
+		case "routes":
+			err = value.Decode(&c.Routes)
+		case "default_channels":
+			err = value.Decode(&c.DefaultChannels)
+		case "channels":
+			err = value.Decode(&c.Channels)
+		case "quiet_hours":
+			err = value.Decode(&c.QuietHours)
+		default:
+			if value.Tag != "!!null" {
+				c.sections = append(c.sections, notifierSection{kind: key, node: value})
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("notifications.%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// MarshalYAML writes the configuration back in the shape it was read, so
+// backups keep the notifier sections.
+func (c NotificationsConfig) MarshalYAML() (interface{}, error) {
+	out := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value interface{}) error {
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return err
+		}
+		out.Content = append(out.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &node)
+		return nil
+	}
+	for _, s := range c.sections {
+		out.Content = append(out.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: s.kind}, s.node)
+	}
+	if len(c.Routes) > 0 {
+		if err := add("routes", c.Routes); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.DefaultChannels) > 0 {
+		if err := add("default_channels", c.DefaultChannels); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.Channels) > 0 {
+		if err := add("channels", c.Channels); err != nil {
+			return nil, err
+		}
+	}
+	if c.QuietHours != nil {
+		if err := add("quiet_hours", c.QuietHours); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// validate builds the configured notifiers and checks their names, the
+// channel policies and the routing rules.
+func (c *NotificationsConfig) validate() error {
+	c.notifiers = nil
+	names := map[string]bool{}
+	for _, s := range c.sections {
+		factory, ok := notifierRegistry[s.kind]
+		if !ok {
+			return fmt.Errorf("unknown notifier %q", s.kind)
+		}
+		built, err := factory(s.node)
+		if err != nil {
+			return fmt.Errorf("notifications.%s: %w", s.kind, err)
+		}
+		for _, n := range built {
+			if names[n.Name()] {
+				return fmt.Errorf("duplicate notification channel name %q", n.Name())
+			}
+			names[n.Name()] = true
+			c.notifiers = append(c.notifiers, configuredNotifier{kind: s.kind, notifier: n})
+		}
+	}
+	if c.QuietHours != nil {
+		if err := c.QuietHours.validate(); err != nil {
+			return err
+		}
+	}
+	for name, policy := range c.Channels {
+		if !names[name] {
+			return fmt.Errorf("notification policy for unknown channel %q", name)
+		}
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("channel %s: %w", name, err)
+		}
+	}
+	return c.validateRoutes(names)
+}
+
+// notifier returns the configured notifier with the given channel name, or
+// nil if there is none.
+func (c NotificationsConfig) notifier(name string) Notifier {
+	for _, n := range c.notifiers {
+		if n.notifier.Name() == name {
+			return n.notifier
+		}
+	}
+	return nil
+}
+
+// Notifier delivers alerts to an external channel. Implementations live in
+// their own notify_*.go file and register a factory from init.
+type Notifier interface {
+	// Name identifies the channel in routing, logs and the API.
+	Name() string
+	// Notify delivers a single alert. Returning a permanentError stops
+	// further retries.
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Optional notifier behaviour, detected by the delivery queue.
+type (
+	// notifierRetries reports the configured retry count, nil for the
+	// default.
+	notifierRetries interface{ retries() *int }
+	// notifierStarter is started once before the first delivery, e.g. to
+	// connect.
+	notifierStarter interface{ start() }
+	// notifierStopper is stopped after the last delivery, when the server
+	// shuts down or a reload replaces it.
+	notifierStopper interface{ stop() }
+	// notifierHealth reports problems beyond failed deliveries, such as a
+	// lost connection.
+	notifierHealth interface{ Health() error }
+)
+
+// notifierFactory validates a configuration section and builds the
+// notifiers it describes.
+type notifierFactory func(node *yaml.Node) ([]Notifier, error)
+
+// notifierRegistry maps configuration keys to notifier factories.
+var notifierRegistry = map[string]notifierFactory{}
+
+// registerNotifier makes a notifier kind configurable under key.
+func registerNotifier(key string, factory notifierFactory) {
+	if _, dup := notifierRegistry[key]; dup {
+		panic("notifier " + key + " registered twice")
+	}
+	notifierRegistry[key] = factory
+}
+
+// decodeNotifierConfig decodes and validates the configuration of a notifier
+// kind; validate may fill in defaults.
+func decodeNotifierConfig[T any, PT interface {
+	*T
+	validate() error
+}](node *yaml.Node) (T, error) {
+	var cfg T
+	if err := node.Decode(&cfg); err != nil {
+		return cfg, err
+	}
+	err := PT(&cfg).validate()
+	return cfg, err
+}
+
+// permanentError marks a delivery failure that retrying will not fix, such
+// as a rejected request.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+
+// startNotifiers starts a delivery queue for every configured channel.
+func startNotifiers(cfg NotificationsConfig) {
+	notifiers = newNotifierQueues(cfg)
+	runNotifiers(notifiers)
+	if len(notifiers) > 0 {
+		log.Printf("Started %d alert notifiers", len(notifiers))
+	}
+}
+
+// notifyClient is shared by the HTTP-based notifiers.
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// postJSON posts v as JSON to url with the extra headers. Client errors
+// other than 429 are reported as permanent.
+func postJSON(ctx context.Context, url string, v interface{}, headers map[string]string) error {
+	return sendJSON(ctx, http.MethodPost, url, v, headers)
+}
+
+// sendJSON is postJSON with a choice of method.
+func sendJSON(ctx context.Context, method, url string, v interface{}, headers map[string]string) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return permanentError{err}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return doNotifyRequest(req)
+}
+
+// doNotifyRequest sends req and maps the response status to an error.
+func doNotifyRequest(req *http.Request) error {
+	resp, err := notifyClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return responseError(resp)
+}
+
+// responseError maps the response status to an error and closes the body.
+// Client errors other than 429 are permanent.
+func responseError(resp *http.Response) error {
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	err := errors.New(resp.Status)
+	if snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512)); len(bytes.TrimSpace(snippet)) > 0 {
+		err = fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(snippet))
+	}
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return permanentError{err}
+	}
+	return err
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alert category of digest notifications.
+const categoryDigest = "digest"
+
+// maxDigestLines caps the alerts listed individually in a digest message.
+const maxDigestLines = 20
+
+// ChannelPolicy protects a channel from alert storms. With RateLimit, at
+// most that many notifications go out per RateWindow and the overflow is
+// sent as one digest when the window frees up. With Digest, all alerts
+// arriving within that period are coalesced into a single summary.
+type ChannelPolicy struct {
+	RateLimit  int           `yaml:"rate_limit"`
+	RateWindow time.Duration `yaml:"rate_window"` // Default 1m
+	Digest     time.Duration `yaml:"digest"`
+	// QuietHours replaces the global quiet hours for this channel.
+	QuietHours *QuietHours `yaml:"quiet_hours"`
+}
+
+func (p ChannelPolicy) validate() error {
+	if p.RateLimit < 0 || p.RateWindow < 0 || p.Digest < 0 {
+		return errors.New("rate_limit, rate_window and digest must not be negative")
+	}
+	if p.QuietHours != nil {
+		return p.QuietHours.validate()
+	}
+	return nil
+}
+
+// channelGate applies a ChannelPolicy and quiet hours in front of a
+// notifier queue.
+type channelGate struct {
+	q      *notifierQueue
+	policy ChannelPolicy
+	quiet  *QuietHours // Nil if none
+
+	mu      sync.Mutex
+	sent    []time.Time // Sends within the current rate window
+	pending []Alert     // Alerts held back for the next digest
+	timer   *time.Timer
+	due     time.Time // When timer fires
+}
+
+func newChannelGate(q *notifierQueue, policy ChannelPolicy, quiet *QuietHours) *channelGate {
+	if policy.RateWindow == 0 {
+		policy.RateWindow = time.Minute
+	}
+	return &channelGate{q: q, policy: policy, quiet: quiet}
+}
+
+// admit passes the alert on, or holds it for a digest when digest mode is
+// on, quiet hours hold it back or the rate limit is exhausted.
+func (g *channelGate) admit(alert Alert) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	if g.policy.Digest > 0 {
+		g.hold(alert, g.policy.Digest)
+		return
+	}
+	if alert.Severity != severityCritical {
+		if until := g.quiet.remaining(now); until > 0 {
+			g.hold(alert, until)
+			return
+		}
+	}
+	if wait := g.rateWait(now); wait > 0 {
+		g.hold(alert, wait)
+		return
+	}
+	g.sent = append(g.sent, now)
+	g.q.enqueue(alert)
+}
+
+// rateWait returns how long until another notification may be sent, zero
+// if one may go now. Callers must hold g.mu.
+func (g *channelGate) rateWait(now time.Time) time.Duration {
+	if g.policy.RateLimit == 0 {
+		return 0
+	}
+	cutoff := now.Add(-g.policy.RateWindow)
+	i := 0
+	for i < len(g.sent) && !g.sent[i].After(cutoff) {
+		i++
+	}
+	g.sent = g.sent[i:]
+	if len(g.sent) < g.policy.RateLimit {
+		return 0
+	}
+	return g.sent[0].Add(g.policy.RateWindow).Sub(now)
+}
+
+// hold buffers the alert, arming the flush timer unless it already fires
+// sooner. Callers must hold g.mu.
+func (g *channelGate) hold(alert Alert, after time.Duration) {
+	g.pending = append(g.pending, alert)
+	g.arm(after)
+}
+
+// arm schedules a flush after the given time unless one is due sooner.
+// Callers must hold g.mu.
+func (g *channelGate) arm(after time.Duration) {
+	due := time.Now().Add(after)
+	if g.timer != nil && !due.Before(g.due) {
+		return
+	}
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+	g.timer, g.due = time.AfterFunc(after, g.flush), due
+}
+
+// flush sends the held alerts, as a digest if there is more than one.
+// During quiet hours only the critical ones go out.
+func (g *channelGate) flush() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.timer = nil
+	if len(g.pending) == 0 {
+		return
+	}
+	now := time.Now()
+	if wait := g.rateWait(now); wait > 0 {
+		g.arm(wait)
+		return
+	}
+	send := g.pending
+	g.pending = nil
+	if until := g.quiet.remaining(now); until > 0 {
+		var critical []Alert
+		for _, a := range send {
+			if a.Severity == severityCritical {
+				critical = append(critical, a)
+			} else {
+				g.pending = append(g.pending, a)
+			}
+		}
+		send = critical
+		if len(g.pending) > 0 {
+			g.arm(until)
+		}
+	}
+	if len(send) == 0 {
+		return
+	}
+	alert := send[0]
+	if len(send) > 1 {
+		alert = digestAlert(send, now)
+	}
+	g.sent = append(g.sent, now)
+	g.q.enqueue(alert)
+}
+
+// park moves the held alerts into the delivery queue, due when the gate would
+// have flushed them, so a restart does not lose them.
+func (g *channelGate) park() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	if len(g.pending) == 0 {
+		return
+	}
+	alert := g.pending[0]
+	if len(g.pending) > 1 {
+		alert = digestAlert(g.pending, time.Now())
+	}
+	g.q.enqueueAt(alert, g.due)
+	g.pending = nil
+}
+
+// digestAlert summarises several alerts in one, carrying the highest
+// severity among them.
+func digestAlert(alerts []Alert, now time.Time) Alert {
+	severity := severityInfo
+	lines := make([]string, 0, min(len(alerts), maxDigestLines)+1)
+	for i, a := range alerts {
+		if severityRank[a.Severity] > severityRank[severity] {
+			severity = a.Severity
+		}
+		if i < maxDigestLines {
+			lines = append(lines, fmt.Sprintf("%s %s", a.Timestamp.Format("15:04:05"), a.Message))
+		}
+	}
+	if len(alerts) > maxDigestLines {
+		lines = append(lines, fmt.Sprintf("... and %d more", len(alerts)-maxDigestLines))
+	}
+	return Alert{
+		ID:        newID(),
+		Category:  categoryDigest,
+		Severity:  severity,
+		Event:     categoryDigest,
+		Message:   fmt.Sprintf("%d alerts since %s:\n%s", len(alerts), alerts[0].Timestamp.Format("15:04:05"), strings.Join(lines, "\n")),
+		Timestamp: now,
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MatrixConfig posts alerts as messages to a Matrix room.
+type MatrixConfig struct {
+	Homeserver  string `yaml:"homeserver"`   // e.g. https://matrix.example.org
+	AccessToken string `yaml:"access_token"` // Token of the bot account
+	RoomID      string `yaml:"room_id"`      // e.g. !abcdef:example.org
+	Retries     *int   `yaml:"retries"`
+}
+
+func (c MatrixConfig) validate() error {
+	if c.Homeserver == "" || c.AccessToken == "" || c.RoomID == "" {
+		return errors.New("matrix homeserver, access_token and room_id are required")
+	}
+	return nil
+}
+
+func init() {
+	registerNotifier("matrix", func(node *yaml.Node) ([]Notifier, error) {
+		cfg, err := decodeNotifierConfig[MatrixConfig](node)
+		if err != nil {
+			return nil, err
+		}
+		return []Notifier{&matrixNotifier{cfg: cfg}}, nil
+	})
+}
+
+type matrixNotifier struct {
+	cfg MatrixConfig
+}
+
+func (n *matrixNotifier) Name() string  { return "matrix" }
+func (n *matrixNotifier) retries() *int { return n.cfg.Retries }
+
+// Notify posts an m.room.message event. The alert ID is the transaction ID,
+// so a retried delivery is not shown twice.
+func (n *matrixNotifier) Notify(ctx context.Context, alert Alert) error {
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(n.cfg.Homeserver, "/"), url.PathEscape(n.cfg.RoomID), url.PathEscape(alert.ID))
+	a := alert.Aircraft
+	position := fmt.Sprintf("https://www.openstreetmap.org/?mlat=%f&mlon=%f#map=11/%f/%f", a.Latitude, a.Longitude, a.Latitude, a.Longitude)
+	details := fmt.Sprintf("%s %s · %d ft · %.0f kt · %s · %s", a.ICAO, a.Callsign, a.Altitude, a.Speed, alert.Severity, alert.Event)
+	return sendJSON(ctx, http.MethodPut, endpoint, map[string]string{
+		"msgtype": "m.text",
+		"body":    alert.Message + "\n" + details + "\n" + position,
+		"format":  "org.matrix.custom.html",
+		"formatted_body": fmt.Sprintf(`<strong>%s</strong><br>%s<br><a href="%s">%.4f, %.4f</a>`,
+			html.EscapeString(alert.Message), html.EscapeString(details), html.EscapeString(position), a.Latitude, a.Longitude),
+	}, map[string]string{"Authorization": "Bearer " + n.cfg.AccessToken})
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const pushoverAPI = "https://api.pushover.net/1/messages.json"
+
+// Pushover message priorities.
+const (
+	pushoverLowest    = -2
+	pushoverNormal    = 0
+	pushoverHigh      = 1
+	pushoverEmergency = 2
+)
+
+// PushoverConfig sends alerts to Pushover. Priorities maps alert severities
+// to Pushover priorities (-2..2); emergency messages repeat every Retry
+// seconds until acknowledged or Expire seconds pass.
+type PushoverConfig struct {
+	Token      string         `yaml:"token"` // Application API token
+	User       string         `yaml:"user"`  // User or group key
+	Device     string         `yaml:"device"`
+	Sound      string         `yaml:"sound"`
+	Priorities map[string]int `yaml:"priorities"`
+	Retry      int            `yaml:"retry"`  // Seconds, at least 30
+	Expire     int            `yaml:"expire"` // Seconds, at most 10800
+	APIURL     string         `yaml:"api_url"`
+	Retries    *int           `yaml:"retries"`
+}
+
+func (c *PushoverConfig) validate() error {
+	if c.Token == "" || c.User == "" {
+		return errors.New("pushover token and user are required")
+	}
+	for severity, p := range c.Priorities {
+		if p < pushoverLowest || p > pushoverEmergency {
+			return fmt.Errorf("pushover priority for %q must be between -2 and 2", severity)
+		}
+	}
+	if c.Retry == 0 {
+		c.Retry = 60
+	}
+	if c.Expire == 0 {
+		c.Expire = 3600
+	}
+	if c.Retry < 30 || c.Expire > 10800 {
+		return errors.New("pushover retry must be at least 30 and expire at most 10800 seconds")
+	}
+	return nil
+}
+
+// defaultPushoverPriorities maps severities when the config does not.
+var defaultPushoverPriorities = map[string]int{
+	severityInfo:     pushoverNormal,
+	severityWarning:  pushoverHigh,
+	severityCritical: pushoverEmergency,
+}
+
+func init() {
+	registerNotifier("pushover", func(node *yaml.Node) ([]Notifier, error) {
+		cfg, err := decodeNotifierConfig[PushoverConfig](node)
+		if err != nil {
+			return nil, err
+		}
+		return []Notifier{&pushoverNotifier{cfg: cfg}}, nil
+	})
+}
+
+type pushoverNotifier struct {
+	cfg PushoverConfig
+}
+
+func (n *pushoverNotifier) Name() string  { return "pushover" }
+func (n *pushoverNotifier) retries() *int { return n.cfg.Retries }
+
+func (n *pushoverNotifier) priority(severity string) int {
+	if p, ok := n.cfg.Priorities[severity]; ok {
+		return p
+	}
+	return defaultPushoverPriorities[severity]
+}
+
+func (n *pushoverNotifier) Notify(ctx context.Context, alert Alert) error {
+	a := alert.Aircraft
+	priority := n.priority(alert.Severity)
+	form := url.Values{
+		"token":     {n.cfg.Token},
+		"user":      {n.cfg.User},
+		"title":     {fmt.Sprintf("%s %s", strings.ToUpper(alert.Severity), alert.Event)},
+		"message":   {fmt.Sprintf("%s\n%d ft · %.0f kt", alert.Message, a.Altitude, a.Speed)},
+		"timestamp": {strconv.FormatInt(alert.Timestamp.Unix(), 10)},
+		"priority":  {strconv.Itoa(priority)},
+		"url":       {fmt.Sprintf("https://www.openstreetmap.org/?mlat=%f&mlon=%f#map=11/%f/%f", a.Latitude, a.Longitude, a.Latitude, a.Longitude)},
+		"url_title": {"Show position"},
+	}
+	if priority == pushoverEmergency {
+		form.Set("retry", strconv.Itoa(n.cfg.Retry))
+		form.Set("expire", strconv.Itoa(n.cfg.Expire))
+	}
+	if n.cfg.Device != "" {
+		form.Set("device", n.cfg.Device)
+	}
+	if n.cfg.Sound != "" {
+		form.Set("sound", n.cfg.Sound)
+	}
+
+	api := n.cfg.APIURL
+	if api == "" {
+		api = pushoverAPI
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api, strings.NewReader(form.Encode()))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doNotifyRequest(req)
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	notifyDefaultRetries = 10
+	notifyRetryBackoff   = 2 * time.Second
+	notifyMaxBackoff     = time.Hour
+	// notifyPollInterval bounds how long a worker sleeps, so deliveries
+	// revived through the API are picked up promptly.
+	notifyPollInterval = 30 * time.Second
+	// notifyAttemptTimeout bounds a single delivery attempt.
+	notifyAttemptTimeout = 30 * time.Second
+	// Dead letters are removed notifyDeadLetterRetention after their alert
+	// was queued, checked every notifyPruneInterval.
+	notifyDeadLetterRetention = 7 * 24 * time.Hour
+	notifyPruneInterval       = time.Hour
+)
+
+// Delivery is one alert queued for one notification channel. Deliveries are
+// kept in the store until they succeed, so pending notifications survive a
+// restart; deliveries that exhaust their retries stay behind as dead letters
+// for notifyDeadLetterRetention.
+type Delivery struct {
+	ID          string    `json:"id"`
+	Channel     string    `json:"channel"`
+	Alert       Alert     `json:"alert"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+	Dead        bool      `json:"dead"`
+	Created     time.Time `json:"created"`
+}
+
+// notifierQueue delivers the stored deliveries of one notifier in the
+// background, retrying failures with exponential backoff.
+type notifierQueue struct {
+	kind     string
+	notifier Notifier
+	retries  int
+	wake     chan struct{}
+	stop     chan chan struct{}
+	gate     *channelGate // Rate limit and digest policy, nil if none
+	// lastPrune is when the worker last removed expired dead letters.
+	lastPrune time.Time
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+	failures    int                 // Consecutive failed attempts
+	pending     map[string]Delivery // This channel's live deliveries by ID, mirroring the store
+}
+
+// notifiers holds the delivery queues started from the configuration. A
+// reload replaces it under mu.
+var notifiers []*notifierQueue
+
+// newNotifierQueues builds a delivery queue for every configured channel.
+// The queues accept alerts straight away, storing them as deliveries, but
+// send nothing until runNotifiers starts them.
+func newNotifierQueues(cfg NotificationsConfig) []*notifierQueue {
+	queues := make([]*notifierQueue, 0, len(cfg.notifiers))
+	for _, n := range cfg.notifiers {
+		q := &notifierQueue{kind: n.kind, notifier: n.notifier, retries: notifyDefaultRetries, wake: make(chan struct{}, 1), stop: make(chan chan struct{}, 1),
+			pending: map[string]Delivery{}}
+		if r, ok := n.notifier.(notifierRetries); ok && r.retries() != nil {
+			q.retries = *r.retries()
+		}
+		policy, ok := cfg.Channels[n.notifier.Name()]
+		quiet := cfg.QuietHours
+		if policy.QuietHours != nil {
+			quiet = policy.QuietHours
+		}
+		if ok || quiet != nil {
+			q.gate = newChannelGate(q, policy, quiet)
+		}
+		queues = append(queues, q)
+	}
+	return queues
+}
+
+// runNotifiers starts the notifiers and the workers of their queues.
+func runNotifiers(queues []*notifierQueue) {
+	for _, q := range queues {
+		if s, ok := q.notifier.(notifierStarter); ok {
+			s.start()
+		}
+		go q.run()
+	}
+}
+
+// activeNotifiers returns the running queues, for handlers not holding mu.
+func activeNotifiers() []*notifierQueue {
+	mu.RLock()
+	defer mu.RUnlock()
+	return notifiers
+}
+
+// notifyAlert queues the alert for the notifiers it is routed to.
+func notifyAlert(alert Alert) {
+	channels := routeAlert(activeConfig.Notifications, alert)
+	for _, q := range notifiers {
+		if channels != nil && !slices.Contains(channels, q.notifier.Name()) {
+			continue
+		}
+		if q.gate != nil {
+			q.gate.admit(alert)
+		} else {
+			q.enqueue(alert)
+		}
+	}
+}
+
+// notifyChannels queues the alert for the named channels only, bypassing
+// routing and channel policies.
+func notifyChannels(alert Alert, channels []string) {
+	for _, q := range notifiers {
+		if slices.Contains(channels, q.notifier.Name()) {
+			q.enqueue(alert)
+		}
+	}
+}
+
+// enqueue stores a delivery of the alert and wakes the worker.
+func (q *notifierQueue) enqueue(alert Alert) {
+	q.enqueueAt(alert, time.Now())
+}
+
+// enqueueAt stores a delivery of the alert due at the given time.
+func (q *notifierQueue) enqueueAt(alert Alert, due time.Time) {
+	d := Delivery{ID: newID(), Channel: q.notifier.Name(), Alert: alert, NextAttempt: due, Created: time.Now()}
+	if err := store.SaveDelivery(d); err != nil {
+		log.Printf("Notifier %s: error queueing alert %s: %v", d.Channel, alert.ID, err)
+		return
+	}
+	q.track(d)
+	q.poke()
+}
+
+// track adds a stored live delivery to the queue's index and untrack drops
+// one, so workers find due deliveries without listing the whole store.
+func (q *notifierQueue) track(d Delivery) {
+	q.mu.Lock()
+	q.pending[d.ID] = d
+	q.mu.Unlock()
+}
+
+func (q *notifierQueue) untrack(id string) {
+	q.mu.Lock()
+	delete(q.pending, id)
+	q.mu.Unlock()
+}
+
+// load indexes the channel's live deliveries left in the store, e.g. by the
+// previous run.
+func (q *notifierQueue) load() {
+	deliveries, err := store.ListDeliveries()
+	if err != nil {
+		log.Printf("Notifier %s: error loading queue: %v", q.notifier.Name(), err)
+		return
+	}
+	for _, d := range deliveries {
+		if d.Channel == q.notifier.Name() && !d.Dead {
+			q.track(d)
+		}
+	}
+}
+
+// pruneDeadLetters removes the channel's dead letters queued longer than
+// notifyDeadLetterRetention ago, at most every notifyPruneInterval.
+func (q *notifierQueue) pruneDeadLetters(now time.Time) {
+	if now.Sub(q.lastPrune) < notifyPruneInterval {
+		return
+	}
+	q.lastPrune = now
+	deliveries, err := store.ListDeliveries()
+	if err != nil {
+		log.Printf("Notifier %s: error loading dead letters: %v", q.notifier.Name(), err)
+		return
+	}
+	for _, d := range deliveries {
+		if d.Channel != q.notifier.Name() || !d.Dead || now.Sub(d.Created) < notifyDeadLetterRetention {
+			continue
+		}
+		if err := store.DeleteDelivery(d.ID); err != nil && !errors.Is(err, errNotFound) {
+			log.Printf("Notifier %s: error removing dead letter %s: %v", q.notifier.Name(), d.ID, err)
+		}
+	}
+}
+
+// retryBackoff is the wait before the next attempt after the given number
+// of failed ones: doubling from notifyRetryBackoff up to notifyMaxBackoff.
+func retryBackoff(attempts int) time.Duration {
+	backoff := notifyRetryBackoff
+	for i := 1; i < attempts && backoff < notifyMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, notifyMaxBackoff)
+}
+
+// poke wakes the worker without blocking.
+func (q *notifierQueue) poke() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *notifierQueue) run() {
+	q.load()
+	for {
+		q.pruneDeadLetters(time.Now())
+		wait := q.deliverDue()
+		timer := time.NewTimer(wait)
+		select {
+		case <-q.wake:
+		case <-timer.C:
+		case done := <-q.stop:
+			timer.Stop()
+			q.deliverDue()
+			close(done)
+			return
+		}
+		timer.Stop()
+	}
+}
+
+// stopNotifiers stores the alerts channel gates are holding back, due when
+// the gates would have released them, and gives every queue a last delivery
+// round before stopping its notifier. It waits for the rounds until ctx
+// ends; whatever is left stays in the store for the next start.
+func stopNotifiers(ctx context.Context, queues []*notifierQueue) {
+	var pending []chan struct{}
+	for _, q := range queues {
+		if q.gate != nil {
+			q.gate.park()
+		}
+		done := make(chan struct{})
+		q.stop <- done
+		pending = append(pending, done)
+	}
+	defer func() {
+		for _, q := range queues {
+			if s, ok := q.notifier.(notifierStopper); ok {
+				s.stop()
+			}
+		}
+	}()
+	for _, done := range pending {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			log.Printf("Notification deliveries still running were cut off")
+			return
+		}
+	}
+}
+
+// deliverDue attempts every delivery of this queue that is due, oldest
+// first, and returns how long to wait before the next one is due.
+func (q *notifierQueue) deliverDue() time.Duration {
+	name := q.notifier.Name()
+	wait := notifyPollInterval
+	var due []Delivery
+	q.mu.Lock()
+	for _, d := range q.pending {
+		if until := time.Until(d.NextAttempt); until > 0 {
+			wait = min(wait, until)
+		} else {
+			due = append(due, d)
+		}
+	}
+	q.mu.Unlock()
+	slices.SortFunc(due, func(a, b Delivery) int { return a.Created.Compare(b.Created) })
+
+	for _, d := range due {
+		err := q.attempt(d.Alert)
+		if err == nil {
+			q.untrack(d.ID)
+			if err := store.DeleteDelivery(d.ID); err != nil && !errors.Is(err, errNotFound) {
+				log.Printf("Notifier %s: error removing delivered %s: %v", name, d.ID, err)
+			}
+			continue
+		}
+
+		d.Attempts++
+		d.LastError = err.Error()
+		_, permanent := err.(permanentError)
+		if permanent || d.Attempts > q.retries {
+			d.Dead = true
+			q.untrack(d.ID)
+			log.Printf("Notifier %s: giving up on alert %s after %d attempts: %v", name, d.Alert.ID, d.Attempts, err)
+		} else {
+			backoff := retryBackoff(d.Attempts)
+			d.NextAttempt = time.Now().Add(backoff)
+			wait = min(wait, backoff)
+			q.track(d)
+			log.Printf("Notifier %s: delivering alert %s failed, retrying in %v: %v", name, d.Alert.ID, backoff, err)
+		}
+		if err := store.SaveDelivery(d); err != nil {
+			log.Printf("Notifier %s: error saving delivery %s: %v", name, d.ID, err)
+		}
+	}
+	return wait
+}
+
+// attempt sends the alert once, recording the outcome for the health
+// status. The attempt is traced as part of the update that raised the alert.
+func (q *notifierQueue) attempt(alert Alert) error {
+	ctx, span := tracer.Start(alertContext(context.Background(), alert), "notify", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("notifier.name", q.notifier.Name()), attribute.String("notifier.kind", q.kind),
+			attribute.String("alert.id", alert.ID)))
+	ctx, cancel := context.WithTimeout(ctx, notifyAttemptTimeout)
+	defer cancel()
+	err := q.notifier.Notify(ctx, alert)
+	endSpan(span, err)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err != nil {
+		q.lastError = err.Error()
+		q.lastErrorAt = time.Now()
+		q.failures++
+	} else {
+		q.lastSuccess = time.Now()
+		q.failures = 0
+	}
+	return err
+}
+
+// NotifierStatus is the health of one notification channel as shown by
+// GET /api/v1/notifiers.
+type NotifierStatus struct {
+	Name                string     `json:"name"`
+	Kind                string     `json:"kind"`
+	Healthy             bool       `json:"healthy"`
+	Error               string     `json:"error,omitempty"` // Reported by the notifier itself, e.g. disconnected
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Pending             int        `json:"pending"`
+	Dead                int        `json:"dead"`
+	Held                int        `json:"held"` // Alerts waiting for the next digest
+}
+
+// status reports the queue's health; deliveries are the stored queue.
+func (q *notifierQueue) status(deliveries []Delivery) NotifierStatus {
+	s := NotifierStatus{Name: q.notifier.Name(), Kind: q.kind}
+	if h, ok := q.notifier.(notifierHealth); ok {
+		if err := h.Health(); err != nil {
+			s.Error = err.Error()
+		}
+	}
+	q.mu.Lock()
+	if !q.lastSuccess.IsZero() {
+		t := q.lastSuccess
+		s.LastSuccess = &t
+	}
+	if !q.lastErrorAt.IsZero() {
+		t := q.lastErrorAt
+		s.LastError, s.LastErrorAt = q.lastError, &t
+	}
+	s.ConsecutiveFailures = q.failures
+	q.mu.Unlock()
+	for _, d := range deliveries {
+		if d.Channel != s.Name {
+			continue
+		}
+		if d.Dead {
+			s.Dead++
+		} else {
+			s.Pending++
+		}
+	}
+	if q.gate != nil {
+		q.gate.mu.Lock()
+		s.Held = len(q.gate.pending)
+		q.gate.mu.Unlock()
+	}
+	s.Healthy = s.Error == "" && s.ConsecutiveFailures == 0
+	return s
+}
+
+// handleListNotifiers reports the health of every notification channel.
+func handleListNotifiers(c *jacked.Context) error {
+	deliveries, err := store.ListDeliveries()
+	if err != nil {
+		return storeError(c, err)
+	}
+	queues := activeNotifiers()
+	list := make([]NotifierStatus, 0, len(queues))
+	for _, q := range queues {
+		list = append(list, q.status(deliveries))
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// handleListDeliveries lists queued deliveries; ?status=dead returns only
+// dead letters and ?status=pending only those still being retried.
+func handleListDeliveries(c *jacked.Context) error {
+	status := c.Request.URL.Query().Get("status")
+	if status != "" && status != "dead" && status != "pending" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "status must be dead or pending"})
+	}
+	deliveries, err := store.ListDeliveries()
+	if err != nil {
+		return storeError(c, err)
+	}
+	list := make([]Delivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		if status == "" || d.Dead == (status == "dead") {
+			list = append(list, d)
+		}
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// handleRetryDelivery revives a dead letter for immediate redelivery.
+func handleRetryDelivery(c *jacked.Context) error {
+	d, err := getDelivery(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Delivery not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	d.Dead = false
+	d.Attempts = 0
+	d.NextAttempt = time.Now()
+	if err := store.SaveDelivery(d); err != nil {
+		return storeError(c, err)
+	}
+	for _, q := range activeNotifiers() {
+		if q.notifier.Name() == d.Channel {
+			q.track(d)
+			q.poke()
+		}
+	}
+	return c.JSON(http.StatusOK, d)
+}
+
+// handleDeleteDelivery discards a queued delivery or dead letter.
+func handleDeleteDelivery(c *jacked.Context) error {
+	id := c.Param("id")
+	err := store.DeleteDelivery(id)
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Delivery not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	for _, q := range activeNotifiers() {
+		q.untrack(id)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+func getDelivery(id string) (Delivery, error) {
+	deliveries, err := store.ListDeliveries()
+	if err != nil {
+		return Delivery{}, err
+	}
+	for _, d := range deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return Delivery{}, errNotFound
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily do-not-disturb period. Non-critical alerts arriving
+// during it are held and sent as one digest when it ends; critical alerts
+// still go out.
+type QuietHours struct {
+	Start    string   `yaml:"start"`    // e.g. "22:00"
+	End      string   `yaml:"end"`      // e.g. "07:00"; before start spans midnight
+	Days     []string `yaml:"days"`     // Days the period starts on, e.g. [sat, sun]; default every day
+	Timezone string   `yaml:"timezone"` // IANA name, e.g. Europe/London; default local time
+
+	start, end int // Minutes after midnight
+	days       [7]bool
+	loc        *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func (q *QuietHours) validate() error {
+	var err error
+	if q.start, err = parseClock(q.Start); err != nil {
+		return fmt.Errorf("quiet_hours start: %w", err)
+	}
+	if q.end, err = parseClock(q.End); err != nil {
+		return fmt.Errorf("quiet_hours end: %w", err)
+	}
+	if q.start == q.end {
+		return errors.New("quiet_hours start and end must differ")
+	}
+	q.loc = time.Local
+	if q.Timezone != "" {
+		if q.loc, err = time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("quiet_hours timezone: %w", err)
+		}
+	}
+	q.days = [7]bool{}
+	for _, d := range q.Days {
+		wd, ok := weekdays[strings.ToLower(d)]
+		if !ok {
+			return fmt.Errorf("quiet_hours: unknown day %q", d)
+		}
+		q.days[wd] = true
+	}
+	if len(q.Days) == 0 {
+		q.days = [7]bool{true, true, true, true, true, true, true}
+	}
+	return nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// remaining returns how long the quiet period covering now lasts, zero
+// outside quiet hours or for a nil schedule.
+func (q *QuietHours) remaining(now time.Time) time.Duration {
+	if q == nil {
+		return 0
+	}
+	t := now.In(q.loc)
+	y, m, d := t.Date()
+	// The period covering now started either today or, spanning midnight,
+	// yesterday.
+	for back := 0; back <= 1; back++ {
+		start := time.Date(y, m, d-back, q.start/60, q.start%60, 0, 0, q.loc)
+		end := time.Date(y, m, d-back, q.end/60, q.end%60, 0, 0, q.loc)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		if q.days[start.Weekday()] && !t.Before(start) && t.Before(end) {
+			return end.Sub(t)
+		}
+	}
+	return 0
+}
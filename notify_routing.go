@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// NotificationRoute sends alerts carrying all of Tags (from their
+// criterion) and at least MinSeverity to Channels. Empty conditions match
+// every alert.
+type NotificationRoute struct {
+	Tags        []string `yaml:"tags"`
+	MinSeverity string   `yaml:"min_severity"`
+	Channels    []string `yaml:"channels"`
+}
+
+// validateRoutes checks that routes only name configured channels.
+func (c *NotificationsConfig) validateRoutes(names map[string]bool) error {
+	for i, r := range c.Routes {
+		if len(r.Channels) == 0 {
+			return fmt.Errorf("notification route %d has no channels", i+1)
+		}
+		if r.MinSeverity != "" {
+			if _, ok := severityRank[r.MinSeverity]; !ok {
+				return fmt.Errorf("notification route %d: unknown min_severity %q", i+1, r.MinSeverity)
+			}
+		}
+		for _, ch := range r.Channels {
+			if !names[ch] {
+				return fmt.Errorf("notification route %d: unknown channel %q", i+1, ch)
+			}
+		}
+	}
+	for _, ch := range c.DefaultChannels {
+		if !names[ch] {
+			return fmt.Errorf("unknown default notification channel %q", ch)
+		}
+	}
+	return nil
+}
+
+func (r NotificationRoute) matches(alert Alert) bool {
+	if r.MinSeverity != "" && severityRank[alert.Severity] < severityRank[r.MinSeverity] {
+		return false
+	}
+	return alert.Criteria.HasTags(r.Tags)
+}
+
+// routeAlert returns the channels the alert goes to, or nil for all of
+// them. The criterion's own "notify" list wins over routes, and routes over
+// the default channels.
+func routeAlert(cfg NotificationsConfig, alert Alert) []string {
+	if len(alert.Criteria.Notify) > 0 {
+		return alert.Criteria.Notify
+	}
+	var channels []string
+	for _, r := range cfg.Routes {
+		if !r.matches(alert) {
+			continue
+		}
+		for _, ch := range r.Channels {
+			if !slices.Contains(channels, ch) {
+				channels = append(channels, ch)
+			}
+		}
+	}
+	if len(channels) > 0 {
+		return channels
+	}
+	return cfg.DefaultChannels
+}
+
+// validateNotify checks the criterion's "notify" channels against the
+// running configuration.
+func validateNotify(ac *AlertCriteria) error {
+	names := notificationChannelNames(activeConfig.Notifications)
+	for _, ch := range ac.Notify {
+		if !slices.Contains(names, ch) {
+			return fmt.Errorf("unknown notification channel %q", ch)
+		}
+	}
+	return nil
+}
+
+// notificationChannelNames lists the channels configured in cfg, which must
+// already be validated.
+func notificationChannelNames(cfg NotificationsConfig) []string {
+	names := make([]string, 0, len(cfg.notifiers))
+	for _, n := range cfg.notifiers {
+		names = append(names, n.notifier.Name())
+	}
+	return names
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/containrrr/shoutrrr"
+	"github.com/containrrr/shoutrrr/pkg/router"
+	"github.com/containrrr/shoutrrr/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ShoutrrrConfig is one channel backed by a shoutrrr service URL, e.g.
+// gotify://host/token or teams://group@tenant/altId/groupOwner?host=...,
+// covering services without a notifier of their own.
+type ShoutrrrConfig struct {
+	Name    string `yaml:"name"` // Channel name; defaults to the URL scheme
+	URL     string `yaml:"url"`
+	Retries *int   `yaml:"retries"`
+}
+
+func (c *ShoutrrrConfig) validate() error {
+	if c.URL == "" {
+		return errors.New("shoutrrr url is required")
+	}
+	if _, err := shoutrrr.CreateSender(c.URL); err != nil {
+		// The error may quote the URL, which usually carries credentials.
+		return errors.New("invalid shoutrrr url")
+	}
+	if c.Name == "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || u.Scheme == "" {
+			return errors.New("shoutrrr url has no service scheme")
+		}
+		c.Name = u.Scheme
+	}
+	return nil
+}
+
+// shoutrrr is a list so several services can be configured.
+func init() {
+	registerNotifier("shoutrrr", func(node *yaml.Node) ([]Notifier, error) {
+		var cfgs []ShoutrrrConfig
+		if err := node.Decode(&cfgs); err != nil {
+			return nil, err
+		}
+		built := make([]Notifier, 0, len(cfgs))
+		for i := range cfgs {
+			cfg := cfgs[i]
+			if err := cfg.validate(); err != nil {
+				return nil, err
+			}
+			sender, err := shoutrrr.CreateSender(cfg.URL)
+			if err != nil {
+				return nil, fmt.Errorf("shoutrrr channel %s: cannot create sender", cfg.Name)
+			}
+			built = append(built, &shoutrrrNotifier{cfg: cfg, sender: sender})
+		}
+		return built, nil
+	})
+}
+
+type shoutrrrNotifier struct {
+	cfg    ShoutrrrConfig
+	sender *router.ServiceRouter
+}
+
+func (n *shoutrrrNotifier) Name() string  { return n.cfg.Name }
+func (n *shoutrrrNotifier) retries() *int { return n.cfg.Retries }
+
+// Notify sends a plain text message. Shoutrrr does not take a context, so
+// a send outliving ctx is abandoned rather than interrupted.
+func (n *shoutrrrNotifier) Notify(ctx context.Context, alert Alert) error {
+	params := types.Params{"title": fmt.Sprintf("%s %s", strings.ToUpper(alert.Severity), alert.Event)}
+	done := make(chan error, 1)
+	go func() {
+		done <- errors.Join(n.sender.Send(shoutrrrText(alert), &params)...)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shoutrrrText formats the alert for services of unknown markup.
+func shoutrrrText(alert Alert) string {
+	a := alert.Aircraft
+	return fmt.Sprintf("%s\n%s %s · %d ft · %.0f kt\nhttps://www.openstreetmap.org/?mlat=%f&mlon=%f#map=11/%f/%f",
+		alert.Message, a.ICAO, a.Callsign, a.Altitude, a.Speed, a.Latitude, a.Longitude, a.Latitude, a.Longitude)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SlackConfig posts alerts to Slack incoming webhooks. Each webhook is bound
+// to one Slack channel; criteria pick a named channel with "slack_channel".
+type SlackConfig struct {
+	WebhookURL string            `yaml:"webhook_url"` // Default channel
+	Channels   map[string]string `yaml:"channels"`    // Channel name -> incoming webhook URL
+	Retries    *int              `yaml:"retries"`
+}
+
+func (c SlackConfig) validate() error {
+	if c.WebhookURL == "" {
+		return errors.New("slack webhook_url is required")
+	}
+	for name, url := range c.Channels {
+		if url == "" {
+			return fmt.Errorf("slack channel %q has no webhook URL", name)
+		}
+	}
+	return nil
+}
+
+func init() {
+	registerNotifier("slack", func(node *yaml.Node) ([]Notifier, error) {
+		cfg, err := decodeNotifierConfig[SlackConfig](node)
+		if err != nil {
+			return nil, err
+		}
+		return []Notifier{&slackNotifier{cfg: cfg}}, nil
+	})
+}
+
+type slackNotifier struct {
+	cfg SlackConfig
+}
+
+func (n *slackNotifier) Name() string  { return "slack" }
+func (n *slackNotifier) retries() *int { return n.cfg.Retries }
+
+func (n *slackNotifier) Notify(ctx context.Context, alert Alert) error {
+	url := n.cfg.WebhookURL
+	if u, ok := n.cfg.Channels[alert.Criteria.SlackChannel]; ok {
+		url = u
+	}
+	return postJSON(ctx, url, slackMessage(alert), nil)
+}
+
+// severityEmoji decorates chat messages by alert severity.
+var severityEmoji = map[string]string{
+	severityInfo:     ":information_source:",
+	severityWarning:  ":warning:",
+	severityCritical: ":rotating_light:",
+}
+
+// slackEscape escapes the control characters of Slack mrkdwn.
+var slackEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+// slackMessage formats the alert as a Block Kit message with a plain text
+// fallback for notifications.
+func slackMessage(alert Alert) map[string]interface{} {
+	a := alert.Aircraft
+	callsign := a.Callsign
+	if callsign == "" {
+		callsign = "-"
+	}
+	field := func(label, value string) map[string]string {
+		return map[string]string{"type": "mrkdwn", "text": "*" + label + "*\n" + value}
+	}
+	return map[string]interface{}{
+		"text": alert.Message,
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": severityEmoji[alert.Severity] + " *" + slackEscape(alert.Message) + "*"},
+			},
+			map[string]interface{}{
+				"type": "section",
+				"fields": []interface{}{
+					field("ICAO", a.ICAO),
+					field("Callsign", slackEscape(callsign)),
+					field("Altitude", fmt.Sprintf("%d ft", a.Altitude)),
+					field("Speed", fmt.Sprintf("%.0f kt", a.Speed)),
+					field("Event", alert.Event),
+					field("Position", fmt.Sprintf("<https://www.openstreetmap.org/?mlat=%f&mlon=%f#map=11/%f/%f|%.4f, %.4f>",
+						a.Latitude, a.Longitude, a.Latitude, a.Longitude, a.Latitude, a.Longitude)),
+				},
+			},
+			map[string]interface{}{
+				"type": "context",
+				"elements": []interface{}{
+					map[string]string{"type": "mrkdwn", "text": fmt.Sprintf("%s · %s · <!date^%d^{date_short_pretty} {time_secs}|%s>",
+						alert.Severity, alert.Category, alert.Timestamp.Unix(), alert.Timestamp.UTC().Format(time.RFC3339))},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const telegramAPI = "https://api.telegram.org"
+
+// TelegramConfig sends alerts through a Telegram bot to one or more chats.
+type TelegramConfig struct {
+	BotToken string   `yaml:"bot_token"`
+	ChatIDs  []string `yaml:"chat_ids"` // Numeric chat IDs or @channelname
+	// StaticMapURL, when set, attaches a map image of the aircraft position.
+	// {lat} and {lon} are replaced with the coordinates; Telegram fetches
+	// the image itself.
+	StaticMapURL string `yaml:"static_map_url"`
+	APIURL       string `yaml:"api_url"` // Defaults to https://api.telegram.org
+	Retries      *int   `yaml:"retries"`
+}
+
+func (c TelegramConfig) validate() error {
+	if c.BotToken == "" {
+		return errors.New("telegram bot_token is required")
+	}
+	if len(c.ChatIDs) == 0 {
+		return errors.New("telegram needs at least one chat ID")
+	}
+	return nil
+}
+
+func init() {
+	registerNotifier("telegram", func(node *yaml.Node) ([]Notifier, error) {
+		cfg, err := decodeNotifierConfig[TelegramConfig](node)
+		if err != nil {
+			return nil, err
+		}
+		return []Notifier{&telegramNotifier{cfg: cfg}}, nil
+	})
+}
+
+type telegramNotifier struct {
+	cfg TelegramConfig
+}
+
+func (n *telegramNotifier) Name() string  { return "telegram" }
+func (n *telegramNotifier) retries() *int { return n.cfg.Retries }
+
+// Notify delivers the alert to every chat. A failing chat does not stop the
+// others; the first error is returned so the alert is retried.
+func (n *telegramNotifier) Notify(ctx context.Context, alert Alert) error {
+	api := n.cfg.APIURL
+	if api == "" {
+		api = telegramAPI
+	}
+	api = strings.TrimSuffix(api, "/") + "/bot" + n.cfg.BotToken
+
+	text := telegramText(alert)
+	var firstErr error
+	for _, chat := range n.cfg.ChatIDs {
+		var err error
+		if n.cfg.StaticMapURL != "" {
+			err = postJSON(ctx, api+"/sendPhoto", map[string]string{
+				"chat_id":    chat,
+				"photo":      staticMapURL(n.cfg.StaticMapURL, alert.Aircraft),
+				"caption":    text,
+				"parse_mode": "HTML",
+			}, nil)
+		} else {
+			err = postJSON(ctx, api+"/sendMessage", map[string]string{
+				"chat_id":    chat,
+				"text":       text,
+				"parse_mode": "HTML",
+			}, nil)
+		}
+		if err != nil && firstErr == nil {
+			// Transport errors quote the URL, which embeds the token.
+			msg := strings.ReplaceAll(err.Error(), n.cfg.BotToken, "<token>")
+			if _, permanent := err.(permanentError); permanent {
+				firstErr = permanentError{fmt.Errorf("chat %s: %s", chat, msg)}
+			} else {
+				firstErr = fmt.Errorf("chat %s: %s", chat, msg)
+			}
+		}
+	}
+	return firstErr
+}
+
+// staticMapURL fills the {lat} and {lon} placeholders of a map image URL.
+func staticMapURL(tmpl string, a Aircraft) string {
+	return strings.NewReplacer(
+		"{lat}", fmt.Sprintf("%f", a.Latitude),
+		"{lon}", fmt.Sprintf("%f", a.Longitude),
+	).Replace(tmpl)
+}
+
+// telegramText formats the alert as Telegram HTML.
+func telegramText(alert Alert) string {
+	a := alert.Aircraft
+	return fmt.Sprintf("<b>%s</b>\n%s %s · %d ft · %.0f kt\n%s · %s\n%s",
+		html.EscapeString(alert.Message),
+		html.EscapeString(a.ICAO), html.EscapeString(a.Callsign), a.Altitude, a.Speed,
+		html.EscapeString(alert.Severity), html.EscapeString(alert.Event),
+		fmt.Sprintf(`<a href="https://www.openstreetmap.org/?mlat=%f&amp;mlon=%f#map=11/%f/%f">%.4f, %.4f</a>`,
+			a.Latitude, a.Longitude, a.Latitude, a.Longitude, a.Latitude, a.Longitude))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const twilioAPI = "https://api.twilio.com/2010-04-01"
+
+// TwilioConfig sends SMS, and optionally places voice calls, for alerts of
+// at least MinSeverity. DailyCap bounds the messages plus calls per day so a
+// noisy criterion cannot run up the bill.
+type TwilioConfig struct {
+	AccountSID  string   `yaml:"account_sid"`
+	AuthToken   string   `yaml:"auth_token"`
+	From        string   `yaml:"from"` // Twilio number, E.164
+	To          []string `yaml:"to"`   // Recipients, E.164
+	Voice       bool     `yaml:"voice"`
+	MinSeverity string   `yaml:"min_severity"` // Default critical
+	DailyCap    int      `yaml:"daily_cap"`    // Default 20
+	APIURL      string   `yaml:"api_url"`
+	Retries     *int     `yaml:"retries"`
+}
+
+func (c *TwilioConfig) validate() error {
+	if c.AccountSID == "" || c.AuthToken == "" || c.From == "" || len(c.To) == 0 {
+		return errors.New("twilio account_sid, auth_token, from and to are required")
+	}
+	if c.MinSeverity == "" {
+		c.MinSeverity = severityCritical
+	}
+	if _, ok := severityRank[c.MinSeverity]; !ok {
+		return fmt.Errorf("unknown twilio min_severity %q", c.MinSeverity)
+	}
+	if c.DailyCap == 0 {
+		c.DailyCap = 20
+	}
+	if c.DailyCap < 0 {
+		return errors.New("twilio daily_cap must be positive")
+	}
+	return nil
+}
+
+func init() {
+	registerNotifier("twilio", func(node *yaml.Node) ([]Notifier, error) {
+		cfg, err := decodeNotifierConfig[TwilioConfig](node)
+		if err != nil {
+			return nil, err
+		}
+		return []Notifier{&twilioNotifier{cfg: cfg}}, nil
+	})
+}
+
+type twilioNotifier struct {
+	cfg TwilioConfig
+
+	mu    sync.Mutex
+	day   string // Local date the count applies to
+	count int
+}
+
+func (n *twilioNotifier) Name() string  { return "twilio" }
+func (n *twilioNotifier) retries() *int { return n.cfg.Retries }
+
+// reserve counts one message or call against today's cap, reporting false
+// once the cap is reached.
+func (n *twilioNotifier) reserve() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if today := time.Now().Format("2006-01-02"); today != n.day {
+		n.day, n.count = today, 0
+	}
+	if n.count >= n.cfg.DailyCap {
+		return false
+	}
+	n.count++
+	return true
+}
+
+func (n *twilioNotifier) Notify(ctx context.Context, alert Alert) error {
+	if severityRank[alert.Severity] < severityRank[n.cfg.MinSeverity] {
+		return nil
+	}
+	text := fmt.Sprintf("%s (%s, %d ft)", alert.Message, alert.Severity, alert.Aircraft.Altitude)
+	var firstErr error
+	for _, to := range n.cfg.To {
+		if !n.reserve() {
+			log.Printf("Twilio: daily cap of %d reached, not notifying %s of alert %s", n.cfg.DailyCap, to, alert.ID)
+			return firstErr
+		}
+		err := n.post(ctx, "Messages.json", url.Values{"To": {to}, "From": {n.cfg.From}, "Body": {text}})
+		if err == nil && n.cfg.Voice {
+			if !n.reserve() {
+				log.Printf("Twilio: daily cap of %d reached, not calling %s for alert %s", n.cfg.DailyCap, to, alert.ID)
+				return firstErr
+			}
+			err = n.post(ctx, "Calls.json", url.Values{"To": {to}, "From": {n.cfg.From}, "Twiml": {twimlSay(text)}})
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", to, err)
+		}
+	}
+	return firstErr
+}
+
+// post calls a Twilio REST resource of the account.
+func (n *twilioNotifier) post(ctx context.Context, resource string, form url.Values) error {
+	api := n.cfg.APIURL
+	if api == "" {
+		api = twilioAPI
+	}
+	endpoint := fmt.Sprintf("%s/Accounts/%s/%s", strings.TrimSuffix(api, "/"), url.PathEscape(n.cfg.AccountSID), resource)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.SetBasicAuth(n.cfg.AccountSID, n.cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doNotifyRequest(req)
+}
+
+// twimlSay returns TwiML reading the text out twice.
+func twimlSay(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	say := "<Say>" + escaped.String() + "</Say>"
+	return "<Response>" + say + "<Pause length=\"1\"/>" + say + "</Response>"
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"gopkg.in/yaml.v3"
+)
+
+// webhooks is a list; unnamed entries are named webhook, webhook-2 and so
+// on.
+func init() {
+	registerNotifier("webhooks", func(node *yaml.Node) ([]Notifier, error) {
+		var cfgs []WebhookConfig
+		if err := node.Decode(&cfgs); err != nil {
+			return nil, err
+		}
+		built := make([]Notifier, 0, len(cfgs))
+		for i, w := range cfgs {
+			if w.Name == "" {
+				w.Name = "webhook"
+				if i > 0 {
+					w.Name = fmt.Sprintf("webhook-%d", i+1)
+				}
+			}
+			if err := w.validate(); err != nil {
+				return nil, err
+			}
+			built = append(built, newWebhookNotifier(w))
+		}
+		return built, nil
+	})
+}
+
+// WebhookConfig is a URL receiving each alert as a JSON POST body.
+type WebhookConfig struct {
+	Name    string            `yaml:"name"` // Channel name used in routing; default webhook, webhook-2, ...
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"` // e.g. Authorization
+	Retries *int              `yaml:"retries"` // Extra attempts after a failed delivery; default 10
+}
+
+func (w WebhookConfig) validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook url must be an absolute http(s) URL")
+	}
+	if w.Retries != nil && *w.Retries < 0 {
+		return errors.New("webhook retries must not be negative")
+	}
+	return nil
+}
+
+// webhookNotifier posts the alert JSON unchanged.
+type webhookNotifier struct {
+	cfg WebhookConfig
+}
+
+func newWebhookNotifier(cfg WebhookConfig) *webhookNotifier {
+	return &webhookNotifier{cfg: cfg}
+}
+
+func (n *webhookNotifier) Name() string  { return n.cfg.Name }
+func (n *webhookNotifier) retries() *int { return n.cfg.Retries }
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, n.cfg.URL, alert, n.cfg.Headers)
+}
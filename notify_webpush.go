@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"gopkg.in/yaml.v3"
+)
+
+// WebPushConfig sends alerts to browsers subscribed from the map UI, even
+// while no tab is open. Generate a key pair with -vapid-keys.
+type WebPushConfig struct {
+	VAPIDPublicKey  string        `yaml:"vapid_public_key"`
+	VAPIDPrivateKey string        `yaml:"vapid_private_key"`
+	Subscriber      string        `yaml:"subscriber"` // Contact for push services, mailto: or https: URL
+	TTL             time.Duration `yaml:"ttl"`        // How long push services keep undelivered messages; default 12h
+	Retries         *int          `yaml:"retries"`
+}
+
+func (c *WebPushConfig) validate() error {
+	if c.VAPIDPublicKey == "" || c.VAPIDPrivateKey == "" {
+		return errors.New("webpush vapid_public_key and vapid_private_key are required")
+	}
+	if c.Subscriber == "" {
+		return errors.New("webpush subscriber is required")
+	}
+	if c.TTL == 0 {
+		c.TTL = 12 * time.Hour
+	}
+	if c.TTL < 0 {
+		return errors.New("webpush ttl must not be negative")
+	}
+	return nil
+}
+
+func init() {
+	registerNotifier("webpush", func(node *yaml.Node) ([]Notifier, error) {
+		cfg, err := decodeNotifierConfig[WebPushConfig](node)
+		if err != nil {
+			return nil, err
+		}
+		return []Notifier{&webPushNotifier{cfg: cfg}}, nil
+	})
+}
+
+// PushSubscription is a browser's PushSubscription as registered through
+// POST /api/v1/push/subscriptions.
+type PushSubscription struct {
+	ID        string       `json:"id"`
+	Endpoint  string       `json:"endpoint"`
+	Keys      webpush.Keys `json:"keys"`
+	UserAgent string       `json:"user_agent,omitempty"`
+	Created   time.Time    `json:"created"`
+}
+
+const (
+	maxPushSubscriptionBody = 16 << 10
+	// maxPushSubscriptions bounds the browsers every alert is pushed to.
+	maxPushSubscriptions = 1000
+)
+
+// pushSubscriptionID derives the ID from the endpoint, so a browser that
+// subscribes again replaces its old subscription.
+func pushSubscriptionID(endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint))
+	return hex.EncodeToString(sum[:8])
+}
+
+// pushUrgency tells push services how soon to wake the device.
+var pushUrgency = map[string]webpush.Urgency{
+	severityInfo:     webpush.UrgencyNormal,
+	severityWarning:  webpush.UrgencyHigh,
+	severityCritical: webpush.UrgencyHigh,
+}
+
+type webPushNotifier struct {
+	cfg WebPushConfig
+}
+
+func (n *webPushNotifier) Name() string  { return "webpush" }
+func (n *webPushNotifier) retries() *int { return n.cfg.Retries }
+
+// Notify pushes the alert to every subscribed browser. Subscriptions the
+// push service reports as gone are removed. The alert ID is the push topic,
+// so a retried alert replaces an undelivered copy instead of adding one.
+func (n *webPushNotifier) Notify(ctx context.Context, alert Alert) error {
+	subs, err := store.ListPushSubscriptions()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(webPushPayload(alert))
+	if err != nil {
+		return permanentError{err}
+	}
+	opts := &webpush.Options{
+		HTTPClient:      notifyClient,
+		Subscriber:      n.cfg.Subscriber,
+		VAPIDPublicKey:  n.cfg.VAPIDPublicKey,
+		VAPIDPrivateKey: n.cfg.VAPIDPrivateKey,
+		TTL:             int(n.cfg.TTL / time.Second),
+		Urgency:         pushUrgency[alert.Severity],
+		Topic:           alert.ID,
+	}
+	var firstErr error
+	for _, sub := range subs {
+		resp, err := webpush.SendNotificationWithContext(ctx, payload, &webpush.Subscription{Endpoint: sub.Endpoint, Keys: sub.Keys}, opts)
+		if err == nil {
+			if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+				resp.Body.Close()
+				log.Printf("Web push: subscription %s expired, removing it", sub.ID)
+				if err := store.DeletePushSubscription(sub.ID); err != nil && !errors.Is(err, errNotFound) {
+					log.Printf("Web push: error removing subscription %s: %v", sub.ID, err)
+				}
+				continue
+			}
+			err = responseError(resp)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("subscription %s: %w", sub.ID, err)
+		}
+	}
+	return firstErr
+}
+
+// webPushPayload is the message the service worker (public/sw.js) shows.
+func webPushPayload(alert Alert) map[string]string {
+	a := alert.Aircraft
+	return map[string]string{
+		"title":    fmt.Sprintf("%s %s", a.ICAO, alert.Event),
+		"body":     fmt.Sprintf("%s\n%d ft · %.0f kt", alert.Message, a.Altitude, a.Speed),
+		"tag":      alert.ID,
+		"severity": alert.Severity,
+		"url":      "/",
+	}
+}
+
+// webPush returns the configured Web Push notifier, or nil.
+func webPush() *webPushNotifier {
+	mu.RLock()
+	defer mu.RUnlock()
+	n, _ := activeConfig.Notifications.notifier("webpush").(*webPushNotifier)
+	return n
+}
+
+// handlePushKey returns the VAPID public key browsers subscribe with.
+func handlePushKey(c *jacked.Context) error {
+	n := webPush()
+	if n == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Web push is not configured"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"public_key": n.cfg.VAPIDPublicKey})
+}
+
+// handleSubscribePush registers a browser PushSubscription (the JSON of
+// subscription.toJSON()). It needs the operator role, as every alert is
+// then sent to the endpoint.
+func handleSubscribePush(c *jacked.Context) error {
+	if webPush() == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Web push is not configured"})
+	}
+	var sub PushSubscription
+	body, err := readBody(c, maxPushSubscriptionBody)
+	if err == nil {
+		err = json.Unmarshal(body, &sub)
+	}
+	if err != nil {
+		return bodyError(c, err, "Invalid subscription")
+	}
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "endpoint must be an https URL"})
+	}
+	if sub.Keys.P256dh == "" || sub.Keys.Auth == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "keys.p256dh and keys.auth are required"})
+	}
+	if err := checkPushHost(c.Request.Context(), u.Hostname()); err != nil {
+		logRequestf(c.Request, "Refused push subscription to %s: %v", u.Host, err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "endpoint must be a public push service"})
+	}
+	sub.ID = pushSubscriptionID(sub.Endpoint)
+	subs, err := store.ListPushSubscriptions()
+	if err != nil {
+		return storeError(c, err)
+	}
+	if len(subs) >= maxPushSubscriptions && !slices.ContainsFunc(subs, func(s PushSubscription) bool { return s.ID == sub.ID }) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": fmt.Sprintf("At most %d push subscriptions are allowed", maxPushSubscriptions)})
+	}
+	sub.UserAgent = c.Request.UserAgent()
+	sub.Created = time.Now()
+	if err := store.SavePushSubscription(sub); err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusCreated, sub)
+}
+
+// checkPushHost refuses push endpoints on this host or a private network,
+// which the server would otherwise POST to for every alert.
+func checkPushHost(ctx context.Context, host string) error {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("loopback host")
+	}
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return err
+		}
+		ips = ips[:0]
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+			return fmt.Errorf("%s is not a public address", ip)
+		}
+	}
+	return nil
+}
+
+// handleListPushSubscriptions lists the subscribed browsers.
+func handleListPushSubscriptions(c *jacked.Context) error {
+	subs, err := store.ListPushSubscriptions()
+	if err != nil {
+		return storeError(c, err)
+	}
+	if subs == nil {
+		subs = []PushSubscription{}
+	}
+	return c.JSON(http.StatusOK, subs)
+}
+
+// handleDeletePushSubscription unsubscribes a browser.
+func handleDeletePushSubscription(c *jacked.Context) error {
+	err := store.DeletePushSubscription(c.Param("id"))
+	if errors.Is(err, errNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Subscription not found"})
+	} else if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AirspaceConfig imports airspace files in the OpenAIR format, as published
+// for gliding and flight planning software, as zones named after the
+// airspace (AN), so criteria can watch restricted areas and control zones
+// without drawing them by hand. Zones in zones_file win over airspace of the
+// same name.
+type AirspaceConfig struct {
+	Files []string `yaml:"files"`
+	// Classes limits the import to these classes (AC), e.g. [R, P, CTR];
+	// empty imports every airspace.
+	Classes []string `yaml:"classes"`
+}
+
+// arcStep is the spacing in degrees of the polygon points drawn along arcs.
+const arcStep = 5
+
+var (
+	openAirCoord    = regexp.MustCompile(`^(\d+):(\d+(?:\.\d+)?)(?::(\d+(?:\.\d+)?))?\s*([NS])\s*,?\s*(\d+):(\d+(?:\.\d+)?)(?::(\d+(?:\.\d+)?))?\s*([EW])$`)
+	openAirAltitude = regexp.MustCompile(`^(\d+(?:\.\d+)?)(FT|F|M)?(MSL|AMSL|AGL|ASFC|AAL|SFC|GND)?$`)
+)
+
+// readZoneSources reads the zones of zones_file and the airspace files. It
+// returns nil if neither is configured.
+func readZoneSources(cfg Config) (map[string]Zone, error) {
+	if cfg.ZonesFile == "" && len(cfg.Airspace.Files) == 0 {
+		return nil, nil
+	}
+	loaded := map[string]Zone{}
+	if cfg.ZonesFile != "" {
+		var err error
+		if loaded, err = readZones(cfg.ZonesFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range cfg.Airspace.Files {
+		airspace, err := readOpenAirFile(path)
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		for _, z := range airspace {
+			if len(cfg.Airspace.Classes) > 0 && !slices.Contains(cfg.Airspace.Classes, z.Class) {
+				continue
+			}
+			name := z.Name
+			for i := 2; ; i++ {
+				if _, taken := loaded[name]; !taken {
+					break
+				}
+				name = fmt.Sprintf("%s (%d)", z.Name, i)
+			}
+			z.Name = name
+			loaded[name] = z
+			n++
+		}
+		log.Printf("Loaded %d of %d airspaces from %s", n, len(airspace), path)
+	}
+	return loaded, nil
+}
+
+func readOpenAirFile(path string) ([]Zone, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zones, err := readOpenAir(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return zones, nil
+}
+
+// readOpenAir parses OpenAIR airspace definitions: AC, AN, AL and AH for
+// the class, name and vertical limits, and DP, DC, DA and DB with the V X=
+// and V D= variables for the outline. Arcs become polygon points every
+// arcStep degrees. Other records, such as labels and styles, are ignored.
+func readOpenAir(r io.Reader) ([]Zone, error) {
+	var (
+		list   []Zone
+		cur    *Zone
+		center [2]float64
+		dir    = 1.0 // Clockwise
+	)
+	finish := func() error {
+		if cur == nil {
+			return nil
+		}
+		if err := cur.validate(); err != nil {
+			return err
+		}
+		list = append(list, *cur)
+		cur = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(text, '*'); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		record, arg, _ := strings.Cut(text, " ")
+		arg = strings.TrimSpace(arg)
+		record = strings.ToUpper(record)
+		if record != "AC" && cur == nil {
+			continue
+		}
+		var err error
+		switch record {
+		case "AC":
+			if err = finish(); err == nil {
+				cur = &Zone{Class: strings.ToUpper(arg)}
+				dir = 1
+			}
+		case "AN":
+			cur.Name = arg
+		case "AL":
+			cur.Floor, err = parseOpenAirAltitude(arg)
+		case "AH":
+			cur.Ceiling, err = parseOpenAirAltitude(arg)
+		case "V":
+			name, value, _ := strings.Cut(arg, "=")
+			switch strings.ToUpper(strings.TrimSpace(name)) {
+			case "X":
+				center[0], center[1], err = parseOpenAirCoord(value)
+			case "D":
+				if strings.TrimSpace(value) == "-" {
+					dir = -1
+				} else {
+					dir = 1
+				}
+			}
+		case "DP":
+			var lat, lon float64
+			if lat, lon, err = parseOpenAirCoord(arg); err == nil {
+				cur.Polygon = append(cur.Polygon, [2]float64{lat, lon})
+			}
+		case "DC":
+			var radius float64
+			if radius, err = strconv.ParseFloat(arg, 64); err == nil {
+				c := center
+				cur.Center, cur.Radius = &c, radius
+			}
+		case "DA":
+			parts := strings.Split(arg, ",")
+			if len(parts) != 3 {
+				err = errors.New("DA needs a radius, a start and an end angle")
+				break
+			}
+			var v [3]float64
+			for i, p := range parts {
+				if v[i], err = strconv.ParseFloat(strings.TrimSpace(p), 64); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				cur.Polygon = append(cur.Polygon, arcPoints(center, v[0], v[1], v[2], dir)...)
+			}
+		case "DB":
+			from, to, ok := strings.Cut(arg, ",")
+			if !ok {
+				err = errors.New("DB needs two points")
+				break
+			}
+			var lat1, lon1, lat2, lon2 float64
+			if lat1, lon1, err = parseOpenAirCoord(from); err != nil {
+				break
+			}
+			if lat2, lon2, err = parseOpenAirCoord(to); err != nil {
+				break
+			}
+			radius := distanceNM(center[0], center[1], lat1, lon1)
+			start := bearingDeg(center[0], center[1], lat1, lon1)
+			end := bearingDeg(center[0], center[1], lat2, lon2)
+			cur.Polygon = append(cur.Polygon, arcPoints(center, radius, start, end, dir)...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// parseOpenAirCoord parses a coordinate such as "39:29:54 N 119:46:06 W"
+// or "39:29.9N 119:46.1W".
+func parseOpenAirCoord(s string) (lat, lon float64, err error) {
+	m := openAirCoord.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid coordinate %q", s)
+	}
+	degrees := func(d, min, sec, hemisphere string) float64 {
+		v, _ := strconv.ParseFloat(d, 64)
+		minutes, _ := strconv.ParseFloat(min, 64)
+		seconds, _ := strconv.ParseFloat(sec, 64)
+		v += minutes/60 + seconds/3600
+		if hemisphere == "S" || hemisphere == "W" {
+			v = -v
+		}
+		return v
+	}
+	lat, lon = degrees(m[1], m[2], m[3], m[4]), degrees(m[5], m[6], m[7], m[8])
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("coordinate %q out of range", s)
+	}
+	return lat, lon, nil
+}
+
+// parseOpenAirAltitude parses a vertical limit in feet: SFC or GND, a
+// flight level (FL95), or a height in feet or metres (1500ft MSL, 300M AGL).
+// Heights above ground are taken as above sea level, as the ground
+// elevation is not known. UNL, no limit, is 0 like the surface; zones
+// read 0 as no limit for a ceiling.
+func parseOpenAirAltitude(s string) (int, error) {
+	v := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	switch {
+	case v == "SFC" || v == "GND" || strings.HasPrefix(v, "UNL"):
+		return 0, nil
+	case strings.HasPrefix(v, "FL"):
+		level, err := strconv.Atoi(strings.TrimPrefix(v, "FL"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid flight level %q", s)
+		}
+		return level * 100, nil
+	}
+	m := openAirAltitude.FindStringSubmatch(v)
+	if m == nil {
+		return 0, fmt.Errorf("invalid altitude %q", s)
+	}
+	height, _ := strconv.ParseFloat(m[1], 64)
+	if m[2] == "M" {
+		height *= 3.28084
+	}
+	return int(math.Round(height)), nil
+}
+
+// arcPoints draws the arc of radius nautical miles around center from the
+// start to the end bearing, clockwise for dir 1 and anticlockwise for -1.
+func arcPoints(center [2]float64, radius, start, end, dir float64) [][2]float64 {
+	sweep := math.Mod(dir*(end-start)+720, 360)
+	if sweep == 0 {
+		sweep = 360
+	}
+	var points [][2]float64
+	for a := 0.0; a < sweep; a += arcStep {
+		lat, lon := destinationPoint(center[0], center[1], start+dir*a, radius)
+		points = append(points, [2]float64{lat, lon})
+	}
+	lat, lon := destinationPoint(center[0], center[1], end, radius)
+	return append(points, [2]float64{lat, lon})
+}
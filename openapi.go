@@ -0,0 +1,434 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// apiOperation documents one REST endpoint in the OpenAPI document served at
+// /api/v1/openapi.json. Keep the table in step with the routes in main.go; the
+// schemas are derived from the Go types, so only the routes need updating.
+type apiOperation struct {
+	method, path string // path as registered, e.g. /api/v1/alerts/:id
+	tag          string
+	summary      string
+	params       []apiParam
+	body         interface{} // Zero value of the JSON request body, nil for none
+	bodyType     string      // Request media type other than JSON
+	status       int         // Success status; default 200
+	response     interface{} // Zero value of the JSON response, nil for none
+	responseType string      // Response media type other than JSON
+}
+
+// apiParam is a query parameter; path parameters are added from the path.
+type apiParam struct {
+	name, typ, description string // typ is a JSON schema type, or "date-time"
+}
+
+// Parameters shared by several endpoints.
+var (
+	timeParamDoc    = "Unix seconds or RFC 3339"
+	geoJSONParam    = apiParam{"format", "string", "geojson returns a GeoJSON FeatureCollection"}
+	alertFilterDocs = []apiParam{
+		{"since", "date-time", "Alerts at or after this time"},
+		{"until", "date-time", "Alerts before this time"},
+		{"icao", "string", "ICAO address"},
+		{"callsign", "string", "Callsign"},
+		{"severity", "string", "Comma-separated severities"},
+		{"criterion", "string", "Criterion ID"},
+		{"zone", "string", "Zone name"},
+		{"tag", "string", "Criterion tag; repeatable"},
+		{"acknowledged", "boolean", "Acknowledgement state"},
+	}
+	streamFilterDocs = []apiParam{
+		{"events", "string", "Comma-separated event types: alert, aircraftUpdate, aircraftRemoved, expired"},
+		{"icao", "string", "Comma-separated ICAO addresses"},
+		{"bbox", "string", "min_lat,min_lon,max_lat,max_lon"},
+		{"min_severity", "string", "Leave out alerts below this severity"},
+	}
+)
+
+type apiStatus struct {
+	Status string `json:"status"`
+}
+
+// ingestStatus is the reply to an accepted aircraft update.
+type ingestStatus struct {
+	Status   string       `json:"status"`
+	Warnings []FieldError `json:"warnings,omitempty"` // Problems let through by validation.mode: flag
+}
+
+var apiOperations = []apiOperation{
+	{method: "GET", path: "/api/v1/aircraft", tag: "aircraft", summary: "List tracked aircraft",
+		params: []apiParam{
+			{"max_age", "string", "Leave out aircraft not heard from within this duration, e.g. 60s"},
+			{"bbox", "string", "Only aircraft inside min_lat,min_lon,max_lat,max_lon"},
+			geoJSONParam,
+		},
+		response: []AircraftState{}},
+	{method: "POST", path: "/api/v1/aircraft", tag: "aircraft", summary: "Queue an aircraft position report for evaluation; 400 listing the invalid fields, 503 when the queue is full and set to reject",
+		body: Aircraft{}, response: ingestStatus{}},
+	{method: "GET", path: "/api/v1/aircraft/:icao", tag: "aircraft", summary: "Get an aircraft's state, enrichment, track and alerts",
+		params:   []apiParam{{"alerts", "integer", "Maximum alerts returned; default 50"}},
+		response: AircraftDetail{}},
+	{method: "DELETE", path: "/api/v1/aircraft/:icao", tag: "aircraft", summary: "Drop a stale or spoofed aircraft from the tracked state",
+		response: apiStatus{}},
+	{method: "GET", path: "/api/v1/aircraft/:icao/history", tag: "aircraft", summary: "Get an aircraft's recorded positions",
+		params:   []apiParam{{"since", "string", timeParamDoc + "; default an hour ago"}, {"until", "string", timeParamDoc}},
+		response: []Aircraft{}},
+	{method: "GET", path: "/api/v1/aircraft/:icao/track", tag: "aircraft", summary: "Get an aircraft's flight path for drawing",
+		params: []apiParam{
+			{"since", "string", timeParamDoc + "; default an hour ago"}, {"until", "string", timeParamDoc},
+			{"interval", "string", "Minimum spacing of points, e.g. 30s"}, {"max_points", "integer", "Maximum points returned"},
+			geoJSONParam,
+		},
+		response: []Aircraft{}},
+
+	{method: "GET", path: "/api/v1/alerts", tag: "alerts", summary: "List alerts, newest first",
+		params: append(append([]apiParam{}, alertFilterDocs...),
+			apiParam{"limit", "integer", "Page size; default 100, at most 1000"}, apiParam{"offset", "integer", "Alerts to skip"}, geoJSONParam),
+		response: []Alert{}},
+	{method: "DELETE", path: "/api/v1/alerts", tag: "alerts", summary: "Delete the alerts matching the filters",
+		params:   append(append([]apiParam{}, alertFilterDocs...), apiParam{"all", "boolean", "Required to delete every alert"}),
+		response: map[string]int{}},
+	{method: "DELETE", path: "/api/v1/alerts/:id", tag: "alerts", summary: "Delete an alert", response: apiStatus{}},
+	{method: "POST", path: "/api/v1/alerts/:id/ack", tag: "alerts", summary: "Acknowledge an alert, stopping its escalation", response: Alert{}},
+	{method: "POST", path: "/api/v1/alerts/:id/false-positive", tag: "alerts", summary: "Mark an alert as a false positive", response: Alert{}},
+	{method: "GET", path: "/api/v1/alerts/:id/history", tag: "alerts", summary: "Get the track around an alert",
+		params: []apiParam{{"window", "integer", "Seconds either side of the alert; default 600"}},
+		response: struct {
+			Alert     Alert      `json:"alert"`
+			Positions []Aircraft `json:"positions"`
+		}{}},
+
+	{method: "GET", path: "/api/v1/alert-criteria", tag: "criteria", summary: "List alert criteria",
+		params: []apiParam{{"tag", "string", "Only criteria carrying this tag; repeatable"}}, response: []AlertCriteria{}},
+	{method: "POST", path: "/api/v1/alert-criteria", tag: "criteria", summary: "Add an alert criterion",
+		body: AlertCriteria{}, status: http.StatusCreated, response: AlertCriteria{}},
+	{method: "GET", path: "/api/v1/alert-criteria/:id", tag: "criteria", summary: "Get an alert criterion", response: AlertCriteria{}},
+	{method: "PUT", path: "/api/v1/alert-criteria/:id", tag: "criteria", summary: "Replace an alert criterion",
+		body: AlertCriteria{}, response: AlertCriteria{}},
+	{method: "PATCH", path: "/api/v1/alert-criteria/:id", tag: "criteria", summary: "Merge changes into an alert criterion",
+		body: AlertCriteria{}, bodyType: "application/merge-patch+json", response: AlertCriteria{}},
+	{method: "DELETE", path: "/api/v1/alert-criteria/:id", tag: "criteria", summary: "Delete an alert criterion", response: apiStatus{}},
+	{method: "GET", path: "/api/v1/alert-criteria/:id/feedback", tag: "criteria", summary: "Get false-positive feedback for a criterion",
+		response: criterionFeedback{}},
+	{method: "POST", path: "/api/v1/alert-criteria/test", tag: "criteria", summary: "Dry-run a criterion against tracked aircraft",
+		params: []apiParam{{"history", "boolean", "Also replay the recorded position history, up to its newest 20000 points"}},
+		body:   AlertCriteria{},
+		response: struct {
+			Criterion AlertCriteria `json:"criterion"`
+			History   bool          `json:"history"`
+			Truncated bool          `json:"truncated"` // Older history points were left out
+			Matches   []dryRunMatch `json:"matches"`
+		}{}},
+	{method: "GET", path: "/api/v1/alert-criteria/export", tag: "criteria", summary: "Export alert criteria for versioning or another instance",
+		params:   []apiParam{{"format", "string", "json (default) or csv"}, {"tag", "string", "Only criteria carrying this tag; repeatable"}},
+		response: []AlertCriteria{}},
+	{method: "POST", path: "/api/v1/alert-criteria/import", tag: "criteria", summary: "Import exported alert criteria",
+		params: []apiParam{
+			{"format", "string", "json (default) or csv; text/csv bodies are read as CSV"},
+			{"mode", "string", "merge (default) updates and adds; replace also deletes criteria missing from the import"},
+		},
+		body: []AlertCriteria{}, response: map[string]int{}},
+	{method: "GET", path: "/api/v1/presets", tag: "criteria", summary: "List built-in criteria packs", response: []criteriaPreset{}},
+	{method: "POST", path: "/api/v1/alert-criteria/presets/:name", tag: "criteria", summary: "Install, enable or disable a criteria pack",
+		body: presetRequest{}, response: []AlertCriteria{}},
+
+	{method: "GET", path: "/api/v1/escalation-policies", tag: "escalation", summary: "List escalation policies", response: []EscalationPolicy{}},
+	{method: "POST", path: "/api/v1/escalation-policies", tag: "escalation", summary: "Add an escalation policy",
+		body: EscalationPolicy{}, status: http.StatusCreated, response: EscalationPolicy{}},
+	{method: "PUT", path: "/api/v1/escalation-policies/:id", tag: "escalation", summary: "Replace an escalation policy",
+		body: EscalationPolicy{}, response: EscalationPolicy{}},
+	{method: "DELETE", path: "/api/v1/escalation-policies/:id", tag: "escalation", summary: "Delete an escalation policy", response: apiStatus{}},
+
+	{method: "GET", path: "/api/v1/zones/:name/positions", tag: "zones", summary: "List positions recorded inside a zone",
+		params: []apiParam{{"since", "string", timeParamDoc + "; default an hour ago"}}, response: []Aircraft{}},
+	{method: "GET", path: "/api/v1/tfrs", tag: "zones", summary: "List the zones of active FAA flight restrictions", response: TFRStatus{}},
+
+	{method: "GET", path: "/api/v1/search", tag: "aircraft", summary: "Search live and recent aircraft by ICAO, callsign, registration or squawk",
+		params: []apiParam{
+			{"q", "string", "Query; ICAO and callsign prefixes match"},
+			{"since", "string", "Also search the history back to this time; default a day ago; " + timeParamDoc},
+			{"limit", "integer", "Maximum results; default 20, at most 100"},
+		},
+		response: []SearchResult{}},
+	{method: "GET", path: "/api/v1/stats", tag: "admin", summary: "Get traffic, alert and client counters", response: Stats{}},
+	{method: "GET", path: "/api/v1/coverage", tag: "aircraft", summary: "Get the receiver's farthest positions by bearing and altitude band, for range plots; 404 without a receiver location",
+		params: []apiParam{geoJSONParam}, response: Coverage{}},
+	{method: "DELETE", path: "/api/v1/coverage", tag: "aircraft", summary: "Start the coverage statistics over", response: apiStatus{}},
+
+	{method: "GET", path: "/api/v1/notifiers", tag: "notifications", summary: "Get the health of the notification channels", response: []NotifierStatus{}},
+	{method: "GET", path: "/api/v1/notifications/deliveries", tag: "notifications", summary: "List queued notification deliveries",
+		params: []apiParam{{"status", "string", "dead or pending"}}, response: []Delivery{}},
+	{method: "POST", path: "/api/v1/notifications/deliveries/:id/retry", tag: "notifications", summary: "Retry a dead letter", response: Delivery{}},
+	{method: "DELETE", path: "/api/v1/notifications/deliveries/:id", tag: "notifications", summary: "Drop a delivery", response: apiStatus{}},
+	{method: "GET", path: "/api/v1/push/key", tag: "notifications", summary: "Get the VAPID public key for web push", response: map[string]string{}},
+	{method: "GET", path: "/api/v1/push/subscriptions", tag: "notifications", summary: "List web push subscriptions", response: []PushSubscription{}},
+	{method: "POST", path: "/api/v1/push/subscriptions", tag: "notifications", summary: "Subscribe a browser to web push",
+		body: PushSubscription{}, status: http.StatusCreated, response: PushSubscription{}},
+	{method: "DELETE", path: "/api/v1/push/subscriptions/:id", tag: "notifications", summary: "Unsubscribe a browser", response: apiStatus{}},
+
+	{method: "GET", path: "/api/v1/events", tag: "streaming", summary: "Stream aircraft updates and alerts as server-sent events",
+		params: append([]apiParam{
+			{"alerts_since", "string", "Replay alerts after this time first; " + timeParamDoc},
+			{"last_event_id", "integer", "Resume after this event ID, like the Last-Event-ID header"},
+			{"batch", "boolean", "Send position updates together as aircraftBatch events, a JSON array with each aircraft's latest update, every streams.batch_window"},
+			{"delta", "boolean", "Send position updates as aircraftDelta events with only the fields that changed, and the full picture as an aircraftSnapshot event every streams.snapshot_interval"},
+		}, streamFilterDocs...),
+		responseType: "text/event-stream"},
+	{method: "GET", path: "/api/v1/events/poll", tag: "streaming", summary: "Long-poll for the events after an event ID, for clients that cannot stream",
+		params: append([]apiParam{
+			{"since", "integer", "Return events after this ID, the last_event_id of the previous poll"},
+			{"wait", "integer", "Seconds to wait for new events when there are none; default 25, at most 60"},
+		}, streamFilterDocs...),
+		response: PollResponse{}},
+	{method: "GET", path: "/api/v1/ws", tag: "streaming", summary: "Stream aircraft updates and alerts over a WebSocket",
+		params: streamFilterDocs, status: http.StatusSwitchingProtocols},
+
+	{method: "POST", path: "/api/v1/graphql", tag: "graphql", summary: "Run a read-only GraphQL query over aircraft, tracks, alerts and criteria",
+		body: graphQLRequest{}, response: map[string]interface{}{}},
+	{method: "GET", path: "/api/v1/graphql", tag: "graphql", summary: "Run a GraphQL query from the URL, or upgrade to a graphql-transport-ws WebSocket for subscriptions",
+		params: []apiParam{
+			{"query", "string", "GraphQL query"},
+			{"operationName", "string", "Operation to run when the query has several"},
+			{"variables", "string", "JSON object of variables"},
+		}},
+
+	{method: "POST", path: "/api/v1/login", tag: "auth", summary: "Log in with a username and password",
+		body: loginRequest{}, response: tokenResponse{}},
+	{method: "POST", path: "/api/v1/token/refresh", tag: "auth", summary: "Exchange a refresh token for new tokens",
+		body: refreshRequest{}, response: tokenResponse{}},
+	{method: "POST", path: "/api/v1/logout", tag: "auth", summary: "Revoke a refresh token",
+		body: refreshRequest{}, response: apiStatus{}},
+
+	{method: "GET", path: "/healthz", tag: "health", summary: "Liveness probe; 503 when the server is wedged",
+		response: HealthReport{}},
+	{method: "GET", path: "/readyz", tag: "health", summary: "Readiness probe checking storage, ingest and streams; 503 when any fails",
+		response: HealthReport{}},
+
+	{method: "GET", path: "/api/v1/audit", tag: "admin", summary: "List recent audit log entries, newest first",
+		params: []apiParam{{"limit", "integer", "Maximum entries; default 100"}}, response: []AuditEntry{}},
+	{method: "GET", path: "/api/v1/streams", tag: "admin", summary: "List connected stream clients with their lag, most lagged first",
+		response: []StreamClientStats{}},
+	{method: "GET", path: "/api/v1/backup", tag: "admin", summary: "Download a backup archive", responseType: "application/gzip"},
+	{method: "POST", path: "/api/v1/restore", tag: "admin", summary: "Restore a backup archive",
+		params:   []apiParam{{"skip_config", "boolean", "Restore an archive holding config.yaml without it; required for such archives"}},
+		bodyType: "application/gzip", response: map[string]int{}},
+	{method: "POST", path: "/api/v1/admin/reload", tag: "admin", summary: "Reload criteria, zones and notifiers from the configuration files, as SIGHUP does",
+		response: ReloadResult{}},
+}
+
+var pathParamPattern = regexp.MustCompile(`:(\w+)`)
+
+// openAPISchemas collects the component schemas referenced by the document.
+type openAPISchemas map[string]interface{}
+
+// schema returns the JSON schema of t, adding named structs to the
+// components and referencing them.
+func (s openAPISchemas) schema(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == reflect.TypeOf(time.Duration(0)):
+		return map[string]interface{}{"type": "integer", "description": "Nanoseconds"}
+	case t == reflect.TypeOf(json.RawMessage{}):
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.schema(t.Elem())
+		if _, ok := schema["$ref"]; ok {
+			// OpenAPI 3.0 ignores siblings of $ref.
+			schema = map[string]interface{}{"allOf": []interface{}{schema}}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := s[name]; !ok {
+			s[name] = nil // Placeholder for recursive types
+			s[name] = s.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]interface{}{} // interface{}: any value
+}
+
+// structSchema describes the JSON encoding of a struct, flattening embedded
+// structs as encoding/json does.
+func (s openAPISchemas) structSchema(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+				walk(f.Type)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = s.schema(f.Type)
+		}
+	}
+	walk(t)
+	return map[string]interface{}{"type": "object", "properties": props}
+}
+
+// schemaName is the type name with an upper-case first letter.
+func schemaName(t reflect.Type) string {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
+
+// operationID names an operation after its method and path, e.g.
+// postAlertsIdAck for POST /api/v1/alerts/:id/ack.
+func operationID(op apiOperation) string {
+	id := strings.ToLower(op.method)
+	for _, part := range strings.FieldsFunc(strings.TrimPrefix(op.path, apiPrefix), func(r rune) bool {
+		return r == '/' || r == ':' || r == '-'
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// openAPIDocument builds the OpenAPI 3 document from apiOperations.
+func openAPIDocument() map[string]interface{} {
+	schemas := openAPISchemas{}
+	content := func(mediaType string, v interface{}) map[string]interface{} {
+		schema := map[string]interface{}{}
+		if v != nil {
+			schema = schemas.schema(reflect.TypeOf(v))
+		} else if mediaType != "text/event-stream" {
+			schema = map[string]interface{}{"type": "string", "format": "binary"}
+		}
+		return map[string]interface{}{mediaType: map[string]interface{}{"schema": schema}}
+	}
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content":     content("application/json", APIError{}),
+	}
+
+	paths := map[string]map[string]interface{}{}
+	for _, op := range apiOperations {
+		path := pathParamPattern.ReplaceAllString(op.path, "{$1}")
+		var params []interface{}
+		for _, m := range pathParamPattern.FindAllStringSubmatch(op.path, -1) {
+			params = append(params, map[string]interface{}{
+				"name": m[1], "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, p := range op.params {
+			schema := map[string]interface{}{"type": p.typ}
+			if p.typ == "date-time" {
+				schema = map[string]interface{}{"type": "string", "format": "date-time"}
+			}
+			params = append(params, map[string]interface{}{
+				"name": p.name, "in": "query", "description": p.description, "schema": schema,
+			})
+		}
+
+		status := op.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		if op.response != nil || op.responseType != "" {
+			mediaType := op.responseType
+			if mediaType == "" {
+				mediaType = "application/json"
+			}
+			success["content"] = content(mediaType, op.response)
+		}
+		operation := map[string]interface{}{
+			"summary":     op.summary,
+			"tags":        []string{op.tag},
+			"operationId": operationID(op),
+			"responses": map[string]interface{}{
+				strconv.Itoa(status): success,
+				"default":            errorResponse,
+			},
+		}
+		if params != nil {
+			operation["parameters"] = params
+		}
+		if op.body != nil || op.bodyType != "" {
+			mediaType := op.bodyType
+			if mediaType == "" {
+				mediaType = "application/json"
+			}
+			var body interface{}
+			if mediaType != "application/gzip" {
+				body = op.body
+			}
+			operation["requestBody"] = map[string]interface{}{"required": true, "content": content(mediaType, body)}
+		}
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(op.method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Aircraft Alert API",
+			"description": "Aircraft tracking, alert criteria and notifications. Live events are also available over gRPC (proto/aircraftalert/v1). Every route is also served without the /v1 prefix, with plain {\"error\": message} errors, for older clients; those paths are deprecated.",
+			"version":     "1.0.0",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+// handleOpenAPI serves the OpenAPI document.
+func handleOpenAPI(c *jacked.Context) error {
+	return c.JSON(http.StatusOK, openAPIDocument())
+}
+
+// handleAPIDocs serves Swagger UI for the OpenAPI document, when enabled
+// with swagger_ui.
+func handleAPIDocs(c *jacked.Context) error {
+	setSecurityHeaders(c.Response)
+	http.ServeFile(c.Response, c.Request, activeConfig.StaticDir+"/docs.html")
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// minOutlierNM ignores jumps too short to tell from jitter, such as two
+// reports arriving in the same instant.
+const minOutlierNM = 2
+
+// pendingOutliers holds, by ICAO, the last position refused as an outlier.
+// A report agreeing with it shows the aircraft really is there and the
+// position before was the bad one, so it is accepted.
+var pendingOutliers = struct {
+	sync.Mutex
+	aircraft map[string]Aircraft
+}{aircraft: map[string]Aircraft{}}
+
+// checkOutlier compares the position of an update received at now with the
+// aircraft's last accepted one. It returns the distance between them and
+// the speed it implies, and whether that is over
+// validationConfig.MaxImpliedSpeed, making the update an outlier.
+func checkOutlier(a Aircraft, now time.Time) (dist, implied float64, outlier bool) {
+	limit := validationConfig.MaxImpliedSpeed
+	if limit <= 0 {
+		return 0, 0, false
+	}
+	last, ok := lookupState(a.ICAO)
+	pendingOutliers.Lock()
+	defer pendingOutliers.Unlock()
+	if ok {
+		dist, implied = impliedSpeed(last, a, now)
+	}
+	if !ok || dist <= minOutlierNM || implied <= limit {
+		delete(pendingOutliers.aircraft, a.ICAO)
+		return dist, implied, false
+	}
+	if prev, ok := pendingOutliers.aircraft[a.ICAO]; ok {
+		if d, s := impliedSpeed(prev, a, now); d <= minOutlierNM || s <= limit {
+			delete(pendingOutliers.aircraft, a.ICAO)
+			return dist, implied, false
+		}
+	}
+	a.Timestamp = now
+	pendingOutliers.aircraft[a.ICAO] = a
+	return dist, implied, true
+}
+
+// impliedSpeed returns the distance in nautical miles from one report to the
+// position of the next, received at now, and the speed in knots it takes.
+// Reports less than a second apart count as a second.
+func impliedSpeed(from, to Aircraft, now time.Time) (dist, speed float64) {
+	dist = distanceNM(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	return dist, dist / max(now.Sub(from.Timestamp), time.Second).Hours()
+}
+
+// forgetOutlier drops the refused position held for icao once the aircraft
+// leaves the picture.
+func forgetOutlier(icao string) {
+	pendingOutliers.Lock()
+	delete(pendingOutliers.aircraft, icao)
+	pendingOutliers.Unlock()
+}
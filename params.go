@@ -0,0 +1,15 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+// parseTimeParam parses a timestamp query parameter given either as RFC 3339
+// or as Unix seconds.
+func parseTimeParam(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// What the pipeline does with an update when its worker's queue is full.
+const (
+	whenFullDrop   = "drop"   // Accept and discard it
+	whenFullReject = "reject" // Answer 503 so the feeder backs off and retries
+)
+
+// PipelineConfig sizes the queue between the ingest APIs and alert
+// evaluation, so feeders get their response as soon as an update is
+// accepted, however long the criteria take.
+type PipelineConfig struct {
+	Workers  int    `yaml:"workers"`   // Default: one per CPU
+	Queue    int    `yaml:"queue"`     // Updates waiting per worker
+	WhenFull string `yaml:"when_full"` // "drop" or "reject"
+}
+
+func defaultPipelineConfig() PipelineConfig {
+	return PipelineConfig{Workers: runtime.NumCPU(), Queue: 256, WhenFull: whenFullDrop}
+}
+
+func (c PipelineConfig) validate() error {
+	if c.Workers < 1 || c.Queue < 1 {
+		return errors.New("pipeline.workers and pipeline.queue must be at least 1")
+	}
+	if c.WhenFull != whenFullDrop && c.WhenFull != whenFullReject {
+		return fmt.Errorf("unknown pipeline.when_full %q", c.WhenFull)
+	}
+	return nil
+}
+
+// queuedUpdate is an update waiting for its worker, with the span that
+// accepted it so evaluation joins the feeder's trace.
+type queuedUpdate struct {
+	aircraft Aircraft
+	span     trace.SpanContext
+}
+
+var (
+	// errPipelineFull is returned for updates refused under when_full: reject.
+	errPipelineFull = errors.New("evaluation queue full")
+	// errPipelineStopped is returned for updates arriving during shutdown.
+	errPipelineStopped = errors.New("server shutting down")
+)
+
+// pipeline feeds accepted updates to the evaluation workers. Updates for one
+// aircraft always go to the same worker, so they are evaluated in order.
+var pipeline struct {
+	sync.RWMutex // Write-locked to close the queues
+	queues       []chan queuedUpdate
+	stopped      bool
+	workers      sync.WaitGroup
+	capacity     int
+	whenFull     string
+
+	processed, dropped, rejected atomic.Uint64
+	lastFullLogged               atomic.Int64 // Unix nanoseconds
+}
+
+// pipelineFullLogEvery limits how often a full queue is logged.
+const pipelineFullLogEvery = time.Minute
+
+// startPipeline starts the evaluation workers. Until it runs, updates are
+// evaluated as they arrive.
+func startPipeline(cfg PipelineConfig) {
+	pipeline.whenFull = cfg.WhenFull
+	pipeline.capacity = cfg.Workers * cfg.Queue
+	queues := make([]chan queuedUpdate, cfg.Workers)
+	for i := range queues {
+		queue := make(chan queuedUpdate, cfg.Queue)
+		queues[i] = queue
+		pipeline.workers.Add(1)
+		go func() {
+			defer pipeline.workers.Done()
+			for update := range queue {
+				processAircraft(trace.ContextWithSpanContext(context.Background(), update.span), update.aircraft)
+				pipeline.processed.Add(1)
+			}
+		}()
+	}
+	pipeline.queues = queues
+	log.Printf("Evaluating updates with %d workers, queueing up to %d each", cfg.Workers, cfg.Queue)
+}
+
+// stopPipeline refuses further updates and waits, until ctx ends, for the
+// workers to evaluate the ones already queued.
+func stopPipeline(ctx context.Context) {
+	pipeline.Lock()
+	pipeline.stopped = true
+	for _, queue := range pipeline.queues {
+		close(queue)
+	}
+	pipeline.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		pipeline.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("Shutdown: gave up with %d updates still queued for evaluation", pipelineStats().Queued)
+	}
+}
+
+// enqueueAircraft hands an update to its worker without waiting. Only the
+// trace is kept from ctx; evaluation outlives the feeder's request.
+func enqueueAircraft(ctx context.Context, aircraft Aircraft) error {
+	pipeline.RLock()
+	defer pipeline.RUnlock()
+	if pipeline.stopped {
+		return errPipelineStopped
+	}
+	if pipeline.queues == nil {
+		processAircraft(ctx, aircraft)
+		return nil
+	}
+	select {
+	case pipeline.queues[shardIndex(aircraft.ICAO, len(pipeline.queues))] <- queuedUpdate{aircraft, trace.SpanContextFromContext(ctx)}:
+		return nil
+	default:
+	}
+	if pipeline.whenFull == whenFullReject {
+		logPipelineFull("refused", pipeline.rejected.Add(1))
+		return errPipelineFull
+	}
+	logPipelineFull("dropped", pipeline.dropped.Add(1))
+	return nil
+}
+
+// logPipelineFull logs that the queue is full, with the updates refused or
+// dropped so far, at most once per pipelineFullLogEvery.
+func logPipelineFull(action string, total uint64) {
+	now := time.Now().UnixNano()
+	last := pipeline.lastFullLogged.Load()
+	if now-last < int64(pipelineFullLogEvery) || !pipeline.lastFullLogged.CompareAndSwap(last, now) {
+		return
+	}
+	log.Printf("Evaluation queue full; updates %s so far: %d", action, total)
+}
+
+// PipelineStats describes the evaluation queue.
+type PipelineStats struct {
+	Workers   int    `json:"workers"`
+	Queued    int    `json:"queued"` // Updates waiting for a worker
+	Capacity  int    `json:"capacity"`
+	Processed uint64 `json:"processed"`
+	Dropped   uint64 `json:"dropped"`  // Discarded with the queue full
+	Rejected  uint64 `json:"rejected"` // Refused to the feeder with the queue full
+}
+
+func pipelineStats() PipelineStats {
+	s := PipelineStats{
+		Workers:   len(pipeline.queues),
+		Capacity:  pipeline.capacity,
+		Processed: pipeline.processed.Load(),
+		Dropped:   pipeline.dropped.Load(),
+		Rejected:  pipeline.rejected.Load(),
+	}
+	for _, queue := range pipeline.queues {
+		s.Queued += len(queue)
+	}
+	return s
+}
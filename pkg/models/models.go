@@ -0,0 +1,242 @@
+// Package models holds the types the aircraft-alert server exchanges with
+// feeders and API clients, so the server, the simulator and other Go
+// programs share one definition.
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Aircraft represents basic ADS-B data for an aircraft.
+type Aircraft struct {
+	ICAO      string    `json:"icao"`      // Unique ICAO 24-bit address
+	Callsign  string    `json:"callsign"`  // Callsign (e.g., SWA123, N123AB)
+	Latitude  float64   `json:"lat"`       // Latitude in degrees
+	Longitude float64   `json:"lon"`       // Longitude in degrees
+	Altitude  int       `json:"alt_baro"`  // Barometric altitude in feet
+	Speed     float64   `json:"gs"`        // Ground speed in knots
+	Track     float64   `json:"track"`     // Track angle in degrees (clockwise from true north)
+	Squawk    string    `json:"squawk"`    // Mode A transponder code, e.g. 7700
+	Timestamp time.Time `json:"timestamp"` // Timestamp of the data
+
+	// Distance and bearing from the receiver, set by the server when its
+	// location is configured.
+	ReceiverDistance float64 `json:"r_dst,omitempty"` // Nautical miles
+	ReceiverBearing  float64 `json:"r_dir,omitempty"` // Degrees clockwise from true north
+	// AltitudeAGL is the barometric altitude above the terrain in feet, set
+	// by the server when elevation data is configured and covers the
+	// position.
+	AltitudeAGL *int `json:"alt_agl,omitempty"`
+	// Airport is the ident of the nearest airport within 100 nm in the
+	// server's airport database, and AirportDistance how far it is.
+	Airport         string  `json:"airport,omitempty"`
+	AirportDistance float64 `json:"airport_dst,omitempty"` // Nautical miles
+
+	// Estimated marks a position the server projected from the last
+	// report's speed and track while the aircraft is silent; the timestamp
+	// stays that of the report. Received positions never have it.
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// AlertCriteria defines the conditions for an alert.
+// We can match on any field of the Aircraft struct.
+type AlertCriteria struct {
+	ID       string `json:"id"`
+	ICAO     string `json:"icao,omitempty"`
+	Callsign string `json:"callsign,omitempty"`
+	// ICAORanges matches hex address blocks such as "AE0000-AFFFFF".
+	ICAORanges []string `json:"icao_ranges,omitempty"`
+	// Squawks restricts matches to aircraft squawking one of these codes.
+	Squawks []string `json:"squawks,omitempty"`
+	// Zone restricts matches to aircraft inside the named zone.
+	Zone string `json:"zone,omitempty"`
+
+	// Optional bounds narrowing a match. Zero values mean "no bound".
+	MinAltitude int     `json:"min_altitude,omitempty"`
+	MaxAltitude int     `json:"max_altitude,omitempty"`
+	MinSpeed    float64 `json:"min_speed,omitempty"`
+	MaxSpeed    float64 `json:"max_speed,omitempty"`
+	// MaxDistance is in nautical miles from the receiver; it needs the
+	// server's receiver location.
+	MaxDistance float64 `json:"max_distance,omitempty"`
+	// Bounds in feet above the terrain; they need the server's elevation
+	// data, and never match where it has none.
+	MinAGL int `json:"min_agl,omitempty"`
+	MaxAGL int `json:"max_agl,omitempty"`
+	// Airport restricts matches to aircraft within AirportRadius nautical
+	// miles (default 5) of the airport with this ident, e.g. EGLL.
+	Airport       string  `json:"airport,omitempty"`
+	AirportRadius float64 `json:"airport_radius,omitempty"`
+
+	// Expression is an optional CEL rule, e.g.
+	// `alt_baro < 5000 && gs > 250 && distance(lat, lon, 51.5, -0.1) < 20`.
+	Expression string `json:"expression,omitempty"`
+
+	// Loiter turns the criterion into a stateful orbit detector.
+	Loiter *LoiterParams `json:"loiter,omitempty"`
+	// SignalLoss alerts when a matching aircraft stops reporting.
+	SignalLoss *SignalLossParams `json:"signal_loss,omitempty"`
+	// AirportOps alerts on takeoffs and landings at the listed airports.
+	AirportOps *AirportOpsParams `json:"airport_ops,omitempty"`
+	// Proximity alerts when another aircraft comes too close.
+	Proximity *ProximityParams `json:"proximity,omitempty"`
+
+	// Exclude turns the criterion into a suppression rule: matching
+	// aircraft never raise criteria alerts.
+	Exclude bool `json:"exclude,omitempty"`
+	// Priority orders criteria in first_match evaluation mode; higher wins.
+	Priority int `json:"priority,omitempty"`
+	// MessageTemplate is a Go template replacing the default alert message,
+	// e.g. "{{.Callsign}} at {{.Altitude}}ft, {{.Distance}}nm {{.Bearing}}".
+	MessageTemplate string `json:"message_template,omitempty"`
+	// Severity is copied to alerts: info (default), warning or critical.
+	Severity string `json:"severity,omitempty"`
+	// Tags group criteria for listing and filtering, e.g. "military".
+	Tags []string `json:"tags,omitempty"`
+	// Preset names the built-in rule pack the criterion was installed from.
+	Preset string `json:"preset,omitempty"`
+	// SlackChannel names the configured Slack channel its alerts go to.
+	SlackChannel string `json:"slack_channel,omitempty"`
+	// Notify lists the notification channels its alerts go to, overriding
+	// the configured routes.
+	Notify []string `json:"notify,omitempty"`
+
+	// Enabled controls whether the criterion is evaluated. Disabled criteria
+	// keep their configuration and hit history so they can be reactivated.
+	Enabled        bool       `json:"enabled"`
+	HitCount       int        `json:"hit_count"`
+	FalsePositives int        `json:"false_positives"`
+	LastTriggered  *time.Time `json:"last_triggered,omitempty"`
+}
+
+// Alert represents an alert triggered for a specific aircraft.
+type Alert struct {
+	ID        string        `json:"id"`
+	Category  string        `json:"category"` // criteria or anomaly
+	Severity  string        `json:"severity"` // info, warning or critical
+	Event     string        `json:"event"`    // match, loiter, zone_entered, zone_exited, signal_lost, takeoff, landing or proximity
+	Aircraft  Aircraft      `json:"aircraft"`
+	Message   string        `json:"message"`
+	Criteria  AlertCriteria `json:"criteria"` // The criteria that triggered this alert; empty for anomalies
+	Timestamp time.Time     `json:"timestamp"`
+	// FalsePositive is set when an operator marks the alert as noise.
+	FalsePositive bool `json:"false_positive"`
+	// Acknowledged is set once an operator has seen the alert; it stops
+	// escalation.
+	Acknowledged   bool       `json:"acknowledged"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	// Escalation tracks the escalation policy applied to the alert.
+	Escalation *AlertEscalation `json:"escalation,omitempty"`
+	// Trace is the W3C traceparent of the update that raised the alert,
+	// set when tracing is enabled, so notifications join its trace.
+	Trace string `json:"trace,omitempty"`
+	// TFR describes the flight restriction when the criterion's zone was
+	// created from one.
+	TFR *TFRInfo `json:"tfr,omitempty"`
+	// CPA predicts the closest approach for proximity alerts, and for
+	// criteria on a zone or distance from the receiver, while it lies ahead.
+	CPA *CPA `json:"cpa,omitempty"`
+	// Registration describes the airframe, when the server's registration
+	// database knows its address.
+	Registration *Registration `json:"registration,omitempty"`
+	// Route is the flight's origin and destination, when the routes API
+	// knows its callsign.
+	Route *Route `json:"route,omitempty"`
+}
+
+// Route is the scheduled origin and destination of a flight.
+type Route struct {
+	Origin          string `json:"origin"` // ICAO code, e.g. EGLL
+	Destination     string `json:"destination"`
+	OriginIATA      string `json:"origin_iata,omitempty"` // e.g. LHR
+	DestinationIATA string `json:"destination_iata,omitempty"`
+}
+
+// Registration is what a registration database records about the airframe
+// behind an ICAO address.
+type Registration struct {
+	Registration string `json:"registration,omitempty"` // Tail number, e.g. G-EUPE
+	TypeCode     string `json:"type_code,omitempty"`    // ICAO type designator, e.g. A319
+	Type         string `json:"type,omitempty"`         // Manufacturer and model
+	Operator     string `json:"operator,omitempty"`
+	Owner        string `json:"owner,omitempty"`
+}
+
+// CPA is a closest point of approach, predicted by holding the current
+// speeds and tracks.
+type CPA struct {
+	Seconds   int     `json:"seconds"`  // From the report to the closest approach
+	Distance  float64 `json:"distance"` // Nautical miles apart then
+	Latitude  float64 `json:"lat"`      // Where the aircraft will be
+	Longitude float64 `json:"lon"`
+	Other     string  `json:"other,omitempty"` // ICAO of the other aircraft, for proximity
+}
+
+// TFRInfo describes the FAA Temporary Flight Restriction a zone was created
+// from.
+type TFRInfo struct {
+	NotamID  string `json:"notam_id"`
+	Type     string `json:"type,omitempty"` // e.g. SECURITY, VIP, HAZARDS or SPACE OPERATIONS
+	Title    string `json:"title,omitempty"`
+	State    string `json:"state,omitempty"`
+	Facility string `json:"facility,omitempty"` // ARTCC issuing the NOTAM
+}
+
+// LoiterParams configures loitering (orbit) detection for a criterion.
+// Zero values fall back to the server's defaults.
+type LoiterParams struct {
+	MinTurnRate float64 `json:"min_turn_rate,omitempty"` // Average turn rate in degrees per second
+	MinDuration int     `json:"min_duration,omitempty"`  // Observation window in seconds
+	MinOrbits   float64 `json:"min_orbits,omitempty"`    // Full 360° turns required within the window
+	MaxRadius   float64 `json:"max_radius,omitempty"`    // Max distance from the orbit centre in nautical miles
+}
+
+// SignalLossParams configures alerts for aircraft that stop reporting.
+type SignalLossParams struct {
+	Minutes     int `json:"minutes"`                // Silence required before alerting
+	MaxAltitude int `json:"max_altitude,omitempty"` // Only alert if last seen below this altitude (0 = any)
+}
+
+// AirportOpsParams configures takeoff and landing detection at airports.
+type AirportOpsParams struct {
+	Airports []string `json:"airports"`         // Airport idents, e.g. ["KLAX", "KSMO"]
+	Radius   float64  `json:"radius,omitempty"` // Nautical miles from the airport reference point
+}
+
+// ProximityParams configures alerts for two aircraft closer than the given
+// horizontal and vertical separation.
+type ProximityParams struct {
+	Distance    float64 `json:"distance"`               // Nautical miles
+	Altitude    int     `json:"altitude"`               // Feet
+	MinAltitude int     `json:"min_altitude,omitempty"` // Ignore aircraft below this, e.g. on the ground
+}
+
+// AlertEscalation is the progress of an alert through its policy. On the
+// notifications sent for a step, AlertID names the escalated alert.
+type AlertEscalation struct {
+	PolicyID string     `json:"policy_id"`
+	Step     int        `json:"step"`              // Steps notified so far
+	NextAt   *time.Time `json:"next_at,omitempty"` // Nil once the chain is done or stopped
+	AlertID  string     `json:"alert_id,omitempty"`
+}
+
+// HasConditions reports whether the criterion constrains anything at all.
+// A criterion without conditions never fires.
+func (ac *AlertCriteria) HasConditions() bool {
+	return ac.ICAO != "" || ac.Callsign != "" || len(ac.ICAORanges) > 0 || len(ac.Squawks) > 0 ||
+		ac.MinAltitude != 0 || ac.MaxAltitude != 0 || ac.MinSpeed != 0 || ac.MaxSpeed != 0 || ac.MaxDistance != 0 ||
+		ac.MinAGL != 0 || ac.MaxAGL != 0 || ac.Airport != "" ||
+		ac.Zone != "" || ac.Expression != "" || ac.Loiter != nil || ac.SignalLoss != nil ||
+		ac.AirportOps != nil || ac.Proximity != nil
+}
+
+// HasTags reports whether the criterion carries every one of the tags.
+func (ac *AlertCriteria) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(ac.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+const (
+	defaultPollWait = 25 * time.Second
+	maxPollWait     = 60 * time.Second
+)
+
+// PollEvent is one hub event returned by GET /api/v1/events/poll.
+type PollEvent struct {
+	ID    uint64          `json:"id"`
+	Event string          `json:"event"` // alert, aircraftUpdate, aircraftRemoved, expired or server_shutdown
+	Data  json.RawMessage `json:"data"`
+}
+
+// PollResponse is the reply of GET /api/v1/events/poll.
+type PollResponse struct {
+	Events      []PollEvent `json:"events"`
+	LastEventID uint64      `json:"last_event_id"` // Pass as ?since= to the next poll
+	// Missed is set when events after since had already left the backlog.
+	Missed bool `json:"missed,omitempty"`
+}
+
+// frameID returns the ID the hub gave an event frame.
+func frameID(message []byte) uint64 {
+	if v, ok := bytes.CutPrefix(message, []byte("id: ")); ok {
+		if end := bytes.IndexByte(v, '\n'); end > 0 {
+			id, _ := strconv.ParseUint(string(v[:end]), 10, 64)
+			return id
+		}
+	}
+	return 0
+}
+
+// handlePollEvents is the long-polling fallback for clients whose proxies
+// break SSE and WebSockets. It returns the events after ?since= from the
+// hub's backlog, or waits up to ?wait= seconds (default 25) for new ones.
+// Without since it only waits. The SSE filter parameters apply too.
+func handlePollEvents(c *jacked.Context) error {
+	query := c.Request.URL.Query()
+	filter, err := parseStreamFilter(query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	wait := defaultPollWait
+	if v := query.Get("wait"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || time.Duration(n)*time.Second > maxPollWait {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "wait must be between 0 and " + strconv.Itoa(int(maxPollWait/time.Second)) + " seconds"})
+		}
+		wait = time.Duration(n) * time.Second
+	}
+
+	client := hub.newClient(c.Request.RemoteAddr + " (poll)")
+	client.Filter.Store(filter)
+	var since uint64
+	if v := query.Get("since"); v != "" {
+		if since, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid since event ID"})
+		}
+		client.LastEventID = since
+		client.Resume = make(chan [][]byte, 1)
+	}
+	hub.register <- client
+	defer func() { hub.unregister <- client }()
+
+	resp := PollResponse{Events: []PollEvent{}, LastEventID: since}
+	// The cursor follows every event the poll sees: all of the backlog, which
+	// is filtered here, and the new events passing the filter. IDs start
+	// over when the server restarts, so it is not only ever raised.
+	add := func(message []byte) {
+		id := frameID(message)
+		resp.LastEventID = id
+		if event, data := parseSSEFrame(message); filter.matches(event, data) {
+			resp.Events = append(resp.Events, PollEvent{ID: id, Event: event, Data: data})
+		}
+	}
+	if client.Resume != nil {
+		backlog := <-client.Resume
+		if len(backlog) > 0 && frameID(backlog[0]) > since+1 {
+			resp.Missed = true
+		}
+		for _, message := range backlog {
+			add(message)
+		}
+	}
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	for len(resp.Events) == 0 {
+		select {
+		case message, open := <-client.Send:
+			if !open {
+				return c.JSON(http.StatusOK, resp)
+			}
+			add(message)
+		case <-timeout.C:
+			return c.JSON(http.StatusOK, resp)
+		case <-c.Request.Context().Done():
+			return nil
+		}
+	}
+	// Return whatever else is already queued along with the first events.
+	for {
+		select {
+		case message, open := <-client.Send:
+			if !open {
+				return c.JSON(http.StatusOK, resp)
+			}
+			add(message)
+		default:
+			return c.JSON(http.StatusOK, resp)
+		}
+	}
+}
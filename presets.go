@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// criteriaPreset is a built-in rule pack users can install with one call.
+type criteriaPreset struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// NeedsZone is set for packs that must be bound to a configured zone.
+	NeedsZone bool `json:"needs_zone"`
+	criteria  func(zone string) []AlertCriteria
+}
+
+var criteriaPresets = map[string]criteriaPreset{
+	"emergency-squawks": {
+		Name:        "emergency-squawks",
+		Description: "Hijack (7500), radio failure (7600) and general emergency (7700) squawks",
+		criteria: func(string) []AlertCriteria {
+			return []AlertCriteria{
+				{Squawks: []string{"7500"}, Severity: severityCritical, Tags: []string{"emergency", "hijack"}},
+				{Squawks: []string{"7600"}, Severity: severityWarning, Tags: []string{"emergency", "radio-failure"}},
+				{Squawks: []string{"7700"}, Severity: severityCritical, Tags: []string{"emergency"}},
+			}
+		},
+	},
+	"military": {
+		Name:        "military",
+		Description: "Aircraft in well-known military ICAO address blocks (US, UK)",
+		criteria: func(string) []AlertCriteria {
+			return []AlertCriteria{
+				{ICAORanges: []string{"AE0000-AFFFFF"}, Severity: severityInfo, Tags: []string{"military", "us"}},
+				{ICAORanges: []string{"43C000-43CFFF"}, Severity: severityInfo, Tags: []string{"military", "uk"}},
+			}
+		},
+	},
+	"tcas-proximity": {
+		Name:        "tcas-proximity",
+		Description: "Airborne aircraft within 1 nm and 1000 ft of each other",
+		criteria: func(string) []AlertCriteria {
+			return []AlertCriteria{
+				{Proximity: &ProximityParams{Distance: 1, Altitude: 1000, MinAltitude: 500}, Severity: severityWarning, Tags: []string{"safety"}},
+			}
+		},
+	},
+	"low-and-slow": {
+		Name:        "low-and-slow",
+		Description: "Aircraft below 1000 ft and 120 kt inside a populated-area zone",
+		NeedsZone:   true,
+		criteria: func(zone string) []AlertCriteria {
+			return []AlertCriteria{
+				{Zone: zone, MaxAltitude: 1000, MaxSpeed: 120, Severity: severityWarning, Tags: []string{"noise-complaint"}},
+			}
+		},
+	},
+}
+
+// parseICAORange parses a hex address block "LOW-HIGH".
+func parseICAORange(r string) (lo, hi uint64, err error) {
+	from, to, ok := strings.Cut(r, "-")
+	if ok {
+		lo, err = strconv.ParseUint(strings.TrimSpace(from), 16, 24)
+		if err == nil {
+			hi, err = strconv.ParseUint(strings.TrimSpace(to), 16, 24)
+		}
+	}
+	if !ok || err != nil || lo > hi {
+		return 0, 0, fmt.Errorf("invalid ICAO range %q", r)
+	}
+	return lo, hi, nil
+}
+
+// icaoInRanges reports whether the hex address falls inside any range.
+func icaoInRanges(icao string, ranges []string) bool {
+	addr, err := strconv.ParseUint(icao, 16, 24)
+	if err != nil {
+		return false
+	}
+	for _, r := range ranges {
+		lo, hi, err := parseICAORange(r)
+		if err == nil && addr >= lo && addr <= hi {
+			return true
+		}
+	}
+	return false
+}
+
+func handleListPresets(c *jacked.Context) error {
+	list := make([]criteriaPreset, 0, len(criteriaPresets))
+	for _, p := range criteriaPresets {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return c.JSON(http.StatusOK, list)
+}
+
+// presetRequest is the optional body of POST /api/v1/alert-criteria/presets/:name.
+type presetRequest struct {
+	Enabled *bool  `json:"enabled"` // Defaults to true
+	Zone    string `json:"zone"`    // Required by packs with NeedsZone
+}
+
+// handleApplyPreset installs a preset pack, or enables/disables it when it
+// is already installed.
+func handleApplyPreset(c *jacked.Context) error {
+	preset, ok := criteriaPresets[c.Param("name")]
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Preset not found"})
+	}
+	var req presetRequest
+	body, err := readBody(c, maxCriterionBody)
+	if err == nil && len(body) > 0 {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		return bodyError(c, err, "Invalid preset request")
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	criteria, err := store.ListCriteria()
+	if err != nil {
+		return storeError(c, err)
+	}
+	var installed []AlertCriteria
+	for _, criterion := range criteria {
+		if criterion.Preset == preset.Name {
+			criterion.Enabled = enabled
+			if err := store.SaveCriterion(criterion); err != nil {
+				return storeError(c, err)
+			}
+			installed = append(installed, criterion)
+		}
+	}
+	if len(installed) > 0 {
+		logRequestf(c.Request, "Preset %s enabled=%t", preset.Name, enabled)
+		return c.JSON(http.StatusOK, installed)
+	}
+
+	if preset.NeedsZone && req.Zone == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Preset requires a zone"})
+	}
+	pack := preset.criteria(req.Zone)
+	for i := range pack {
+		pack[i].Preset = preset.Name
+		pack[i].Enabled = enabled
+		if err := validateCriterion(&pack[i]); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+	for _, criterion := range pack {
+		criterion, err := addAlertCriterion(criterion)
+		if err != nil {
+			return storeError(c, err)
+		}
+		installed = append(installed, criterion)
+	}
+	logRequestf(c.Request, "Installed preset %s (%d criteria)", preset.Name, len(installed))
+	return c.JSON(http.StatusCreated, installed)
+}
@@ -0,0 +1,1756 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: proto/aircraftalert/v1/aircraftalert.proto
+
+// The gRPC API of aircraft-alert, served on grpc_listen. It mirrors the REST
+// API's aircraft, alert and criteria resources and streams the live events
+// sent to SSE clients.
+
+package aircraftalertv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Aircraft struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Icao      string                 `protobuf:"bytes,1,opt,name=icao,proto3" json:"icao,omitempty"` // ICAO 24-bit address in hex, e.g. "A1B2C3"
+	Callsign  string                 `protobuf:"bytes,2,opt,name=callsign,proto3" json:"callsign,omitempty"`
+	Lat       float64                `protobuf:"fixed64,3,opt,name=lat,proto3" json:"lat,omitempty"`                       // Degrees
+	Lon       float64                `protobuf:"fixed64,4,opt,name=lon,proto3" json:"lon,omitempty"`                       // Degrees
+	AltBaro   int32                  `protobuf:"varint,5,opt,name=alt_baro,json=altBaro,proto3" json:"alt_baro,omitempty"` // Barometric altitude in feet
+	Gs        float64                `protobuf:"fixed64,6,opt,name=gs,proto3" json:"gs,omitempty"`                         // Ground speed in knots
+	Track     float64                `protobuf:"fixed64,7,opt,name=track,proto3" json:"track,omitempty"`                   // Degrees clockwise from true north
+	Squawk    string                 `protobuf:"bytes,8,opt,name=squawk,proto3" json:"squawk,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *Aircraft) Reset() {
+	*x = Aircraft{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Aircraft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Aircraft) ProtoMessage() {}
+
+func (x *Aircraft) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Aircraft.ProtoReflect.Descriptor instead.
+func (*Aircraft) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Aircraft) GetIcao() string {
+	if x != nil {
+		return x.Icao
+	}
+	return ""
+}
+
+func (x *Aircraft) GetCallsign() string {
+	if x != nil {
+		return x.Callsign
+	}
+	return ""
+}
+
+func (x *Aircraft) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Aircraft) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+func (x *Aircraft) GetAltBaro() int32 {
+	if x != nil {
+		return x.AltBaro
+	}
+	return 0
+}
+
+func (x *Aircraft) GetGs() float64 {
+	if x != nil {
+		return x.Gs
+	}
+	return 0
+}
+
+func (x *Aircraft) GetTrack() float64 {
+	if x != nil {
+		return x.Track
+	}
+	return 0
+}
+
+func (x *Aircraft) GetSquawk() string {
+	if x != nil {
+		return x.Squawk
+	}
+	return ""
+}
+
+func (x *Aircraft) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// AlertCriterion holds the common fields of an alert criterion. Detector
+// settings (loiter, signal_loss, airport_ops, proximity) are only available
+// through the REST API.
+type AlertCriterion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Icao            string                 `protobuf:"bytes,2,opt,name=icao,proto3" json:"icao,omitempty"`
+	Callsign        string                 `protobuf:"bytes,3,opt,name=callsign,proto3" json:"callsign,omitempty"`
+	IcaoRanges      []string               `protobuf:"bytes,4,rep,name=icao_ranges,json=icaoRanges,proto3" json:"icao_ranges,omitempty"`
+	Squawks         []string               `protobuf:"bytes,5,rep,name=squawks,proto3" json:"squawks,omitempty"`
+	Zone            string                 `protobuf:"bytes,6,opt,name=zone,proto3" json:"zone,omitempty"`
+	MinAltitude     int32                  `protobuf:"varint,7,opt,name=min_altitude,json=minAltitude,proto3" json:"min_altitude,omitempty"`
+	MaxAltitude     int32                  `protobuf:"varint,8,opt,name=max_altitude,json=maxAltitude,proto3" json:"max_altitude,omitempty"`
+	MinSpeed        float64                `protobuf:"fixed64,9,opt,name=min_speed,json=minSpeed,proto3" json:"min_speed,omitempty"`
+	MaxSpeed        float64                `protobuf:"fixed64,10,opt,name=max_speed,json=maxSpeed,proto3" json:"max_speed,omitempty"`
+	Expression      string                 `protobuf:"bytes,11,opt,name=expression,proto3" json:"expression,omitempty"` // CEL rule
+	Exclude         bool                   `protobuf:"varint,12,opt,name=exclude,proto3" json:"exclude,omitempty"`
+	Priority        int32                  `protobuf:"varint,13,opt,name=priority,proto3" json:"priority,omitempty"`
+	MessageTemplate string                 `protobuf:"bytes,14,opt,name=message_template,json=messageTemplate,proto3" json:"message_template,omitempty"`
+	Severity        string                 `protobuf:"bytes,15,opt,name=severity,proto3" json:"severity,omitempty"` // info, warning or critical
+	Tags            []string               `protobuf:"bytes,16,rep,name=tags,proto3" json:"tags,omitempty"`
+	Notify          []string               `protobuf:"bytes,17,rep,name=notify,proto3" json:"notify,omitempty"`
+	Enabled         *bool                  `protobuf:"varint,18,opt,name=enabled,proto3,oneof" json:"enabled,omitempty"` // Default true on creation
+	HitCount        int32                  `protobuf:"varint,19,opt,name=hit_count,json=hitCount,proto3" json:"hit_count,omitempty"`
+	FalsePositives  int32                  `protobuf:"varint,20,opt,name=false_positives,json=falsePositives,proto3" json:"false_positives,omitempty"`
+	LastTriggered   *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=last_triggered,json=lastTriggered,proto3" json:"last_triggered,omitempty"`
+}
+
+func (x *AlertCriterion) Reset() {
+	*x = AlertCriterion{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AlertCriterion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertCriterion) ProtoMessage() {}
+
+func (x *AlertCriterion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertCriterion.ProtoReflect.Descriptor instead.
+func (*AlertCriterion) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AlertCriterion) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AlertCriterion) GetIcao() string {
+	if x != nil {
+		return x.Icao
+	}
+	return ""
+}
+
+func (x *AlertCriterion) GetCallsign() string {
+	if x != nil {
+		return x.Callsign
+	}
+	return ""
+}
+
+func (x *AlertCriterion) GetIcaoRanges() []string {
+	if x != nil {
+		return x.IcaoRanges
+	}
+	return nil
+}
+
+func (x *AlertCriterion) GetSquawks() []string {
+	if x != nil {
+		return x.Squawks
+	}
+	return nil
+}
+
+func (x *AlertCriterion) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *AlertCriterion) GetMinAltitude() int32 {
+	if x != nil {
+		return x.MinAltitude
+	}
+	return 0
+}
+
+func (x *AlertCriterion) GetMaxAltitude() int32 {
+	if x != nil {
+		return x.MaxAltitude
+	}
+	return 0
+}
+
+func (x *AlertCriterion) GetMinSpeed() float64 {
+	if x != nil {
+		return x.MinSpeed
+	}
+	return 0
+}
+
+func (x *AlertCriterion) GetMaxSpeed() float64 {
+	if x != nil {
+		return x.MaxSpeed
+	}
+	return 0
+}
+
+func (x *AlertCriterion) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+func (x *AlertCriterion) GetExclude() bool {
+	if x != nil {
+		return x.Exclude
+	}
+	return false
+}
+
+func (x *AlertCriterion) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *AlertCriterion) GetMessageTemplate() string {
+	if x != nil {
+		return x.MessageTemplate
+	}
+	return ""
+}
+
+func (x *AlertCriterion) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *AlertCriterion) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *AlertCriterion) GetNotify() []string {
+	if x != nil {
+		return x.Notify
+	}
+	return nil
+}
+
+func (x *AlertCriterion) GetEnabled() bool {
+	if x != nil && x.Enabled != nil {
+		return *x.Enabled
+	}
+	return false
+}
+
+func (x *AlertCriterion) GetHitCount() int32 {
+	if x != nil {
+		return x.HitCount
+	}
+	return 0
+}
+
+func (x *AlertCriterion) GetFalsePositives() int32 {
+	if x != nil {
+		return x.FalsePositives
+	}
+	return 0
+}
+
+func (x *AlertCriterion) GetLastTriggered() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastTriggered
+	}
+	return nil
+}
+
+type Alert struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Category      string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"` // criteria or anomaly
+	Severity      string                 `protobuf:"bytes,3,opt,name=severity,proto3" json:"severity,omitempty"`
+	Event         string                 `protobuf:"bytes,4,opt,name=event,proto3" json:"event,omitempty"` // match, loiter, zone_entered, ...
+	Aircraft      *Aircraft              `protobuf:"bytes,5,opt,name=aircraft,proto3" json:"aircraft,omitempty"`
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	Criterion     *AlertCriterion        `protobuf:"bytes,7,opt,name=criterion,proto3" json:"criterion,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	FalsePositive bool                   `protobuf:"varint,9,opt,name=false_positive,json=falsePositive,proto3" json:"false_positive,omitempty"`
+	Acknowledged  bool                   `protobuf:"varint,10,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Alert) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Alert) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Alert) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Alert) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *Alert) GetAircraft() *Aircraft {
+	if x != nil {
+		return x.Aircraft
+	}
+	return nil
+}
+
+func (x *Alert) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Alert) GetCriterion() *AlertCriterion {
+	if x != nil {
+		return x.Criterion
+	}
+	return nil
+}
+
+func (x *Alert) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Alert) GetFalsePositive() bool {
+	if x != nil {
+		return x.FalsePositive
+	}
+	return false
+}
+
+func (x *Alert) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+type SubmitAircraftRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Aircraft *Aircraft `protobuf:"bytes,1,opt,name=aircraft,proto3" json:"aircraft,omitempty"` // The timestamp is set on receipt
+}
+
+func (x *SubmitAircraftRequest) Reset() {
+	*x = SubmitAircraftRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitAircraftRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAircraftRequest) ProtoMessage() {}
+
+func (x *SubmitAircraftRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAircraftRequest.ProtoReflect.Descriptor instead.
+func (*SubmitAircraftRequest) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SubmitAircraftRequest) GetAircraft() *Aircraft {
+	if x != nil {
+		return x.Aircraft
+	}
+	return nil
+}
+
+type SubmitAircraftResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SubmitAircraftResponse) Reset() {
+	*x = SubmitAircraftResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitAircraftResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitAircraftResponse) ProtoMessage() {}
+
+func (x *SubmitAircraftResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitAircraftResponse.ProtoReflect.Descriptor instead.
+func (*SubmitAircraftResponse) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{4}
+}
+
+type ListAircraftRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Leaves out aircraft not heard from within this many seconds; zero keeps
+	// every tracked aircraft.
+	MaxAgeSeconds int32 `protobuf:"varint,1,opt,name=max_age_seconds,json=maxAgeSeconds,proto3" json:"max_age_seconds,omitempty"`
+}
+
+func (x *ListAircraftRequest) Reset() {
+	*x = ListAircraftRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAircraftRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAircraftRequest) ProtoMessage() {}
+
+func (x *ListAircraftRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAircraftRequest.ProtoReflect.Descriptor instead.
+func (*ListAircraftRequest) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListAircraftRequest) GetMaxAgeSeconds() int32 {
+	if x != nil {
+		return x.MaxAgeSeconds
+	}
+	return 0
+}
+
+type ListAircraftResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Aircraft []*Aircraft `protobuf:"bytes,1,rep,name=aircraft,proto3" json:"aircraft,omitempty"`
+}
+
+func (x *ListAircraftResponse) Reset() {
+	*x = ListAircraftResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAircraftResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAircraftResponse) ProtoMessage() {}
+
+func (x *ListAircraftResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAircraftResponse.ProtoReflect.Descriptor instead.
+func (*ListAircraftResponse) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListAircraftResponse) GetAircraft() []*Aircraft {
+	if x != nil {
+		return x.Aircraft
+	}
+	return nil
+}
+
+type ListAlertsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Icao        string                 `protobuf:"bytes,1,opt,name=icao,proto3" json:"icao,omitempty"`
+	Severities  []string               `protobuf:"bytes,2,rep,name=severities,proto3" json:"severities,omitempty"`
+	CriterionId string                 `protobuf:"bytes,3,opt,name=criterion_id,json=criterionId,proto3" json:"criterion_id,omitempty"`
+	Since       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	Limit       int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"` // Default 100, at most 1000
+}
+
+func (x *ListAlertsRequest) Reset() {
+	*x = ListAlertsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertsRequest) ProtoMessage() {}
+
+func (x *ListAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertsRequest.ProtoReflect.Descriptor instead.
+func (*ListAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListAlertsRequest) GetIcao() string {
+	if x != nil {
+		return x.Icao
+	}
+	return ""
+}
+
+func (x *ListAlertsRequest) GetSeverities() []string {
+	if x != nil {
+		return x.Severities
+	}
+	return nil
+}
+
+func (x *ListAlertsRequest) GetCriterionId() string {
+	if x != nil {
+		return x.CriterionId
+	}
+	return ""
+}
+
+func (x *ListAlertsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListAlertsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListAlertsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Alerts []*Alert `protobuf:"bytes,1,rep,name=alerts,proto3" json:"alerts,omitempty"`
+}
+
+func (x *ListAlertsResponse) Reset() {
+	*x = ListAlertsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAlertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertsResponse) ProtoMessage() {}
+
+func (x *ListAlertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertsResponse.ProtoReflect.Descriptor instead.
+func (*ListAlertsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListAlertsResponse) GetAlerts() []*Alert {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+type ListAlertCriteriaRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tags []string `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"` // Criteria must carry all of them
+}
+
+func (x *ListAlertCriteriaRequest) Reset() {
+	*x = ListAlertCriteriaRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAlertCriteriaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertCriteriaRequest) ProtoMessage() {}
+
+func (x *ListAlertCriteriaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertCriteriaRequest.ProtoReflect.Descriptor instead.
+func (*ListAlertCriteriaRequest) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListAlertCriteriaRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ListAlertCriteriaResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Criteria []*AlertCriterion `protobuf:"bytes,1,rep,name=criteria,proto3" json:"criteria,omitempty"`
+}
+
+func (x *ListAlertCriteriaResponse) Reset() {
+	*x = ListAlertCriteriaResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAlertCriteriaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAlertCriteriaResponse) ProtoMessage() {}
+
+func (x *ListAlertCriteriaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAlertCriteriaResponse.ProtoReflect.Descriptor instead.
+func (*ListAlertCriteriaResponse) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListAlertCriteriaResponse) GetCriteria() []*AlertCriterion {
+	if x != nil {
+		return x.Criteria
+	}
+	return nil
+}
+
+type CreateAlertCriterionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Criterion *AlertCriterion `protobuf:"bytes,1,opt,name=criterion,proto3" json:"criterion,omitempty"` // id and hit counts are ignored
+}
+
+func (x *CreateAlertCriterionRequest) Reset() {
+	*x = CreateAlertCriterionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateAlertCriterionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAlertCriterionRequest) ProtoMessage() {}
+
+func (x *CreateAlertCriterionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAlertCriterionRequest.ProtoReflect.Descriptor instead.
+func (*CreateAlertCriterionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateAlertCriterionRequest) GetCriterion() *AlertCriterion {
+	if x != nil {
+		return x.Criterion
+	}
+	return nil
+}
+
+type DeleteAlertCriterionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeleteAlertCriterionRequest) Reset() {
+	*x = DeleteAlertCriterionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteAlertCriterionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAlertCriterionRequest) ProtoMessage() {}
+
+func (x *DeleteAlertCriterionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAlertCriterionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAlertCriterionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteAlertCriterionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteAlertCriterionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteAlertCriterionResponse) Reset() {
+	*x = DeleteAlertCriterionResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteAlertCriterionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAlertCriterionResponse) ProtoMessage() {}
+
+func (x *DeleteAlertCriterionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAlertCriterionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAlertCriterionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{13}
+}
+
+// StreamEventsRequest narrows the stream; empty fields match everything.
+type StreamEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AircraftUpdates bool         `protobuf:"varint,1,opt,name=aircraft_updates,json=aircraftUpdates,proto3" json:"aircraft_updates,omitempty"` // Send aircraft updates
+	Alerts          bool         `protobuf:"varint,2,opt,name=alerts,proto3" json:"alerts,omitempty"`                                          // Send alerts; with neither set, both are sent
+	Icao            []string     `protobuf:"bytes,3,rep,name=icao,proto3" json:"icao,omitempty"`
+	Bbox            *BoundingBox `protobuf:"bytes,4,opt,name=bbox,proto3" json:"bbox,omitempty"`
+	MinSeverity     string       `protobuf:"bytes,5,opt,name=min_severity,json=minSeverity,proto3" json:"min_severity,omitempty"` // Alerts only
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StreamEventsRequest) GetAircraftUpdates() bool {
+	if x != nil {
+		return x.AircraftUpdates
+	}
+	return false
+}
+
+func (x *StreamEventsRequest) GetAlerts() bool {
+	if x != nil {
+		return x.Alerts
+	}
+	return false
+}
+
+func (x *StreamEventsRequest) GetIcao() []string {
+	if x != nil {
+		return x.Icao
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetBbox() *BoundingBox {
+	if x != nil {
+		return x.Bbox
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetMinSeverity() string {
+	if x != nil {
+		return x.MinSeverity
+	}
+	return ""
+}
+
+type BoundingBox struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MinLat float64 `protobuf:"fixed64,1,opt,name=min_lat,json=minLat,proto3" json:"min_lat,omitempty"`
+	MinLon float64 `protobuf:"fixed64,2,opt,name=min_lon,json=minLon,proto3" json:"min_lon,omitempty"`
+	MaxLat float64 `protobuf:"fixed64,3,opt,name=max_lat,json=maxLat,proto3" json:"max_lat,omitempty"`
+	MaxLon float64 `protobuf:"fixed64,4,opt,name=max_lon,json=maxLon,proto3" json:"max_lon,omitempty"`
+}
+
+func (x *BoundingBox) Reset() {
+	*x = BoundingBox{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BoundingBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BoundingBox) ProtoMessage() {}
+
+func (x *BoundingBox) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BoundingBox.ProtoReflect.Descriptor instead.
+func (*BoundingBox) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BoundingBox) GetMinLat() float64 {
+	if x != nil {
+		return x.MinLat
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMinLon() float64 {
+	if x != nil {
+		return x.MinLon
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMaxLat() float64 {
+	if x != nil {
+		return x.MaxLat
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMaxLon() float64 {
+	if x != nil {
+		return x.MaxLon
+	}
+	return 0
+}
+
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*Event_AircraftUpdate
+	//	*Event_Alert
+	Event isEvent_Event `protobuf_oneof:"event"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP(), []int{16}
+}
+
+func (m *Event) GetEvent() isEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *Event) GetAircraftUpdate() *Aircraft {
+	if x, ok := x.GetEvent().(*Event_AircraftUpdate); ok {
+		return x.AircraftUpdate
+	}
+	return nil
+}
+
+func (x *Event) GetAlert() *Alert {
+	if x, ok := x.GetEvent().(*Event_Alert); ok {
+		return x.Alert
+	}
+	return nil
+}
+
+type isEvent_Event interface {
+	isEvent_Event()
+}
+
+type Event_AircraftUpdate struct {
+	AircraftUpdate *Aircraft `protobuf:"bytes,1,opt,name=aircraft_update,json=aircraftUpdate,proto3,oneof"`
+}
+
+type Event_Alert struct {
+	Alert *Alert `protobuf:"bytes,2,opt,name=alert,proto3,oneof"`
+}
+
+func (*Event_AircraftUpdate) isEvent_Event() {}
+
+func (*Event_Alert) isEvent_Event() {}
+
+var File_proto_aircraftalert_v1_aircraftalert_proto protoreflect.FileDescriptor
+
+var file_proto_aircraftalert_v1_aircraftalert_proto_rawDesc = []byte{
+	0x0a, 0x2a, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74,
+	0x61, 0x6c, 0x65, 0x72, 0x74, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66,
+	0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x10, 0x61, 0x69,
+	0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x1a, 0x1f,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xf1, 0x01, 0x0a, 0x08, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x69, 0x63, 0x61, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x63, 0x61, 0x6f,
+	0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x6c, 0x6c, 0x73, 0x69, 0x67, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x63, 0x61, 0x6c, 0x6c, 0x73, 0x69, 0x67, 0x6e, 0x12, 0x10, 0x0a, 0x03,
+	0x6c, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x6c, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x6f, 0x6e,
+	0x12, 0x19, 0x0a, 0x08, 0x61, 0x6c, 0x74, 0x5f, 0x62, 0x61, 0x72, 0x6f, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x61, 0x6c, 0x74, 0x42, 0x61, 0x72, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x67,
+	0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x02, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x74, 0x72, 0x61, 0x63,
+	0x6b, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x71, 0x75, 0x61, 0x77, 0x6b, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x73, 0x71, 0x75, 0x61, 0x77, 0x6b, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x22, 0x9c, 0x05, 0x0a, 0x0e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69,
+	0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x63, 0x61, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x63, 0x61, 0x6f, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61,
+	0x6c, 0x6c, 0x73, 0x69, 0x67, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x61,
+	0x6c, 0x6c, 0x73, 0x69, 0x67, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x63, 0x61, 0x6f, 0x5f, 0x72,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x63, 0x61,
+	0x6f, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x71, 0x75, 0x61, 0x77,
+	0x6b, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x71, 0x75, 0x61, 0x77, 0x6b,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x61, 0x6c, 0x74,
+	0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x6d, 0x69, 0x6e,
+	0x41, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f,
+	0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b,
+	0x6d, 0x61, 0x78, 0x41, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6d,
+	0x69, 0x6e, 0x5f, 0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08,
+	0x6d, 0x69, 0x6e, 0x53, 0x70, 0x65, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f,
+	0x73, 0x70, 0x65, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6d, 0x61, 0x78,
+	0x53, 0x70, 0x65, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x29, 0x0a, 0x10, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x18,
+	0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x65,
+	0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69,
+	0x74, 0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69,
+	0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x10, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x79,
+	0x18, 0x11, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x79, 0x12, 0x1d,
+	0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x00, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a,
+	0x09, 0x68, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x68, 0x69, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x66, 0x61,
+	0x6c, 0x73, 0x65, 0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x73, 0x18, 0x14, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0e, 0x66, 0x61, 0x6c, 0x73, 0x65, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69,
+	0x76, 0x65, 0x73, 0x12, 0x41, 0x0a, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x74, 0x72, 0x69, 0x67,
+	0x67, 0x65, 0x72, 0x65, 0x64, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x72, 0x69,
+	0x67, 0x67, 0x65, 0x72, 0x65, 0x64, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x65, 0x6e, 0x61, 0x62, 0x6c,
+	0x65, 0x64, 0x22, 0xfc, 0x02, 0x0a, 0x05, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x76, 0x65,
+	0x72, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x76, 0x65,
+	0x72, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x36, 0x0a, 0x08, 0x61, 0x69,
+	0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61,
+	0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x52, 0x08, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61,
+	0x66, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3e, 0x0a, 0x09,
+	0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x20, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f,
+	0x6e, 0x52, 0x09, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x25, 0x0a, 0x0e, 0x66, 0x61, 0x6c, 0x73, 0x65, 0x5f,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d,
+	0x66, 0x61, 0x6c, 0x73, 0x65, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x12, 0x22, 0x0a,
+	0x0c, 0x61, 0x63, 0x6b, 0x6e, 0x6f, 0x77, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x64, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x63, 0x6b, 0x6e, 0x6f, 0x77, 0x6c, 0x65, 0x64, 0x67, 0x65,
+	0x64, 0x22, 0x4f, 0x0a, 0x15, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x41, 0x69, 0x72, 0x63, 0x72,
+	0x61, 0x66, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x36, 0x0a, 0x08, 0x61, 0x69,
+	0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61,
+	0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x52, 0x08, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61,
+	0x66, 0x74, 0x22, 0x18, 0x0a, 0x16, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x41, 0x69, 0x72, 0x63,
+	0x72, 0x61, 0x66, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3d, 0x0a, 0x13,
+	0x4c, 0x69, 0x73, 0x74, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x61, 0x67, 0x65, 0x5f, 0x73,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6d, 0x61,
+	0x78, 0x41, 0x67, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x4e, 0x0a, 0x14, 0x4c,
+	0x69, 0x73, 0x74, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74,
+	0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66,
+	0x74, 0x52, 0x08, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x22, 0xb2, 0x01, 0x0a, 0x11,
+	0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x63, 0x61, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x69, 0x63, 0x61, 0x6f, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69, 0x74,
+	0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x76, 0x65, 0x72,
+	0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x72, 0x69,
+	0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x22, 0x45, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x06, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66,
+	0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x52,
+	0x06, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x22, 0x2e, 0x0a, 0x18, 0x4c, 0x69, 0x73, 0x74, 0x41,
+	0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x61, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x59, 0x0a, 0x19, 0x4c, 0x69, 0x73, 0x74, 0x41,
+	0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x61, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x08, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x61,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66,
+	0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43,
+	0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72,
+	0x69, 0x61, 0x22, 0x5d, 0x0a, 0x1b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x6c, 0x65, 0x72,
+	0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x3e, 0x0a, 0x09, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61,
+	0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69,
+	0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x63, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f,
+	0x6e, 0x22, 0x2d, 0x0a, 0x1b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x6c, 0x65, 0x72, 0x74,
+	0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x22, 0x1e, 0x0a, 0x1c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43,
+	0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0xc2, 0x01, 0x0a, 0x13, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x69, 0x72, 0x63,
+	0x72, 0x61, 0x66, 0x74, 0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x69,
+	0x63, 0x61, 0x6f, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x69, 0x63, 0x61, 0x6f, 0x12,
+	0x31, 0x0a, 0x04, 0x62, 0x62, 0x6f, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x6f, 0x78, 0x52, 0x04, 0x62, 0x62,
+	0x6f, 0x78, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x73, 0x65, 0x76, 0x65, 0x72, 0x69,
+	0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x53, 0x65, 0x76,
+	0x65, 0x72, 0x69, 0x74, 0x79, 0x22, 0x71, 0x0a, 0x0b, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x42, 0x6f, 0x78, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x6c, 0x61, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4c, 0x61, 0x74, 0x12, 0x17, 0x0a,
+	0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x6c, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06,
+	0x6d, 0x69, 0x6e, 0x4c, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x61,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x4c, 0x61, 0x74, 0x12,
+	0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x06, 0x6d, 0x61, 0x78, 0x4c, 0x6f, 0x6e, 0x22, 0x88, 0x01, 0x0a, 0x05, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x12, 0x45, 0x0a, 0x0f, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x5f, 0x75,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x69,
+	0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x48, 0x00, 0x52, 0x0e, 0x61, 0x69, 0x72, 0x63, 0x72,
+	0x61, 0x66, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x2f, 0x0a, 0x05, 0x61, 0x6c, 0x65,
+	0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72,
+	0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72,
+	0x74, 0x48, 0x00, 0x52, 0x05, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x32, 0xd3, 0x05, 0x0a, 0x14, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74,
+	0x41, 0x6c, 0x65, 0x72, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x63, 0x0a, 0x0e,
+	0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x12, 0x27,
+	0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61,
+	0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69,
+	0x74, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x5d, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66,
+	0x74, 0x12, 0x25, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72,
+	0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x41, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x57, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x12, 0x23,
+	0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c,
+	0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72, 0x74,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6c, 0x0a, 0x11, 0x4c, 0x69, 0x73,
+	0x74, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x61, 0x12, 0x2a,
+	0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65,
+	0x72, 0x69, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x61, 0x69, 0x72,
+	0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x61, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x67, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x12,
+	0x2d, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72,
+	0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20,
+	0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e,
+	0x12, 0x75, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43,
+	0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x12, 0x2d, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72,
+	0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61,
+	0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x43, 0x72, 0x69, 0x74, 0x65, 0x72, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x25, 0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61,
+	0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17,
+	0x2e, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x37, 0x5a, 0x35, 0x61, 0x69, 0x72,
+	0x63, 0x72, 0x61, 0x66, 0x74, 0x2d, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2f, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x2f,
+	0x76, 0x31, 0x3b, 0x61, 0x69, 0x72, 0x63, 0x72, 0x61, 0x66, 0x74, 0x61, 0x6c, 0x65, 0x72, 0x74,
+	0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_aircraftalert_v1_aircraftalert_proto_rawDescOnce sync.Once
+	file_proto_aircraftalert_v1_aircraftalert_proto_rawDescData = file_proto_aircraftalert_v1_aircraftalert_proto_rawDesc
+)
+
+func file_proto_aircraftalert_v1_aircraftalert_proto_rawDescGZIP() []byte {
+	file_proto_aircraftalert_v1_aircraftalert_proto_rawDescOnce.Do(func() {
+		file_proto_aircraftalert_v1_aircraftalert_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_aircraftalert_v1_aircraftalert_proto_rawDescData)
+	})
+	return file_proto_aircraftalert_v1_aircraftalert_proto_rawDescData
+}
+
+var file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_proto_aircraftalert_v1_aircraftalert_proto_goTypes = []any{
+	(*Aircraft)(nil),                     // 0: aircraftalert.v1.Aircraft
+	(*AlertCriterion)(nil),               // 1: aircraftalert.v1.AlertCriterion
+	(*Alert)(nil),                        // 2: aircraftalert.v1.Alert
+	(*SubmitAircraftRequest)(nil),        // 3: aircraftalert.v1.SubmitAircraftRequest
+	(*SubmitAircraftResponse)(nil),       // 4: aircraftalert.v1.SubmitAircraftResponse
+	(*ListAircraftRequest)(nil),          // 5: aircraftalert.v1.ListAircraftRequest
+	(*ListAircraftResponse)(nil),         // 6: aircraftalert.v1.ListAircraftResponse
+	(*ListAlertsRequest)(nil),            // 7: aircraftalert.v1.ListAlertsRequest
+	(*ListAlertsResponse)(nil),           // 8: aircraftalert.v1.ListAlertsResponse
+	(*ListAlertCriteriaRequest)(nil),     // 9: aircraftalert.v1.ListAlertCriteriaRequest
+	(*ListAlertCriteriaResponse)(nil),    // 10: aircraftalert.v1.ListAlertCriteriaResponse
+	(*CreateAlertCriterionRequest)(nil),  // 11: aircraftalert.v1.CreateAlertCriterionRequest
+	(*DeleteAlertCriterionRequest)(nil),  // 12: aircraftalert.v1.DeleteAlertCriterionRequest
+	(*DeleteAlertCriterionResponse)(nil), // 13: aircraftalert.v1.DeleteAlertCriterionResponse
+	(*StreamEventsRequest)(nil),          // 14: aircraftalert.v1.StreamEventsRequest
+	(*BoundingBox)(nil),                  // 15: aircraftalert.v1.BoundingBox
+	(*Event)(nil),                        // 16: aircraftalert.v1.Event
+	(*timestamppb.Timestamp)(nil),        // 17: google.protobuf.Timestamp
+}
+var file_proto_aircraftalert_v1_aircraftalert_proto_depIdxs = []int32{
+	17, // 0: aircraftalert.v1.Aircraft.timestamp:type_name -> google.protobuf.Timestamp
+	17, // 1: aircraftalert.v1.AlertCriterion.last_triggered:type_name -> google.protobuf.Timestamp
+	0,  // 2: aircraftalert.v1.Alert.aircraft:type_name -> aircraftalert.v1.Aircraft
+	1,  // 3: aircraftalert.v1.Alert.criterion:type_name -> aircraftalert.v1.AlertCriterion
+	17, // 4: aircraftalert.v1.Alert.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 5: aircraftalert.v1.SubmitAircraftRequest.aircraft:type_name -> aircraftalert.v1.Aircraft
+	0,  // 6: aircraftalert.v1.ListAircraftResponse.aircraft:type_name -> aircraftalert.v1.Aircraft
+	17, // 7: aircraftalert.v1.ListAlertsRequest.since:type_name -> google.protobuf.Timestamp
+	2,  // 8: aircraftalert.v1.ListAlertsResponse.alerts:type_name -> aircraftalert.v1.Alert
+	1,  // 9: aircraftalert.v1.ListAlertCriteriaResponse.criteria:type_name -> aircraftalert.v1.AlertCriterion
+	1,  // 10: aircraftalert.v1.CreateAlertCriterionRequest.criterion:type_name -> aircraftalert.v1.AlertCriterion
+	15, // 11: aircraftalert.v1.StreamEventsRequest.bbox:type_name -> aircraftalert.v1.BoundingBox
+	0,  // 12: aircraftalert.v1.Event.aircraft_update:type_name -> aircraftalert.v1.Aircraft
+	2,  // 13: aircraftalert.v1.Event.alert:type_name -> aircraftalert.v1.Alert
+	3,  // 14: aircraftalert.v1.AircraftAlertService.SubmitAircraft:input_type -> aircraftalert.v1.SubmitAircraftRequest
+	5,  // 15: aircraftalert.v1.AircraftAlertService.ListAircraft:input_type -> aircraftalert.v1.ListAircraftRequest
+	7,  // 16: aircraftalert.v1.AircraftAlertService.ListAlerts:input_type -> aircraftalert.v1.ListAlertsRequest
+	9,  // 17: aircraftalert.v1.AircraftAlertService.ListAlertCriteria:input_type -> aircraftalert.v1.ListAlertCriteriaRequest
+	11, // 18: aircraftalert.v1.AircraftAlertService.CreateAlertCriterion:input_type -> aircraftalert.v1.CreateAlertCriterionRequest
+	12, // 19: aircraftalert.v1.AircraftAlertService.DeleteAlertCriterion:input_type -> aircraftalert.v1.DeleteAlertCriterionRequest
+	14, // 20: aircraftalert.v1.AircraftAlertService.StreamEvents:input_type -> aircraftalert.v1.StreamEventsRequest
+	4,  // 21: aircraftalert.v1.AircraftAlertService.SubmitAircraft:output_type -> aircraftalert.v1.SubmitAircraftResponse
+	6,  // 22: aircraftalert.v1.AircraftAlertService.ListAircraft:output_type -> aircraftalert.v1.ListAircraftResponse
+	8,  // 23: aircraftalert.v1.AircraftAlertService.ListAlerts:output_type -> aircraftalert.v1.ListAlertsResponse
+	10, // 24: aircraftalert.v1.AircraftAlertService.ListAlertCriteria:output_type -> aircraftalert.v1.ListAlertCriteriaResponse
+	1,  // 25: aircraftalert.v1.AircraftAlertService.CreateAlertCriterion:output_type -> aircraftalert.v1.AlertCriterion
+	13, // 26: aircraftalert.v1.AircraftAlertService.DeleteAlertCriterion:output_type -> aircraftalert.v1.DeleteAlertCriterionResponse
+	16, // 27: aircraftalert.v1.AircraftAlertService.StreamEvents:output_type -> aircraftalert.v1.Event
+	21, // [21:28] is the sub-list for method output_type
+	14, // [14:21] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_proto_aircraftalert_v1_aircraftalert_proto_init() }
+func file_proto_aircraftalert_v1_aircraftalert_proto_init() {
+	if File_proto_aircraftalert_v1_aircraftalert_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Aircraft); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*AlertCriterion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Alert); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*SubmitAircraftRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*SubmitAircraftResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ListAircraftRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ListAircraftResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ListAlertsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*ListAlertsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*ListAlertCriteriaRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*ListAlertCriteriaResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*CreateAlertCriterionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteAlertCriterionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteAlertCriterionResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*StreamEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*BoundingBox); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[1].OneofWrappers = []any{}
+	file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes[16].OneofWrappers = []any{
+		(*Event_AircraftUpdate)(nil),
+		(*Event_Alert)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_aircraftalert_v1_aircraftalert_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_aircraftalert_v1_aircraftalert_proto_goTypes,
+		DependencyIndexes: file_proto_aircraftalert_v1_aircraftalert_proto_depIdxs,
+		MessageInfos:      file_proto_aircraftalert_v1_aircraftalert_proto_msgTypes,
+	}.Build()
+	File_proto_aircraftalert_v1_aircraftalert_proto = out.File
+	file_proto_aircraftalert_v1_aircraftalert_proto_rawDesc = nil
+	file_proto_aircraftalert_v1_aircraftalert_proto_goTypes = nil
+	file_proto_aircraftalert_v1_aircraftalert_proto_depIdxs = nil
+}
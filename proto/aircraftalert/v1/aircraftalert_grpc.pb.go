@@ -0,0 +1,367 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/aircraftalert/v1/aircraftalert.proto
+
+// The gRPC API of aircraft-alert, served on grpc_listen. It mirrors the REST
+// API's aircraft, alert and criteria resources and streams the live events
+// sent to SSE clients.
+
+package aircraftalertv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AircraftAlertService_SubmitAircraft_FullMethodName       = "/aircraftalert.v1.AircraftAlertService/SubmitAircraft"
+	AircraftAlertService_ListAircraft_FullMethodName         = "/aircraftalert.v1.AircraftAlertService/ListAircraft"
+	AircraftAlertService_ListAlerts_FullMethodName           = "/aircraftalert.v1.AircraftAlertService/ListAlerts"
+	AircraftAlertService_ListAlertCriteria_FullMethodName    = "/aircraftalert.v1.AircraftAlertService/ListAlertCriteria"
+	AircraftAlertService_CreateAlertCriterion_FullMethodName = "/aircraftalert.v1.AircraftAlertService/CreateAlertCriterion"
+	AircraftAlertService_DeleteAlertCriterion_FullMethodName = "/aircraftalert.v1.AircraftAlertService/DeleteAlertCriterion"
+	AircraftAlertService_StreamEvents_FullMethodName         = "/aircraftalert.v1.AircraftAlertService/StreamEvents"
+)
+
+// AircraftAlertServiceClient is the client API for AircraftAlertService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AircraftAlertServiceClient interface {
+	// SubmitAircraft ingests a position report, like POST /api/aircraft.
+	SubmitAircraft(ctx context.Context, in *SubmitAircraftRequest, opts ...grpc.CallOption) (*SubmitAircraftResponse, error)
+	// ListAircraft returns the latest state of every tracked aircraft.
+	ListAircraft(ctx context.Context, in *ListAircraftRequest, opts ...grpc.CallOption) (*ListAircraftResponse, error)
+	// ListAlerts returns stored alerts, newest first.
+	ListAlerts(ctx context.Context, in *ListAlertsRequest, opts ...grpc.CallOption) (*ListAlertsResponse, error)
+	ListAlertCriteria(ctx context.Context, in *ListAlertCriteriaRequest, opts ...grpc.CallOption) (*ListAlertCriteriaResponse, error)
+	CreateAlertCriterion(ctx context.Context, in *CreateAlertCriterionRequest, opts ...grpc.CallOption) (*AlertCriterion, error)
+	DeleteAlertCriterion(ctx context.Context, in *DeleteAlertCriterionRequest, opts ...grpc.CallOption) (*DeleteAlertCriterionResponse, error)
+	// StreamEvents sends aircraft updates and alerts as they happen until the
+	// client cancels.
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type aircraftAlertServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAircraftAlertServiceClient(cc grpc.ClientConnInterface) AircraftAlertServiceClient {
+	return &aircraftAlertServiceClient{cc}
+}
+
+func (c *aircraftAlertServiceClient) SubmitAircraft(ctx context.Context, in *SubmitAircraftRequest, opts ...grpc.CallOption) (*SubmitAircraftResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitAircraftResponse)
+	err := c.cc.Invoke(ctx, AircraftAlertService_SubmitAircraft_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aircraftAlertServiceClient) ListAircraft(ctx context.Context, in *ListAircraftRequest, opts ...grpc.CallOption) (*ListAircraftResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAircraftResponse)
+	err := c.cc.Invoke(ctx, AircraftAlertService_ListAircraft_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aircraftAlertServiceClient) ListAlerts(ctx context.Context, in *ListAlertsRequest, opts ...grpc.CallOption) (*ListAlertsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAlertsResponse)
+	err := c.cc.Invoke(ctx, AircraftAlertService_ListAlerts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aircraftAlertServiceClient) ListAlertCriteria(ctx context.Context, in *ListAlertCriteriaRequest, opts ...grpc.CallOption) (*ListAlertCriteriaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAlertCriteriaResponse)
+	err := c.cc.Invoke(ctx, AircraftAlertService_ListAlertCriteria_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aircraftAlertServiceClient) CreateAlertCriterion(ctx context.Context, in *CreateAlertCriterionRequest, opts ...grpc.CallOption) (*AlertCriterion, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AlertCriterion)
+	err := c.cc.Invoke(ctx, AircraftAlertService_CreateAlertCriterion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aircraftAlertServiceClient) DeleteAlertCriterion(ctx context.Context, in *DeleteAlertCriterionRequest, opts ...grpc.CallOption) (*DeleteAlertCriterionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAlertCriterionResponse)
+	err := c.cc.Invoke(ctx, AircraftAlertService_DeleteAlertCriterion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aircraftAlertServiceClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AircraftAlertService_ServiceDesc.Streams[0], AircraftAlertService_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AircraftAlertService_StreamEventsClient = grpc.ServerStreamingClient[Event]
+
+// AircraftAlertServiceServer is the server API for AircraftAlertService service.
+// All implementations must embed UnimplementedAircraftAlertServiceServer
+// for forward compatibility.
+type AircraftAlertServiceServer interface {
+	// SubmitAircraft ingests a position report, like POST /api/aircraft.
+	SubmitAircraft(context.Context, *SubmitAircraftRequest) (*SubmitAircraftResponse, error)
+	// ListAircraft returns the latest state of every tracked aircraft.
+	ListAircraft(context.Context, *ListAircraftRequest) (*ListAircraftResponse, error)
+	// ListAlerts returns stored alerts, newest first.
+	ListAlerts(context.Context, *ListAlertsRequest) (*ListAlertsResponse, error)
+	ListAlertCriteria(context.Context, *ListAlertCriteriaRequest) (*ListAlertCriteriaResponse, error)
+	CreateAlertCriterion(context.Context, *CreateAlertCriterionRequest) (*AlertCriterion, error)
+	DeleteAlertCriterion(context.Context, *DeleteAlertCriterionRequest) (*DeleteAlertCriterionResponse, error)
+	// StreamEvents sends aircraft updates and alerts as they happen until the
+	// client cancels.
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedAircraftAlertServiceServer()
+}
+
+// UnimplementedAircraftAlertServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAircraftAlertServiceServer struct{}
+
+func (UnimplementedAircraftAlertServiceServer) SubmitAircraft(context.Context, *SubmitAircraftRequest) (*SubmitAircraftResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitAircraft not implemented")
+}
+func (UnimplementedAircraftAlertServiceServer) ListAircraft(context.Context, *ListAircraftRequest) (*ListAircraftResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAircraft not implemented")
+}
+func (UnimplementedAircraftAlertServiceServer) ListAlerts(context.Context, *ListAlertsRequest) (*ListAlertsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAlerts not implemented")
+}
+func (UnimplementedAircraftAlertServiceServer) ListAlertCriteria(context.Context, *ListAlertCriteriaRequest) (*ListAlertCriteriaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAlertCriteria not implemented")
+}
+func (UnimplementedAircraftAlertServiceServer) CreateAlertCriterion(context.Context, *CreateAlertCriterionRequest) (*AlertCriterion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAlertCriterion not implemented")
+}
+func (UnimplementedAircraftAlertServiceServer) DeleteAlertCriterion(context.Context, *DeleteAlertCriterionRequest) (*DeleteAlertCriterionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAlertCriterion not implemented")
+}
+func (UnimplementedAircraftAlertServiceServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedAircraftAlertServiceServer) mustEmbedUnimplementedAircraftAlertServiceServer() {}
+func (UnimplementedAircraftAlertServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafeAircraftAlertServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AircraftAlertServiceServer will
+// result in compilation errors.
+type UnsafeAircraftAlertServiceServer interface {
+	mustEmbedUnimplementedAircraftAlertServiceServer()
+}
+
+func RegisterAircraftAlertServiceServer(s grpc.ServiceRegistrar, srv AircraftAlertServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAircraftAlertServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AircraftAlertService_ServiceDesc, srv)
+}
+
+func _AircraftAlertService_SubmitAircraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitAircraftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AircraftAlertServiceServer).SubmitAircraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AircraftAlertService_SubmitAircraft_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AircraftAlertServiceServer).SubmitAircraft(ctx, req.(*SubmitAircraftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AircraftAlertService_ListAircraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAircraftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AircraftAlertServiceServer).ListAircraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AircraftAlertService_ListAircraft_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AircraftAlertServiceServer).ListAircraft(ctx, req.(*ListAircraftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AircraftAlertService_ListAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAlertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AircraftAlertServiceServer).ListAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AircraftAlertService_ListAlerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AircraftAlertServiceServer).ListAlerts(ctx, req.(*ListAlertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AircraftAlertService_ListAlertCriteria_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAlertCriteriaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AircraftAlertServiceServer).ListAlertCriteria(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AircraftAlertService_ListAlertCriteria_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AircraftAlertServiceServer).ListAlertCriteria(ctx, req.(*ListAlertCriteriaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AircraftAlertService_CreateAlertCriterion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAlertCriterionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AircraftAlertServiceServer).CreateAlertCriterion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AircraftAlertService_CreateAlertCriterion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AircraftAlertServiceServer).CreateAlertCriterion(ctx, req.(*CreateAlertCriterionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AircraftAlertService_DeleteAlertCriterion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAlertCriterionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AircraftAlertServiceServer).DeleteAlertCriterion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AircraftAlertService_DeleteAlertCriterion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AircraftAlertServiceServer).DeleteAlertCriterion(ctx, req.(*DeleteAlertCriterionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AircraftAlertService_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AircraftAlertServiceServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AircraftAlertService_StreamEventsServer = grpc.ServerStreamingServer[Event]
+
+// AircraftAlertService_ServiceDesc is the grpc.ServiceDesc for AircraftAlertService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AircraftAlertService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aircraftalert.v1.AircraftAlertService",
+	HandlerType: (*AircraftAlertServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitAircraft",
+			Handler:    _AircraftAlertService_SubmitAircraft_Handler,
+		},
+		{
+			MethodName: "ListAircraft",
+			Handler:    _AircraftAlertService_ListAircraft_Handler,
+		},
+		{
+			MethodName: "ListAlerts",
+			Handler:    _AircraftAlertService_ListAlerts_Handler,
+		},
+		{
+			MethodName: "ListAlertCriteria",
+			Handler:    _AircraftAlertService_ListAlertCriteria_Handler,
+		},
+		{
+			MethodName: "CreateAlertCriterion",
+			Handler:    _AircraftAlertService_CreateAlertCriterion_Handler,
+		},
+		{
+			MethodName: "DeleteAlertCriterion",
+			Handler:    _AircraftAlertService_DeleteAlertCriterion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _AircraftAlertService_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/aircraftalert/v1/aircraftalert.proto",
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Positions older than this are not considered for proximity checks.
+const proximityMaxAge = time.Minute
+
+// proximityPairs records pairs currently in conflict, keyed by criterion and
+// both ICAOs in sorted order, so each encounter alerts once. Guarded by mu.
+var proximityPairs = map[string]bool{}
+
+// nearbyAircraft returns the candidates for a proximity check: the latest
+// report of at least every aircraft within nm of a point. Live it asks the
+// spatial index; a history dry run swaps in a grid of the replayed points,
+// as the index holds only current positions. Guarded by mu.
+var nearbyAircraft = indexedNearby
+
+func indexedNearby(lat, lon, nm float64) []Aircraft {
+	return aircraftIn(boxesAround(lat, lon, nm)...)
+}
+
+func validateProximity(p ProximityParams) error {
+	if p.Distance <= 0 || p.Altitude <= 0 {
+		return errors.New("proximity distance and altitude must be positive")
+	}
+	return nil
+}
+
+// detectProximity compares the aircraft with the latest position of the
+// other aircraft within the criterion's distance, found through
+// nearbyAircraft. Callers must hold mu.
+func detectProximity(criterion *AlertCriteria, aircraft Aircraft) (event, message string, cpa *CPA, ok bool) {
+	p := criterion.Proximity
+	if aircraft.Altitude < p.MinAltitude {
+		return "", "", nil, false
+	}
+	near := map[string]bool{}
+	for _, other := range nearbyAircraft(aircraft.Latitude, aircraft.Longitude, p.Distance) {
+		if other.ICAO == aircraft.ICAO {
+			continue
+		}
+		dist := distanceNM(aircraft.Latitude, aircraft.Longitude, other.Latitude, other.Longitude)
+		vert := int(math.Abs(float64(aircraft.Altitude - other.Altitude)))
+		if aircraft.Timestamp.Sub(other.Timestamp) > proximityMaxAge ||
+			other.Altitude < p.MinAltitude || dist > p.Distance || vert > p.Altitude {
+			continue
+		}
+		key := proximityKey(criterion.ID, aircraft.ICAO, other.ICAO)
+		near[key] = true
+		if proximityPairs[key] || ok {
+			continue
+		}
+		proximityPairs[key] = true
+		cpa = predictCPA(aircraft, other)
+		event, ok = eventProximity, true
+		message = fmt.Sprintf("Proximity: %s (%s) and %s (%s) are %.2f nm and %d ft apart",
+			aircraft.Callsign, aircraft.ICAO, other.Callsign, other.ICAO, dist, vert)
+	}
+	// Pairs the aircraft has left behind may alert again next time.
+	for key := range proximityPairs {
+		if !near[key] && strings.HasPrefix(key, criterion.ID+"|") && slices.Contains(strings.Split(key, "|")[1:], aircraft.ICAO) {
+			delete(proximityPairs, key)
+		}
+	}
+	return event, message, cpa, ok
+}
+
+// proximityKey keys a pair of aircraft for a criterion, in the same order
+// whichever of them reported.
+func proximityKey(criterionID, a, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return criterionID + "|" + a + "|" + b
+}
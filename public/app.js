@@ -1,4 +1,88 @@
-document.addEventListener('DOMContentLoaded', () => {
+// When the server requires API keys, open the map once as /?api_key=<key>;
+// the key is remembered in this browser and sent with every API request.
+const apiKey = (() => {
+    const params = new URLSearchParams(window.location.search);
+    if (params.has('api_key')) {
+        localStorage.setItem('apiKey', params.get('api_key'));
+        history.replaceState(null, '', window.location.pathname);
+    }
+    return localStorage.getItem('apiKey');
+})();
+
+// apiURL adds the API key or session token to an /api URL.
+function apiURL(path) {
+    const [name, value] = apiKey ? ['api_key', apiKey] : ['access_token', localStorage.getItem('accessToken')];
+    if (!value) return path;
+    return path + (path.includes('?') ? '&' : '?') + name + '=' + encodeURIComponent(value);
+}
+
+// Servers with user accounts issue short-lived access tokens from /api/v1/login,
+// renewed with the refresh token until it expires or the user logs out.
+function saveSession(tokens) {
+    localStorage.setItem('accessToken', tokens.access_token);
+    localStorage.setItem('refreshToken', tokens.refresh_token);
+    setTimeout(refreshSession, Math.max(tokens.expires_in - 60, 10) * 1000);
+}
+
+function clearSession() {
+    localStorage.removeItem('accessToken');
+    localStorage.removeItem('refreshToken');
+}
+
+function postJSON(path, body) {
+    return fetch(path, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(body) });
+}
+
+async function refreshSession() {
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (!refreshToken) return false;
+    const response = await postJSON('/api/v1/token/refresh', { refresh_token: refreshToken });
+    if (!response.ok) {
+        clearSession();
+        return false;
+    }
+    saveSession(await response.json());
+    return true;
+}
+
+// ensureSession resolves to true once the API can be used, and shows the
+// login form instead when the server wants a login first.
+async function ensureSession() {
+    if (apiKey) return true;
+    if (await refreshSession()) {
+        const logout = document.getElementById('logout');
+        logout.hidden = false;
+        logout.onclick = async () => {
+            await postJSON('/api/v1/logout', { refresh_token: localStorage.getItem('refreshToken') });
+            clearSession();
+            window.location.reload();
+        };
+        return true;
+    }
+    const probe = await fetch('/api/v1/aircraft?max_age=1s');
+    if (probe.status !== 401) return true;
+
+    const form = document.getElementById('login');
+    document.getElementById('map').hidden = true;
+    document.getElementById('alerts').hidden = true;
+    form.hidden = false;
+    form.onsubmit = async (event) => {
+        event.preventDefault();
+        const response = await postJSON('/api/v1/login', { username: form.username.value, password: form.password.value });
+        if (!response.ok) {
+            const { error } = await response.json().catch(() => ({ error: 'Login failed' }));
+            document.getElementById('login-error').textContent = error;
+            return;
+        }
+        saveSession(await response.json());
+        window.location.reload();
+    };
+    return false;
+}
+
+document.addEventListener('DOMContentLoaded', async () => {
+    if (!await ensureSession()) return;
+
     const aircraftFeatures = new Map();
 
     const aircraftIconBlueURI = 'data:image/svg+xml;charset=UTF-8,%3Csvg%20xmlns%3D%22http%3A%2F%2Fwww.w3.org%2F2000%2Fsvg%22%20viewBox%3D%220%200%2024%2024%22%20width%3D%2228px%22%20height%3D%2228px%22%20fill%3D%22%23007bff%22%3E%3Cpath%20d%3D%22M21%2016v-2l-8-5V3.5c0-.83-.67-1.5-1.5-1.5S10%202.67%2010%203.5V9l-8%205v2l8-2.5V19l-2%201.5V22l3.5-1%203.5%201v-1.5L13%2019v-5.5l8%202.5z%22%2F%3E%3C%2Fsvg%3E';
@@ -47,7 +131,8 @@ document.addEventListener('DOMContentLoaded', () => {
 
     function addAlertToList(alert) {
         const listItem = document.createElement('li');
-        listItem.textContent = `ALERT (${alert.criteria.callsign || alert.criteria.icao}): ${alert.message} (Aircraft: ${alert.aircraft.callsign}/${alert.aircraft.icao}) at ${new Date(alert.timestamp).toLocaleString()}`;
+        const label = alert.category === 'anomaly' ? `ANOMALY ${alert.event}` : `ALERT (${alert.criteria.callsign || alert.criteria.icao || alert.event})`;
+        listItem.textContent = `${label}: ${alert.message} (Aircraft: ${alert.aircraft.callsign}/${alert.aircraft.icao}) at ${new Date(alert.timestamp).toLocaleString()}`;
         alertList.insertBefore(listItem, alertList.firstChild);
         
         activeAlertICAOs.add(alert.aircraft.icao);
@@ -89,8 +174,8 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     }
 
-    console.log("Attempting to connect to SSE at /api/events");
-    const eventSource = new EventSource('/api/events');
+    console.log("Attempting to connect to SSE at /api/v1/events");
+    const eventSource = new EventSource(apiURL('/api/v1/events?batch=true'));
 
     eventSource.onopen = function() {
         console.log("SSE connection opened successfully.");
@@ -122,47 +207,149 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     });
 
-    eventSource.addEventListener('aircraftUpdate', function(event) {
-        const statusMessage = document.getElementById("sse-status-message");
-        if (statusMessage) statusMessage.remove();
-        try {
-            const acData = JSON.parse(event.data);
-            if (!acData.icao || typeof acData.lat !== 'number' || typeof acData.lon !== 'number') {
-                console.warn("Received incomplete aircraft data:", acData);
-                return;
-            }
+    function updateAircraft(acData, lastUpdate) {
+        if (!acData.icao || typeof acData.lat !== 'number' || typeof acData.lon !== 'number') {
+            console.warn("Received incomplete aircraft data:", acData);
+            return;
+        }
 
-            let feature = aircraftFeatures.get(acData.icao);
-            const coordinates = ol.proj.fromLonLat([acData.lon, acData.lat]);
+        let feature = aircraftFeatures.get(acData.icao);
+        const coordinates = ol.proj.fromLonLat([acData.lon, acData.lat]);
 
-            if (feature) {
-                feature.getGeometry().setCoordinates(coordinates);
-                feature.set('track', acData.track);
-            } else {
-                feature = new ol.Feature({
-                    geometry: new ol.geom.Point(coordinates),
-                    name: acData.callsign || acData.icao,
-                    icao: acData.icao,
-                    track: acData.track,
-                    alerted: activeAlertICAOs.has(acData.icao)
-                });
-                aircraftVectorSource.addFeature(feature);
-                aircraftFeatures.set(acData.icao, feature);
-            }
-            feature.set('lastUpdate', Date.now());
-            if (!activeAlertICAOs.has(acData.icao)) {
-                 feature.set('alerted', false);
+        if (feature) {
+            feature.getGeometry().setCoordinates(coordinates);
+            feature.set('track', acData.track);
+        } else {
+            feature = new ol.Feature({
+                geometry: new ol.geom.Point(coordinates),
+                name: acData.callsign || acData.icao,
+                icao: acData.icao,
+                track: acData.track,
+                alerted: activeAlertICAOs.has(acData.icao)
+            });
+            aircraftVectorSource.addFeature(feature);
+            aircraftFeatures.set(acData.icao, feature);
+        }
+        feature.set('lastUpdate', lastUpdate);
+        if (!activeAlertICAOs.has(acData.icao)) {
+             feature.set('alerted', false);
+        }
+        feature.changed();
+    }
+
+    // Draw the aircraft already being tracked instead of waiting for updates.
+    fetch(apiURL('/api/v1/aircraft?max_age=' + AIRCRAFT_TIMEOUT_MS / 1000 + 's'))
+        .then(response => response.json())
+        .then(aircraft => aircraft.forEach(ac => {
+            if (!aircraftFeatures.has(ac.icao)) {
+                updateAircraft(ac, Date.now() - ac.seen * 1000);
             }
-            feature.changed();
+        }))
+        .catch(err => console.error("Error loading tracked aircraft:", err));
 
+    eventSource.addEventListener('aircraftUpdate', function(event) {
+        const statusMessage = document.getElementById("sse-status-message");
+        if (statusMessage) statusMessage.remove();
+        try {
+            updateAircraft(JSON.parse(event.data), Date.now());
         } catch (e) {
             console.error("Error parsing aircraft data from 'aircraftUpdate' event:", e, "Raw data:", event.data);
         }
     });
 
+    // Updates arriving together are batched by the server; a reconnect
+    // resuming from the backlog gets them one by one again.
+    eventSource.addEventListener('aircraftBatch', function(event) {
+        const statusMessage = document.getElementById("sse-status-message");
+        if (statusMessage) statusMessage.remove();
+        try {
+            const now = Date.now();
+            JSON.parse(event.data).forEach(ac => updateAircraft(ac, now));
+        } catch (e) {
+            console.error("Error parsing aircraft data from 'aircraftBatch' event:", e, "Raw data:", event.data);
+        }
+    });
+
+    // An operator purged the aircraft from the tracked state, or it stopped
+    // reporting and expired.
+    function removeAircraft(event) {
+        const icao = JSON.parse(event.data).icao;
+        const feature = aircraftFeatures.get(icao);
+        if (feature) {
+            aircraftVectorSource.removeFeature(feature);
+            aircraftFeatures.delete(icao);
+        }
+        activeAlertICAOs.delete(icao);
+    }
+    eventSource.addEventListener('aircraftRemoved', removeAircraft);
+    eventSource.addEventListener('expired', removeAircraft);
+
+    // The browser reconnects by itself once the server is back.
+    eventSource.addEventListener('server_shutdown', function() {
+        console.log("Server is shutting down; the live feed will reconnect when it returns.");
+    });
+
     eventSource.onmessage = function(event) {
         if (event.type !== 'alert' && event.type !== 'aircraftUpdate') {
             console.log("Received generic SSE message (untyped or keep-alive?):", event);
         }
     };
-});
\ No newline at end of file
+
+    setupPushToggle();
+});
+
+// setupPushToggle shows the notification button when the server has web push
+// configured and the browser supports it.
+async function setupPushToggle() {
+    const button = document.getElementById('push-toggle');
+    if (!('serviceWorker' in navigator) || !('PushManager' in window)) return;
+    const keyResponse = await fetch(apiURL('/api/v1/push/key'));
+    if (!keyResponse.ok) return;
+    const { public_key: publicKey } = await keyResponse.json();
+
+    const registration = await navigator.serviceWorker.register('/static/sw.js');
+    let subscription = await registration.pushManager.getSubscription();
+    const render = () => {
+        button.textContent = subscription ? 'Disable notifications' : 'Enable notifications';
+    };
+    render();
+    button.hidden = false;
+
+    button.addEventListener('click', async () => {
+        button.disabled = true;
+        try {
+            if (subscription) {
+                const id = localStorage.getItem('pushSubscriptionId');
+                if (id) {
+                    await fetch(apiURL('/api/v1/push/subscriptions/' + encodeURIComponent(id)), { method: 'DELETE' });
+                    localStorage.removeItem('pushSubscriptionId');
+                }
+                await subscription.unsubscribe();
+                subscription = null;
+            } else {
+                if (await Notification.requestPermission() !== 'granted') return;
+                subscription = await registration.pushManager.subscribe({
+                    userVisibleOnly: true,
+                    applicationServerKey: urlBase64ToUint8Array(publicKey)
+                });
+                const saved = await fetch(apiURL('/api/v1/push/subscriptions'), { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(subscription) });
+                if (saved.ok) {
+                    localStorage.setItem('pushSubscriptionId', (await saved.json()).id);
+                } else {
+                    console.error("Registering push subscription failed:", await saved.text());
+                }
+            }
+        } catch (e) {
+            console.error("Changing push subscription failed:", e);
+        } finally {
+            button.disabled = false;
+            render();
+        }
+    });
+}
+
+function urlBase64ToUint8Array(base64String) {
+    const padding = '='.repeat((4 - base64String.length % 4) % 4);
+    const base64 = (base64String + padding).replace(/-/g, '+').replace(/_/g, '/');
+    return Uint8Array.from(atob(base64), c => c.charCodeAt(0));
+}
\ No newline at end of file
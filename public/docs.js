@@ -0,0 +1,8 @@
+// Swagger UI for the API docs page (/api/v1/docs). Kept out of docs.html so the
+// page works under the Content-Security-Policy.
+window.addEventListener('load', () => {
+    window.ui = SwaggerUIBundle({
+        url: '/api/v1/openapi.json',
+        dom_id: '#swagger-ui',
+    });
+});
@@ -0,0 +1,28 @@
+// Service worker showing web push alerts while no tab is open.
+self.addEventListener('push', function(event) {
+    let data = {};
+    try {
+        data = event.data ? event.data.json() : {};
+    } catch (e) {
+        data = { title: 'Aircraft alert', body: event.data.text() };
+    }
+    event.waitUntil(self.registration.showNotification(data.title || 'Aircraft alert', {
+        body: data.body,
+        tag: data.tag,
+        requireInteraction: data.severity === 'critical',
+        data: { url: data.url || '/' }
+    }));
+});
+
+self.addEventListener('notificationclick', function(event) {
+    event.notification.close();
+    const url = event.notification.data && event.notification.data.url || '/';
+    event.waitUntil(clients.matchAll({ type: 'window', includeUncontrolled: true }).then(function(windows) {
+        for (const w of windows) {
+            if (new URL(w.url).pathname === url && 'focus' in w) {
+                return w.focus();
+            }
+        }
+        return clients.openWindow(url);
+    }));
+});
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitIdle is how long a client's bucket is kept after its last request.
+const rateLimitIdle = 10 * time.Minute
+
+// RateLimitConfig limits how fast each client may call the API. Clients that
+// send a valid API key or session token are limited per key or user, others
+// per IP address.
+type RateLimitConfig struct {
+	Ingest RateLimit `yaml:"ingest"` // POST /api/v1/aircraft and gRPC SubmitAircraft
+	API    RateLimit `yaml:"api"`    // Every other /api route and gRPC call
+
+	// TrustProxy takes the client address from X-Forwarded-For; only set it
+	// behind a reverse proxy that overwrites the header.
+	TrustProxy bool `yaml:"trust_proxy"`
+}
+
+// RateLimit is a token bucket: clients may make burst requests at once and
+// rate requests per second on average.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"` // Zero disables the limit
+	Burst int     `yaml:"burst"`
+}
+
+func (c RateLimitConfig) validate() error {
+	for _, l := range []RateLimit{c.Ingest, c.API} {
+		if l.Rate < 0 {
+			return errors.New("rate_limit: rate must not be negative")
+		}
+		if l.Rate > 0 && l.Burst < 1 {
+			return errors.New("rate_limit: burst must be at least 1")
+		}
+	}
+	return nil
+}
+
+// rateLimiter holds a token bucket per client.
+type rateLimiter struct {
+	limit     RateLimit
+	mu        sync.Mutex
+	clients   map[string]*rateClient
+	lastPrune time.Time
+}
+
+type rateClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newRateLimiter returns nil when the limit is disabled.
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	return &rateLimiter{limit: limit, clients: map[string]*rateClient{}, lastPrune: time.Now()}
+}
+
+// allow takes a token for client, or returns how long until one is free.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastPrune) > rateLimitIdle {
+		for id, c := range l.clients {
+			if now.Sub(c.lastSeen) > rateLimitIdle {
+				delete(l.clients, id)
+			}
+		}
+		l.lastPrune = now
+	}
+	c, ok := l.clients[client]
+	if !ok {
+		c = &rateClient{limiter: rate.NewLimiter(rate.Limit(l.limit.Rate), l.limit.Burst)}
+		l.clients[client] = c
+	}
+	c.lastSeen = now
+	r := c.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// rateLimits holds the limiters of the ingest and API routes.
+type rateLimits struct {
+	ingest, api *rateLimiter
+	trustProxy  bool
+}
+
+func newRateLimits(cfg RateLimitConfig) *rateLimits {
+	return &rateLimits{ingest: newRateLimiter(cfg.Ingest), api: newRateLimiter(cfg.API), trustProxy: cfg.TrustProxy}
+}
+
+// forRoute returns the limiter of a route; ingest routes are the ones only
+// feeders may call.
+func (l *rateLimits) forRoute(role string) *rateLimiter {
+	if role == roleFeeder {
+		return l.ingest
+	}
+	return l.api
+}
+
+// clientIP returns the address requests are limited by when they carry no
+// credential.
+func (l *rateLimits) clientIP(r *http.Request) string {
+	if l.trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rejectRateLimited replies 429 with a Retry-After header in whole seconds.
+func rejectRateLimited(c *jacked.Context, wait time.Duration) error {
+	c.Response.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Rate limit exceeded"})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const rawLogFlushEvery = 5 * time.Second
+
+// Raw log rotation periods.
+const (
+	rotateHourly = "hour"
+	rotateDaily  = "day"
+)
+
+// RawLogConfig configures the append-only log of received updates.
+type RawLogConfig struct {
+	Dir      string `yaml:"dir"`      // Directory for the log files; empty disables the log
+	Rotate   string `yaml:"rotate"`   // "hour" or "day"
+	Compress bool   `yaml:"compress"` // Gzip the files (.jsonl.gz)
+}
+
+func defaultRawLogConfig() RawLogConfig {
+	return RawLogConfig{Rotate: rotateHourly, Compress: true}
+}
+
+func (c RawLogConfig) validate() error {
+	if c.Rotate != rotateHourly && c.Rotate != rotateDaily {
+		return fmt.Errorf("unknown raw_log.rotate %q", c.Rotate)
+	}
+	return nil
+}
+
+// rawLogger writes one JSON line per update to a file per rotation period.
+// Files are opened in append mode, so restarting within a period adds to the
+// existing file (as a new gzip member when compressed).
+type rawLogger struct {
+	mu     sync.Mutex
+	cfg    RawLogConfig
+	period string
+	file   *os.File
+	gz     *gzip.Writer
+	w      io.Writer
+}
+
+// rawLog is the active raw logger, nil when disabled.
+var rawLog *rawLogger
+
+func newRawLogger(cfg RawLogConfig) (*rawLogger, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	l := &rawLogger{cfg: cfg}
+	go l.flushLoop()
+	return l, nil
+}
+
+// periodName returns the file name stem for the period containing t.
+func (l *rawLogger) periodName(t time.Time) string {
+	if l.cfg.Rotate == rotateDaily {
+		return t.UTC().Format("2006-01-02")
+	}
+	return t.UTC().Format("2006-01-02T15")
+}
+
+// write appends the update to the file for its period, rotating as needed.
+func (l *rawLogger) write(aircraft Aircraft) {
+	line, err := json.Marshal(aircraft)
+	if err != nil {
+		log.Printf("Raw log: error marshalling update: %v", err)
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if period := l.periodName(aircraft.Timestamp); period != l.period {
+		if err := l.rotate(period); err != nil {
+			log.Printf("Raw log: error opening file: %v", err)
+			return
+		}
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		log.Printf("Raw log: error writing %s: %v", l.file.Name(), err)
+	}
+}
+
+// rotate closes the current file and opens the one for period.
+// Callers must hold l.mu.
+func (l *rawLogger) rotate(period string) error {
+	l.closeFile()
+	name := "updates-" + period + ".jsonl"
+	if l.cfg.Compress {
+		name += ".gz"
+	}
+	f, err := os.OpenFile(filepath.Join(l.cfg.Dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	l.file, l.period, l.w = f, period, f
+	if l.cfg.Compress {
+		l.gz = gzip.NewWriter(f)
+		l.w = l.gz
+	}
+	return nil
+}
+
+// closeFile finishes and closes the current file. Callers must hold l.mu.
+func (l *rawLogger) closeFile() {
+	if l.file == nil {
+		return
+	}
+	if l.gz != nil {
+		if err := l.gz.Close(); err != nil {
+			log.Printf("Raw log: error finishing %s: %v", l.file.Name(), err)
+		}
+	}
+	if err := l.file.Close(); err != nil {
+		log.Printf("Raw log: error closing %s: %v", l.file.Name(), err)
+	}
+	l.file, l.gz, l.w, l.period = nil, nil, nil, ""
+}
+
+// flushLoop periodically flushes compressed output so a crash loses at most
+// a few seconds of updates.
+func (l *rawLogger) flushLoop() {
+	ticker := time.NewTicker(rawLogFlushEvery)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.mu.Lock()
+		if l.gz != nil {
+			if err := l.gz.Flush(); err != nil {
+				log.Printf("Raw log: error flushing %s: %v", l.file.Name(), err)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// Close finishes the current file.
+func (l *rawLogger) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closeFile()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// ReceiverConfig is where the receiver's antenna, or the operator's home,
+// is. When set, every update gets its distance (r_dst) and bearing (r_dir)
+// from there, as readsb reports them; criteria can then match on
+// max_distance and expressions on r_dst and r_dir. Without it, the values
+// are left as the feeder sent them.
+type ReceiverConfig struct {
+	Latitude  float64 `yaml:"lat" json:"lat"`
+	Longitude float64 `yaml:"lon" json:"lon"`
+}
+
+func (c ReceiverConfig) set() bool { return c.Latitude != 0 || c.Longitude != 0 }
+
+func (c ReceiverConfig) validate() error {
+	if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+		return errors.New("receiver.lat must be between -90 and 90 and receiver.lon between -180 and 180")
+	}
+	return nil
+}
+
+// receiver is the configured receiver location.
+var receiver ReceiverConfig
+
+// fromReceiver returns the aircraft with its distance and bearing from the
+// receiver filled in.
+func fromReceiver(aircraft Aircraft) Aircraft {
+	if !receiver.set() {
+		return aircraft
+	}
+	aircraft.ReceiverDistance = math.Round(distanceNM(receiver.Latitude, receiver.Longitude, aircraft.Latitude, aircraft.Longitude)*1000) / 1000
+	aircraft.ReceiverBearing = math.Mod(math.Round(bearingDeg(receiver.Latitude, receiver.Longitude, aircraft.Latitude, aircraft.Longitude)*10)/10, 360)
+	return aircraft
+}
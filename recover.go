@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recoveredPanics counts the handler panics recovered since the server
+// started, for GET /api/v1/stats.
+var recoveredPanics atomic.Uint64
+
+// recoverPanics answers a request whose handler panicked with a 500 and logs
+// the panic with its stack, so one bad request costs neither the server nor
+// the client's connection. A response already under way, such as a stream,
+// cannot be turned into an error; its connection is dropped instead.
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			recoveredPanics.Add(1)
+			logRequestf(r, "Panic serving %s %s: %v\n%s", r.Method, r.URL.Path, v, debug.Stack())
+			if sw, ok := w.(*statusWriter); ok && sw.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+			var body any = map[string]string{"error": "Internal server error"}
+			if strings.HasPrefix(r.URL.Path, apiPrefix+"/") {
+				body = APIError{
+					Error:     APIErrorDetail{Code: errorCode(http.StatusInternalServerError), Message: "Internal server error"},
+					RequestID: requestID(r),
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(body)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// grpcRecovery returns interceptors that turn a panic in a gRPC method into
+// an Internal status.
+func grpcRecovery() []grpc.ServerOption {
+	recovered := func(method string, err *error) {
+		if v := recover(); v != nil {
+			recoveredPanics.Add(1)
+			log.Printf("gRPC: panic serving %s: %v\n%s", method, v, debug.Stack())
+			*err = status.Error(codes.Internal, "internal server error")
+		}
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+			defer recovered(info.FullMethod, &err)
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+			defer recovered(info.FullMethod, &err)
+			return handler(srv, ss)
+		}),
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RegistryConfig loads a registration database so aircraft and alerts carry
+// the tail number, type and operator or owner of the airframe behind an
+// ICAO address. The database is downloaded to File and refreshed from URL
+// every Refresh.
+type RegistryConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URL serves the OpenSky aircraft database CSV or the FAA's
+	// ReleasableAircraft.zip (US aircraft only, without operators). Leave
+	// it empty to use File as it is, never refreshed.
+	URL     string        `yaml:"url"`
+	File    string        `yaml:"file"`
+	Refresh time.Duration `yaml:"refresh"`
+}
+
+func defaultRegistryConfig() RegistryConfig {
+	return RegistryConfig{
+		URL:     "https://opensky-network.org/datasets/metadata/aircraftDatabase.csv",
+		File:    "registry.cache",
+		Refresh: 30 * 24 * time.Hour,
+	}
+}
+
+func (c RegistryConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.File == "" {
+		return errors.New("registry.file must be set")
+	}
+	if c.URL != "" && !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return errors.New("registry.url must be an http or https URL")
+	}
+	if c.URL != "" && c.Refresh < time.Hour {
+		return errors.New("registry.refresh must be at least 1h")
+	}
+	return nil
+}
+
+// registrations holds the loaded database by ICAO address. A loaded map is
+// never modified, so lookups need no lock.
+var registrations atomic.Pointer[map[string]Registration]
+
+// lookupRegistration returns what the database knows about icao, or nil.
+func lookupRegistration(icao string) *Registration {
+	db := registrations.Load()
+	if db == nil {
+		return nil
+	}
+	r, ok := (*db)[icao]
+	if !ok {
+		return nil
+	}
+	return &r
+}
+
+// runRegistry loads the cached database, then downloads a fresh copy
+// whenever the cache is older than cfg.Refresh. A failed download is
+// retried after an hour, keeping the database already loaded.
+func runRegistry(cfg RegistryConfig) {
+	client := &http.Client{Timeout: 15 * time.Minute}
+	var wait time.Duration
+	if info, err := os.Stat(cfg.File); err == nil {
+		if err := loadRegistry(cfg.File); err != nil {
+			log.Printf("Registry: %v", err)
+		} else {
+			wait = cfg.Refresh - time.Since(info.ModTime())
+		}
+	} else if cfg.URL == "" {
+		log.Printf("Registry: %v", err)
+	}
+	if cfg.URL == "" {
+		return
+	}
+	for {
+		time.Sleep(max(wait, 0))
+		if err := downloadRegistry(client, cfg); err != nil {
+			log.Printf("Registry: %v", err)
+			wait = time.Hour
+			continue
+		}
+		if err := loadRegistry(cfg.File); err != nil {
+			log.Printf("Registry: %v", err)
+		}
+		wait = cfg.Refresh
+	}
+}
+
+// downloadRegistry fetches cfg.URL into cfg.File, replacing the cache only
+// once the download is complete.
+func downloadRegistry(client *http.Client, cfg RegistryConfig) error {
+	resp, err := client.Get(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("fetching database: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching database: status %s", resp.Status)
+	}
+	tmp := cfg.File + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, io.LimitReader(resp.Body, 1<<30))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("fetching database: %w", err)
+	}
+	return os.Rename(tmp, cfg.File)
+}
+
+// loadRegistry reads a database file, an OpenSky CSV or an FAA zip, and
+// replaces the loaded database with it.
+func loadRegistry(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	magic, _ := bufio.NewReader(f).Peek(4)
+	var db map[string]Registration
+	if string(magic) == "PK\x03\x04" {
+		db, err = readFAARegistry(file)
+	} else if _, err = f.Seek(0, io.SeekStart); err == nil {
+		db, err = readRegistryCSV(f, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", file, err)
+	}
+	registrations.Store(&db)
+	log.Printf("Loaded %d aircraft registrations from %s", len(db), file)
+	return nil
+}
+
+// readFAARegistry reads MASTER.txt from the FAA's ReleasableAircraft.zip,
+// taking aircraft types from ACFTREF.txt beside it.
+func readFAARegistry(file string) (map[string]Registration, error) {
+	z, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	open := func(name string) (io.ReadCloser, error) {
+		for _, f := range z.File {
+			if strings.EqualFold(path.Base(f.Name), name) {
+				return f.Open()
+			}
+		}
+		return nil, fmt.Errorf("%s not in archive", name)
+	}
+
+	types := map[string]string{}
+	if r, err := open("ACFTREF.txt"); err == nil {
+		err = readCSV(r, []string{"CODE"}, func(field func(string) string) {
+			types[field("CODE")] = strings.TrimSpace(field("MFR") + " " + field("MODEL"))
+		})
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("ACFTREF.txt: %w", err)
+		}
+	}
+	r, err := open("MASTER.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readRegistryCSV(r, types)
+}
+
+// readRegistryCSV reads an OpenSky aircraft database or an FAA MASTER.txt,
+// telling them apart by their columns. types maps FAA model codes to type
+// names.
+func readRegistryCSV(r io.Reader, types map[string]string) (map[string]Registration, error) {
+	db := map[string]Registration{}
+	err := readCSV(r, []string{"icao24", "MODE S CODE HEX"}, func(field func(string) string) {
+		var icao string
+		var reg Registration
+		if n := field("N-NUMBER"); n != "" {
+			icao = field("MODE S CODE HEX")
+			reg = Registration{Registration: "N" + n, Type: types[field("MFR MDL CODE")], Owner: field("NAME")}
+		} else {
+			icao = field("icao24")
+			reg = Registration{
+				Registration: field("registration"),
+				TypeCode:     field("typecode"),
+				Type:         field("model"),
+				Operator:     field("operator"),
+				Owner:        field("owner"),
+			}
+			if mfr := field("manufacturername"); mfr != "" && !strings.HasPrefix(reg.Type, mfr) {
+				reg.Type = strings.TrimSpace(mfr + " " + reg.Type)
+			}
+		}
+		if icao = strings.ToUpper(icao); !icaoPattern.MatchString(icao) || reg == (Registration{}) {
+			return
+		}
+		db[icao] = reg
+	})
+	return db, err
+}
+
+// readCSV calls row for every record of a CSV file whose header has one of
+// the columns in anyOf, with a function returning a record's trimmed value
+// in a named column.
+func readCSV(r io.Reader, anyOf []string, row func(field func(string) string)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return err
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if !slices.ContainsFunc(anyOf, func(name string) bool { _, ok := col[name]; return ok }) {
+		return fmt.Errorf("missing column %s", strings.Join(anyOf, " or "))
+	}
+	var rec []string
+	field := func(name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	for {
+		rec, err = cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row(field)
+	}
+}
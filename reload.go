@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// reloadTimeout bounds the last delivery round of the notifiers a reload
+// replaces.
+const reloadTimeout = 15 * time.Second
+
+var (
+	// configFile is the configuration the server was started with, read
+	// again on reload. Empty when it runs on defaults and the environment.
+	configFile string
+	// reloadMu keeps reloads from overlapping.
+	reloadMu sync.Mutex
+)
+
+// ReloadResult is the reply of POST /api/v1/admin/reload.
+type ReloadResult struct {
+	Zones           int `json:"zones"`
+	CriteriaCreated int `json:"criteria_created"`
+	CriteriaUpdated int `json:"criteria_updated"`
+	// CriteriaSkipped counts file criteria without an id, which a reload
+	// cannot tell apart from the stored ones. Give them an id to have
+	// reloads update them.
+	CriteriaSkipped int `json:"criteria_skipped"`
+	Notifiers       int `json:"notifiers"`
+}
+
+// reloadConfig reads the configuration file again and applies what can
+// change while running: the zones and criteria files, the notifiers and
+// their routing, the evaluation mode and anomaly detection. Criteria in the
+// file are merged by id, as with POST /api/v1/alert-criteria/import; ones
+// removed from the file stay until deleted through the API. Stream clients,
+// aircraft state, tracks and queued deliveries are kept. Other settings,
+// such as the listen addresses, storage and auth, take effect on restart.
+// Nothing changes unless the whole configuration is valid.
+func reloadConfig() (ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	var result ReloadResult
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return result, err
+	}
+	loadedZones, err := readZoneSources(cfg)
+	if err != nil {
+		return result, err
+	}
+	var criteria []AlertCriteria
+	seen := map[string]bool{}
+	if cfg.CriteriaFile != "" {
+		all, err := readCriteriaFile(cfg.CriteriaFile)
+		if err != nil {
+			return result, err
+		}
+		for _, criterion := range all {
+			if criterion.ID == "" {
+				result.CriteriaSkipped++
+				continue
+			}
+			if seen[criterion.ID] {
+				return result, fmt.Errorf("%s: duplicate criterion ID %q", cfg.CriteriaFile, criterion.ID)
+			}
+			seen[criterion.ID] = true
+			criteria = append(criteria, criterion)
+		}
+	}
+	queues := newNotifierQueues(cfg.Notifications)
+
+	// Validate the criteria against the new zones and channels, and switch
+	// alerts over to the new queues. They store deliveries until the old
+	// queues have finished and they start sending.
+	mu.Lock()
+	previousZones, previousNotifications := zones, activeConfig.Notifications
+	if loadedZones != nil {
+		zones = loadedZones
+		restoreTFRZones()
+	}
+	activeConfig.Notifications = cfg.Notifications
+	for _, criterion := range criteria {
+		if err := validateCriterion(&criterion); err != nil {
+			zones, activeConfig.Notifications = previousZones, previousNotifications
+			mu.Unlock()
+			return result, fmt.Errorf("%s: criterion %s: %w", cfg.CriteriaFile, criterion.ID, err)
+		}
+	}
+	result.CriteriaCreated, result.CriteriaUpdated, _, err = mergeCriteria(criteria, seen, false)
+	if err != nil {
+		zones, activeConfig.Notifications = previousZones, previousNotifications
+		mu.Unlock()
+		return result, err
+	}
+	evaluationMode = cfg.EvaluationMode
+	anomalyConfig = cfg.Anomaly
+	activeConfig.ZonesFile, activeConfig.CriteriaFile, activeConfig.Airspace = cfg.ZonesFile, cfg.CriteriaFile, cfg.Airspace
+	activeConfig.EvaluationMode, activeConfig.Anomaly = cfg.EvaluationMode, cfg.Anomaly
+	previous := notifiers
+	notifiers = queues
+	indexZones()
+	result.Zones = len(zones)
+	mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), reloadTimeout)
+	defer cancel()
+	stopNotifiers(ctx, previous)
+	runNotifiers(queues)
+	result.Notifiers = len(queues)
+	return result, nil
+}
+
+// handleReload applies the configuration file without a restart; see
+// reloadConfig.
+func handleReload(c *jacked.Context) error {
+	result, err := reloadConfig()
+	if err != nil {
+		logRequestf(c.Request, "Reload failed: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Reload failed: " + err.Error()})
+	}
+	logReload(result)
+	recordAudit(c, "config.reload", configFile, fmt.Sprintf("%d criteria created, %d updated", result.CriteriaCreated, result.CriteriaUpdated))
+	return c.JSON(http.StatusOK, result)
+}
+
+// reloadOnSignal reloads the configuration on every SIGHUP.
+func reloadOnSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		result, err := reloadConfig()
+		if err != nil {
+			log.Printf("Reload failed, keeping the running configuration: %v", err)
+			continue
+		}
+		logReload(result)
+	}
+}
+
+func logReload(r ReloadResult) {
+	log.Printf("Reloaded configuration: %d zones, %d criteria created, %d updated, %d without an id skipped, %d notifiers",
+		r.Zones, r.CriteriaCreated, r.CriteriaUpdated, r.CriteriaSkipped, r.Notifiers)
+}
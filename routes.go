@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RoutesConfig looks up the scheduled route of airline flights by callsign,
+// so aircraft and alerts carry their origin and destination and messages
+// read "BAW117 LHR→JFK" rather than just the callsign.
+type RoutesConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URL answers routeset queries like api.adsb.lol's, which serves the
+	// routes of vrs-standing-data.
+	URL string        `yaml:"url"`
+	TTL time.Duration `yaml:"ttl"` // How long a looked-up route is kept
+}
+
+func defaultRoutesConfig() RoutesConfig {
+	return RoutesConfig{
+		URL: "https://api.adsb.lol/api/0/routeset",
+		TTL: 6 * time.Hour,
+	}
+}
+
+func (c RoutesConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return errors.New("routes.url must be an http or https URL")
+	}
+	if c.TTL < time.Minute {
+		return errors.New("routes.ttl must be at least 1m")
+	}
+	return nil
+}
+
+var routesConfig = defaultRoutesConfig()
+
+const (
+	// routeBatchEvery is how often queued callsigns are looked up, and
+	// maxRouteBatch how many go in one query.
+	routeBatchEvery = 5 * time.Second
+	maxRouteBatch   = 100
+	// routeRetryAfter is how long a callsign waits after a failed query.
+	routeRetryAfter = time.Minute
+)
+
+// routeCallsign matches the callsigns of airline flights, an ICAO airline
+// designator and a flight number; others, such as registrations, have no
+// route to look up.
+var routeCallsign = regexp.MustCompile(`^[A-Z]{3}[0-9][0-9A-Z]*$`)
+
+// routeEntry is a looked-up route, nil if the routes API had none.
+type routeEntry struct {
+	route   *Route
+	expires time.Time
+}
+
+// routeCache holds the routes by callsign, and the callsigns waiting for a
+// lookup with their latest report, which the API uses to judge routes
+// plausible.
+var routeCache = struct {
+	sync.Mutex
+	entries map[string]routeEntry
+	pending map[string]Aircraft
+}{entries: map[string]routeEntry{}, pending: map[string]Aircraft{}}
+
+// lookupRoute returns the route of the aircraft's flight, or nil if it is
+// unknown or not looked up yet. A callsign missing from the cache or expired
+// is queued for the next lookup, so the route appears a few seconds after
+// the first report; until then an expired route is still returned.
+func lookupRoute(aircraft Aircraft) *Route {
+	if !routesConfig.Enabled || !routeCallsign.MatchString(aircraft.Callsign) {
+		return nil
+	}
+	routeCache.Lock()
+	defer routeCache.Unlock()
+	e, ok := routeCache.entries[aircraft.Callsign]
+	if !ok || time.Now().After(e.expires) {
+		routeCache.pending[aircraft.Callsign] = aircraft
+	}
+	return e.route
+}
+
+// runRouteLookups looks up the queued callsigns every routeBatchEvery and
+// drops routes that have been expired for a whole TTL.
+func runRouteLookups(cfg RoutesConfig) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	ticker := time.NewTicker(routeBatchEvery)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		routeCache.Lock()
+		batch := make([]Aircraft, 0, min(len(routeCache.pending), maxRouteBatch))
+		for callsign, aircraft := range routeCache.pending {
+			if len(batch) == maxRouteBatch {
+				break
+			}
+			batch = append(batch, aircraft)
+			delete(routeCache.pending, callsign)
+		}
+		for callsign, e := range routeCache.entries {
+			if now.Sub(e.expires) > cfg.TTL {
+				delete(routeCache.entries, callsign)
+			}
+		}
+		routeCache.Unlock()
+		if len(batch) == 0 {
+			continue
+		}
+
+		found, err := fetchRoutes(client, cfg.URL, batch)
+		routeCache.Lock()
+		for _, aircraft := range batch {
+			if err != nil {
+				// Keep any route already known until the retry.
+				e := routeCache.entries[aircraft.Callsign]
+				e.expires = now.Add(routeRetryAfter)
+				routeCache.entries[aircraft.Callsign] = e
+				continue
+			}
+			routeCache.entries[aircraft.Callsign] = routeEntry{route: found[aircraft.Callsign], expires: now.Add(cfg.TTL)}
+		}
+		routeCache.Unlock()
+		if err != nil {
+			log.Printf("Routes: %v", err)
+		}
+	}
+}
+
+// fetchRoutes queries the routes API for a batch of aircraft and returns
+// the plausible routes found, by callsign.
+func fetchRoutes(client *http.Client, url string, batch []Aircraft) (map[string]*Route, error) {
+	type plane struct {
+		Callsign  string  `json:"callsign"`
+		Latitude  float64 `json:"lat"`
+		Longitude float64 `json:"lng"`
+	}
+	var query struct {
+		Planes []plane `json:"planes"`
+	}
+	for _, a := range batch {
+		query.Planes = append(query.Planes, plane{a.Callsign, a.Latitude, a.Longitude})
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("fetching routes: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching routes: status %s", resp.Status)
+	}
+	routes, err := readRoutes(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading routes: %w", err)
+	}
+	return routes, nil
+}
+
+// readRoutes parses a routeset reply. Routes come as dash-separated airport
+// codes, e.g. "EGLL-KJFK", with every stop of a multi-leg flight; those the
+// API marks implausible for the aircraft's position are left out.
+func readRoutes(r io.Reader) (map[string]*Route, error) {
+	var results []struct {
+		Callsign  string          `json:"callsign"`
+		ICAO      string          `json:"airport_codes"`
+		IATA      string          `json:"_airport_codes_iata"`
+		Plausible json.RawMessage `json:"plausible"`
+	}
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		return nil, err
+	}
+	routes := map[string]*Route{}
+	for _, res := range results {
+		if p := string(res.Plausible); p == "false" || p == "0" {
+			continue
+		}
+		icao := strings.Split(res.ICAO, "-")
+		if len(icao) < 2 {
+			continue
+		}
+		route := &Route{Origin: icao[0], Destination: icao[len(icao)-1]}
+		if iata := strings.Split(res.IATA, "-"); len(iata) == len(icao) {
+			route.OriginIATA, route.DestinationIATA = iata[0], iata[len(iata)-1]
+		}
+		routes[strings.ToUpper(res.Callsign)] = route
+	}
+	return routes, nil
+}
+
+// describeRoute formats a route for messages, e.g. "LHR→JFK", preferring
+// the IATA codes travellers know.
+func describeRoute(r *Route) string {
+	from, to := r.Origin, r.Destination
+	if r.OriginIATA != "" && r.DestinationIATA != "" {
+		from, to = r.OriginIATA, r.DestinationIATA
+	}
+	return from + "→" + to
+}
+
+// withRoute inserts the route after the first mention of the callsign in an
+// alert message, e.g. "Monitored aircraft detected: BAW117 LHR→JFK
+// (4006E5)".
+func withRoute(message, callsign string, route *Route) string {
+	if route == nil || callsign == "" {
+		return message
+	}
+	return strings.Replace(message, callsign, callsign+" "+describeRoute(route), 1)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+const (
+	defaultSearchLimit  = 20
+	maxSearchLimit      = 100
+	defaultSearchWindow = 24 * time.Hour
+)
+
+// Match scores; higher ranks first.
+const (
+	scoreICAOExact      = 100
+	scoreCallsignExact  = 90
+	scoreSquawk         = 80
+	scoreCallsignPrefix = 70
+	scoreICAOPrefix     = 60
+)
+
+// SearchResult is an aircraft matching a search, with its newest known
+// position.
+type SearchResult struct {
+	Aircraft
+	Live    bool   `json:"live"`    // Currently tracked, rather than only in the history
+	Matched string `json:"matched"` // icao, callsign, registration or squawk
+	Score   int    `json:"score"`
+}
+
+// normalizeIdent upper-cases an identifier and drops the dash of
+// registrations such as G-ABCD, so "gabcd" finds an aircraft using it as its
+// callsign.
+func normalizeIdent(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), "-", ""))
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789ABCDEF", r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isSquawk(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '7' {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAircraft scores an aircraft against a normalised query, returning 0
+// when it does not match. General aviation flights use their registration
+// as callsign, so registrations are found through callsigns.
+func matchAircraft(aircraft Aircraft, query string) (score int, matched string) {
+	callsign := normalizeIdent(aircraft.Callsign)
+	field := "callsign"
+	if strings.ContainsRune(aircraft.Callsign, '-') {
+		field = "registration"
+	}
+	switch {
+	case aircraft.ICAO == query:
+		return scoreICAOExact, "icao"
+	case callsign != "" && callsign == query:
+		return scoreCallsignExact, field
+	case isSquawk(query) && aircraft.Squawk == query:
+		return scoreSquawk, "squawk"
+	case callsign != "" && strings.HasPrefix(callsign, query):
+		return scoreCallsignPrefix, field
+	case isHex(query) && strings.HasPrefix(aircraft.ICAO, query):
+		return scoreICAOPrefix, "icao"
+	}
+	return 0, ""
+}
+
+// handleSearch finds aircraft by ICAO address, callsign or registration
+// prefix, or squawk, among the tracked aircraft and those in the position
+// history since ?since= (default a day ago). Results are ranked by match
+// quality, then live aircraft, then recency, for typeahead in the UI.
+func handleSearch(c *jacked.Context) error {
+	query := c.Request.URL.Query()
+	q := normalizeIdent(query.Get("q"))
+	if q == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "q is required"})
+	}
+	limit := defaultSearchLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and " + strconv.Itoa(maxSearchLimit)})
+		}
+		limit = n
+	}
+	since := time.Now().Add(-defaultSearchWindow)
+	if v := query.Get("since"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid since timestamp"})
+		}
+		since = t
+	}
+
+	byICAO := map[string]SearchResult{}
+	for _, state := range trackedAircraft(trackRetention, nil) {
+		if score, matched := matchAircraft(state.Aircraft, q); score > 0 {
+			byICAO[state.ICAO] = SearchResult{Aircraft: state.Aircraft, Live: true, Matched: matched, Score: score}
+		}
+	}
+	if historyConfig.Enabled {
+		positions, err := store.LatestPositions(since)
+		if err != nil {
+			return storeError(c, err)
+		}
+		for _, p := range positions {
+			if _, live := byICAO[p.ICAO]; live {
+				continue
+			}
+			if score, matched := matchAircraft(p, q); score > 0 {
+				byICAO[p.ICAO] = SearchResult{Aircraft: p, Matched: matched, Score: score}
+			}
+		}
+	}
+
+	results := make([]SearchResult, 0, len(byICAO))
+	for _, r := range byICAO {
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Live != b.Live {
+			return a.Live
+		}
+		return a.Timestamp.After(b.Timestamp)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return c.JSON(http.StatusOK, results)
+}
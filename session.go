@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	jwtIssuer      = "aircraft-alert"
+	maxSessionBody = 16 << 10
+)
+
+// sessions issues the tokens of users logging in through /api/v1/login; nil
+// when no users are configured.
+var sessions *sessionManager
+
+// sessionManager issues short-lived access tokens and longer-lived refresh
+// tokens as HS256 JWTs. Refresh tokens are single use: each refresh returns
+// a new pair and retires the old refresh token, and logging out retires it
+// early.
+type sessionManager struct {
+	users      map[string]User
+	key        []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	dummyHash  []byte // Compared against for unknown users, so timing does not reveal them
+
+	mu      sync.Mutex
+	refresh map[string]time.Time // Live refresh token IDs and their expiry; lost on restart
+}
+
+// sessionClaims are the claims of access and refresh tokens. The subject is
+// the username.
+type sessionClaims struct {
+	Roles   []string `json:"roles,omitempty"`
+	Refresh bool     `json:"refresh,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenResponse struct {
+	AccessToken  string   `json:"access_token"`
+	RefreshToken string   `json:"refresh_token"`
+	TokenType    string   `json:"token_type"` // Always Bearer
+	ExpiresIn    int      `json:"expires_in"` // Seconds until the access token expires
+	Roles        []string `json:"roles"`
+}
+
+func newSessionManager(cfg AuthConfig) (*sessionManager, error) {
+	m := &sessionManager{
+		users:      map[string]User{},
+		key:        []byte(cfg.JWTSecret),
+		accessTTL:  cfg.AccessTTL,
+		refreshTTL: cfg.RefreshTTL,
+		refresh:    map[string]time.Time{},
+	}
+	for _, u := range cfg.Users {
+		m.users[u.Username] = u
+	}
+	if len(m.key) == 0 {
+		m.key = make([]byte, 32)
+		if _, err := rand.Read(m.key); err != nil {
+			return nil, err
+		}
+		log.Printf("auth.jwt_secret is not set; sessions will end when the server restarts")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	m.dummyHash = hash
+	return m, nil
+}
+
+// isJWT reports whether a credential looks like a JWT rather than an API key.
+func isJWT(credential string) bool {
+	return strings.Count(credential, ".") == 2
+}
+
+// login checks a username and password.
+func (m *sessionManager) login(username, password string) (User, bool) {
+	user, ok := m.users[username]
+	hash := []byte(user.PasswordHash)
+	if !ok {
+		hash = m.dummyHash
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !ok {
+		return User{}, false
+	}
+	return user, true
+}
+
+// issue returns a new access and refresh token pair for user.
+func (m *sessionManager) issue(user User) (tokenResponse, error) {
+	now := time.Now()
+	accessClaims, err := m.registeredClaims(user.Username, now, m.accessTTL)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	access, err := m.sign(sessionClaims{Roles: user.Roles, RegisteredClaims: accessClaims})
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	refreshClaims, err := m.registeredClaims(user.Username, now, m.refreshTTL)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	refresh, err := m.sign(sessionClaims{Refresh: true, RegisteredClaims: refreshClaims})
+	if err != nil {
+		return tokenResponse{}, err
+	}
+
+	m.mu.Lock()
+	for id, expiry := range m.refresh {
+		if now.After(expiry) {
+			delete(m.refresh, id)
+		}
+	}
+	m.refresh[refreshClaims.ID] = refreshClaims.ExpiresAt.Time
+	m.mu.Unlock()
+
+	return tokenResponse{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(m.accessTTL / time.Second),
+		Roles:        user.Roles,
+	}, nil
+}
+
+// registeredClaims returns the standard claims of a token with a random ID,
+// which refresh tokens are retired by.
+func (m *sessionManager) registeredClaims(username string, now time.Time, ttl time.Duration) (jwt.RegisteredClaims, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return jwt.RegisteredClaims{}, fmt.Errorf("generating token ID: %w", err)
+	}
+	return jwt.RegisteredClaims{
+		Issuer:    jwtIssuer,
+		Subject:   username,
+		ID:        hex.EncodeToString(id),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}, nil
+}
+
+func (m *sessionManager) sign(claims sessionClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.key)
+}
+
+// verify parses a token, checking its signature, expiry and kind, and that
+// its user still exists.
+func (m *sessionManager) verify(token string, refresh bool) (*sessionClaims, error) {
+	claims := &sessionClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return m.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(jwtIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Refresh != refresh {
+		return nil, errors.New("wrong token kind")
+	}
+	if _, ok := m.users[claims.Subject]; !ok {
+		return nil, errors.New("unknown user")
+	}
+	return claims, nil
+}
+
+// retire invalidates a refresh token, reporting whether it was still live.
+func (m *sessionManager) retire(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expiry, ok := m.refresh[id]
+	delete(m.refresh, id)
+	return ok && time.Now().Before(expiry)
+}
+
+// handleLogin exchanges a username and password for a token pair.
+func handleLogin(c *jacked.Context) error {
+	var req loginRequest
+	body, err := readBody(c, maxSessionBody)
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		return bodyError(c, err, "Invalid JSON")
+	}
+	user, ok := sessions.login(req.Username, req.Password)
+	if !ok {
+		logRequestf(c.Request, "Failed login for %q from %s", req.Username, c.Request.RemoteAddr)
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid username or password"})
+	}
+	tokens, err := sessions.issue(user)
+	if err != nil {
+		logRequestf(c.Request, "Error issuing tokens: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to issue tokens"})
+	}
+	logRequestf(c.Request, "User %s logged in from %s", user.Username, c.Request.RemoteAddr)
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// handleRefreshToken exchanges a refresh token for a new token pair.
+func handleRefreshToken(c *jacked.Context) error {
+	var req refreshRequest
+	body, err := readBody(c, maxSessionBody)
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		return bodyError(c, err, "Invalid JSON")
+	}
+	claims, err := sessions.verify(req.RefreshToken, true)
+	if err != nil || !sessions.retire(claims.ID) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid or expired refresh token"})
+	}
+	tokens, err := sessions.issue(sessions.users[claims.Subject])
+	if err != nil {
+		logRequestf(c.Request, "Error issuing tokens: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to issue tokens"})
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// handleLogout retires a refresh token. The access token stays valid until
+// it expires.
+func handleLogout(c *jacked.Context) error {
+	var req refreshRequest
+	body, err := readBody(c, maxSessionBody)
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		return bodyError(c, err, "Invalid JSON")
+	}
+	if claims, err := sessions.verify(req.RefreshToken, true); err == nil {
+		sessions.retire(claims.ID)
+		logRequestf(c.Request, "User %s logged out", claims.Subject)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "logged out"})
+}
+
+// hashPassword reads a password from stdin and prints its bcrypt hash for
+// auth.users, for -hash-password.
+func hashPassword() error {
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return err
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return errors.New("empty password")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("password_hash: %q\n", hash)
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout bounds a graceful shutdown; whatever is still running
+// after it is cut off.
+const shutdownTimeout = 15 * time.Second
+
+// shutdown stops the servers and drains the work in flight. Stream clients
+// get a server_shutdown event and are closed first, as the HTTP and gRPC
+// servers otherwise wait on them. Queued updates are then evaluated and
+// shared with the other replicas, the notifiers get a last delivery round,
+// pending trace spans are exported, and mu is left held so nothing starts a
+// storage write while main closes the store and raw log.
+func shutdown(ctx context.Context, servers []*http.Server, grpcSrv *grpc.Server) {
+	hub.shutdown()
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Shutdown: HTTP server on %s: %v", server.Addr, err)
+		}
+	}
+	if grpcSrv != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcSrv.Stop()
+		}
+	}
+	stopPipeline(ctx)
+	stopCluster()
+	stopNotifiers(ctx, notifiers)
+	stopTracing(ctx)
+
+	locked := make(chan struct{})
+	go func() {
+		mu.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+const signalLossCheckEvery = 15 * time.Second
+
+// signalLost records (criterion, ICAO) pairs already alerted for the current
+// silence so each outage produces one alert. Guarded by mu.
+var signalLost = map[string]bool{}
+
+func validateSignalLoss(p SignalLossParams) error {
+	if p.Minutes <= 0 || time.Duration(p.Minutes)*time.Minute >= trackRetention {
+		return fmt.Errorf("signal loss minutes must be between 1 and %d", int(trackRetention.Minutes())-1)
+	}
+	if p.MaxAltitude < 0 {
+		return fmt.Errorf("signal loss max_altitude must not be negative")
+	}
+	return nil
+}
+
+// watchSignalLoss periodically checks the last position of every tracked
+// aircraft against the enabled signal-loss criteria.
+func watchSignalLoss() {
+	ticker := time.NewTicker(signalLossCheckEvery)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		mu.Lock()
+		checkSignalLoss(now)
+		mu.Unlock()
+	}
+}
+
+// checkSignalLoss fires signal_lost alerts for aircraft silent for longer
+// than a criterion allows. Callers must hold mu.
+func checkSignalLoss(now time.Time) {
+	criteria, err := store.ListCriteria()
+	if err != nil {
+		log.Printf("Error loading alert criteria: %v", err)
+		return
+	}
+	for i := range criteria {
+		criterion := &criteria[i]
+		if !criterion.Enabled || criterion.SignalLoss == nil {
+			continue
+		}
+		for _, lost := range detectSignalLoss(criterion, now) {
+			triggerAlert(criterion, lost.aircraft, eventSignalLost, lost.message)
+		}
+	}
+}
+
+// signalLossHit is an aircraft whose silence just crossed the threshold.
+type signalLossHit struct {
+	aircraft Aircraft
+	message  string
+}
+
+// detectSignalLoss returns the tracked aircraft that newly exceed the
+// criterion's silence threshold. Callers must hold mu.
+func detectSignalLoss(ac *AlertCriteria, now time.Time) []signalLossHit {
+	p := ac.SignalLoss
+	var hits []signalLossHit
+	for icao, points := range tracks {
+		if len(points) == 0 {
+			continue
+		}
+		last := points[len(points)-1]
+		key := ac.ID + "|" + icao
+		silence := now.Sub(last.Timestamp)
+		if signalLost[key] || silence < time.Duration(p.Minutes)*time.Minute {
+			continue
+		}
+		if p.MaxAltitude != 0 && last.Altitude >= p.MaxAltitude {
+			continue
+		}
+		if !criterionMatches(ac, last) {
+			continue
+		}
+		signalLost[key] = true
+		hits = append(hits, signalLossHit{last, fmt.Sprintf(
+			"Signal lost: %s (%s) not seen for %d minutes, last at %d ft near %.4f, %.4f",
+			last.Callsign, last.ICAO, int(silence.Minutes()), last.Altitude, last.Latitude, last.Longitude)})
+	}
+	return hits
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	stateSweepEvery = 10 * time.Second
+	// stateShards splits the registry so concurrent feeders and readers of
+	// different aircraft rarely wait on each other.
+	stateShards = 32
+)
+
+// StateConfig controls the registry of aircraft currently in the air.
+type StateConfig struct {
+	// ExpireAfter drops an aircraft from the registry, with an expired
+	// stream event, once it has not reported for this long. It cannot
+	// exceed the 30 minute track retention.
+	ExpireAfter time.Duration `yaml:"expire_after"`
+	// DeadReckoning projects a silent aircraft along its last track for up
+	// to this long, so maps keep it moving; 0 turns it off.
+	DeadReckoning time.Duration `yaml:"dead_reckoning"`
+}
+
+func defaultStateConfig() StateConfig {
+	return StateConfig{ExpireAfter: 5 * time.Minute, DeadReckoning: 30 * time.Second}
+}
+
+var stateConfig = defaultStateConfig()
+
+type stateShard struct {
+	sync.RWMutex
+	aircraft map[string]Aircraft
+}
+
+// aircraftStates holds the latest report of every aircraft in the air,
+// sharded by ICAO. It has its own locks rather than mu. Track history lives
+// on in tracks after an aircraft expires, for the detectors and the detail
+// view.
+var aircraftStates [stateShards]stateShard
+
+// shardIndex spreads ICAO addresses evenly over n shards.
+func shardIndex(icao string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(icao))
+	return int(h.Sum32() % uint32(n))
+}
+
+func stateShardOf(icao string) *stateShard {
+	return &aircraftStates[shardIndex(icao, stateShards)]
+}
+
+// updateState records the aircraft's report unless a newer one is already
+// known, which happens when feeders deliver out of order.
+func updateState(aircraft Aircraft) {
+	s := stateShardOf(aircraft.ICAO)
+	s.Lock()
+	defer s.Unlock()
+	if last, ok := s.aircraft[aircraft.ICAO]; ok && aircraft.Timestamp.Before(last.Timestamp) {
+		return
+	}
+	if s.aircraft == nil {
+		s.aircraft = map[string]Aircraft{}
+	}
+	s.aircraft[aircraft.ICAO] = aircraft
+	indexAircraft(aircraft)
+}
+
+// lookupState returns the latest report of icao.
+func lookupState(icao string) (Aircraft, bool) {
+	s := stateShardOf(icao)
+	s.RLock()
+	defer s.RUnlock()
+	aircraft, ok := s.aircraft[icao]
+	return aircraft, ok
+}
+
+// removeState drops icao from the registry, reporting whether it was there.
+func removeState(icao string) bool {
+	s := stateShardOf(icao)
+	s.Lock()
+	defer s.Unlock()
+	_, ok := s.aircraft[icao]
+	delete(s.aircraft, icao)
+	unindexAircraft(icao)
+	return ok
+}
+
+// allStates returns the latest report of every registered aircraft, in no
+// particular order.
+func allStates() []Aircraft {
+	var list []Aircraft
+	for i := range aircraftStates {
+		s := &aircraftStates[i]
+		s.RLock()
+		for _, aircraft := range s.aircraft {
+			list = append(list, aircraft)
+		}
+		s.RUnlock()
+	}
+	return list
+}
+
+// expireStates periodically drops aircraft that have not reported within
+// stateConfig.ExpireAfter and tells stream clients to take them off the map.
+func expireStates() {
+	ticker := time.NewTicker(stateSweepEvery)
+	defer ticker.Stop()
+	for range ticker.C {
+		cutoff := time.Now().Add(-stateConfig.ExpireAfter)
+		var expired []Aircraft
+		for i := range aircraftStates {
+			s := &aircraftStates[i]
+			s.Lock()
+			for icao, aircraft := range s.aircraft {
+				if aircraft.Timestamp.Before(cutoff) {
+					delete(s.aircraft, icao)
+					unindexAircraft(icao)
+					expired = append(expired, aircraft)
+				}
+			}
+			s.Unlock()
+		}
+		for _, aircraft := range expired {
+			forgetOutlier(aircraft.ICAO)
+			hub.broadcast <- []byte("event: expired\ndata: {\"icao\":\"" + aircraft.ICAO + "\"}\n\n")
+			log.Printf("Aircraft %s (%s) expired after %s without reports", aircraft.ICAO, aircraft.Callsign, stateConfig.ExpireAfter)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// Update sources counted by countMessage.
+const (
+	sourceHTTP   = "http"
+	sourceGRPC   = "grpc"
+	sourceReplay = "replay"
+)
+
+const (
+	messageRateWindow = 60 // Seconds the message rate is averaged over
+	topCriteriaCount  = 10
+)
+
+var startTime = time.Now()
+
+// messageStats counts received updates per source.
+var messageStats = struct {
+	sync.Mutex
+	sources map[string]*messageRate
+}{sources: map[string]*messageRate{}}
+
+// messageRate keeps per-second counts for the last messageRateWindow seconds.
+type messageRate struct {
+	total   int64
+	buckets [messageRateWindow]int64
+	last    int64     // Unix second of the newest bucket
+	latest  time.Time // When the last update arrived
+}
+
+// advance clears the buckets of the seconds since the newest one.
+func (r *messageRate) advance(now int64) {
+	if now-r.last >= messageRateWindow {
+		r.buckets = [messageRateWindow]int64{}
+	} else {
+		for s := r.last + 1; s <= now; s++ {
+			r.buckets[s%messageRateWindow] = 0
+		}
+	}
+	if now > r.last {
+		r.last = now
+	}
+}
+
+func (r *messageRate) perSecond(now int64) float64 {
+	r.advance(now)
+	var sum int64
+	for _, n := range r.buckets {
+		sum += n
+	}
+	return float64(sum) / messageRateWindow
+}
+
+// countMessage records an update received from source.
+func countMessage(source string) {
+	now := time.Now().Unix()
+	messageStats.Lock()
+	defer messageStats.Unlock()
+	r, ok := messageStats.sources[source]
+	if !ok {
+		r = &messageRate{last: now}
+		messageStats.sources[source] = r
+	}
+	r.advance(now)
+	r.buckets[now%messageRateWindow]++
+	r.total++
+	r.latest = time.Now()
+}
+
+// SourceStats describes the updates received from one source.
+type SourceStats struct {
+	PerSecond float64 `json:"per_second"` // Averaged over the last minute
+	Total     int64   `json:"total"`      // Since the server started
+}
+
+// CriterionStats is a criterion with its recent alert count.
+type CriterionStats struct {
+	ID     string `json:"id"`
+	Alerts int    `json:"alerts"` // In the last day
+}
+
+// Stats is the server overview returned by GET /api/v1/stats.
+type Stats struct {
+	Aircraft           int                    `json:"aircraft"`             // Tracked, heard within the track retention
+	AircraftLastMinute int                    `json:"aircraft_last_minute"` // Heard within the last minute
+	Messages           map[string]SourceStats `json:"messages"`             // By source: http, grpc or replay
+	AlertsLastHour     int                    `json:"alerts_last_hour"`
+	AlertsLastDay      int                    `json:"alerts_last_day"`
+	TopCriteria        []CriterionStats       `json:"top_criteria"`   // Most alerts in the last day
+	StreamClients      int                    `json:"stream_clients"` // SSE, WebSocket and gRPC streams
+	Pipeline           PipelineStats          `json:"pipeline"`
+	Validation         ValidationStats        `json:"validation"`     // Updates failing the ingest checks
+	Panics             uint64                 `json:"panics"`         // Handler panics recovered
+	SpilledAlerts      uint64                 `json:"spilled_alerts"` // Pushed out of memory storage by its alert limit
+	DroppedAlerts      uint64                 `json:"dropped_alerts"` // Of those, not written to the alert archive
+	UptimeSeconds      int64                  `json:"uptime_seconds"`
+	StartedAt          time.Time              `json:"started_at"`
+}
+
+// handleStats returns counters for dashboards and health checks.
+func handleStats(c *jacked.Context) error {
+	now := time.Now()
+	stats := Stats{
+		Messages:      map[string]SourceStats{},
+		TopCriteria:   []CriterionStats{},
+		StreamClients: int(hub.clientCount.Load()),
+		Pipeline:      pipelineStats(),
+		Panics:        recoveredPanics.Load(),
+		SpilledAlerts: spilledAlerts.Load(),
+		DroppedAlerts: droppedAlerts.Load(),
+		Validation:    ValidationStats{Rejected: validationStats.rejected.Load(), Flagged: validationStats.flagged.Load(), Outliers: validationStats.outliers.Load()},
+		UptimeSeconds: int64(now.Sub(startTime).Seconds()),
+		StartedAt:     startTime,
+	}
+
+	for _, state := range trackedAircraft(trackRetention, nil) {
+		stats.Aircraft++
+		if now.Sub(state.LastSeen) <= time.Minute {
+			stats.AircraftLastMinute++
+		}
+	}
+
+	messageStats.Lock()
+	for source, r := range messageStats.sources {
+		stats.Messages[source] = SourceStats{PerSecond: r.perSecond(now.Unix()), Total: r.total}
+	}
+	messageStats.Unlock()
+
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		return storeError(c, err)
+	}
+	perCriterion := map[string]int{}
+	for _, alert := range alerts {
+		age := now.Sub(alert.Timestamp)
+		if age > 24*time.Hour {
+			continue
+		}
+		stats.AlertsLastDay++
+		if age <= time.Hour {
+			stats.AlertsLastHour++
+		}
+		if alert.Criteria.ID != "" {
+			perCriterion[alert.Criteria.ID]++
+		}
+	}
+	for id, n := range perCriterion {
+		stats.TopCriteria = append(stats.TopCriteria, CriterionStats{ID: id, Alerts: n})
+	}
+	sort.Slice(stats.TopCriteria, func(i, j int) bool {
+		a, b := stats.TopCriteria[i], stats.TopCriteria[j]
+		if a.Alerts != b.Alerts {
+			return a.Alerts > b.Alerts
+		}
+		return a.ID < b.ID
+	})
+	if len(stats.TopCriteria) > topCriteriaCount {
+		stats.TopCriteria = stats.TopCriteria[:topCriteriaCount]
+	}
+	return c.JSON(http.StatusOK, stats)
+}
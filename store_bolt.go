@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bolt bucket names. Criteria and alerts are keyed by an insertion sequence
+// to keep their order, with a separate ID index; positions live in one
+// nested bucket per ICAO keyed by timestamp.
+var (
+	boltCriteria      = []byte("criteria")
+	boltCriteriaIDs   = []byte("criteria_ids")
+	boltAlerts        = []byte("alerts")
+	boltAlertIDs      = []byte("alert_ids")
+	boltAlertsArchive = []byte("alerts_archive")
+	boltPositions     = []byte("positions")
+	boltDeliveries    = []byte("deliveries")
+	boltDeliveryIDs   = []byte("delivery_ids")
+	boltPushSubs      = []byte("push_subscriptions")
+	boltPushSubIDs    = []byte("push_subscription_ids")
+	boltPolicies      = []byte("escalation_policies")
+	boltPolicyIDs     = []byte("escalation_policy_ids")
+)
+
+// boltStore is a pure-Go embedded Store backed by a single bbolt file, for
+// deployments without a database server.
+type boltStore struct {
+	db *bolt.DB
+}
+
+func newBoltStore(path string) (*boltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltCriteria, boltCriteriaIDs, boltAlerts, boltAlertIDs, boltAlertsArchive, boltPositions, boltDeliveries, boltDeliveryIDs, boltPushSubs, boltPushSubIDs, boltPolicies, boltPolicyIDs} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+// boltKey encodes n as a big-endian key so keys sort numerically.
+func boltKey(n uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, n)
+}
+
+// putIndexed stores v in bucket under the sequence key of id, allocating a
+// new sequence when id is not yet in the index.
+func putIndexed(tx *bolt.Tx, bucket, index []byte, id string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b, idx := tx.Bucket(bucket), tx.Bucket(index)
+	key := idx.Get([]byte(id))
+	if key == nil {
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key = boltKey(seq)
+		if err := idx.Put([]byte(id), key); err != nil {
+			return err
+		}
+	}
+	return b.Put(key, data)
+}
+
+// getIndexed decodes the record stored for id into v.
+func getIndexed(tx *bolt.Tx, bucket, index []byte, id string, v interface{}) error {
+	key := tx.Bucket(index).Get([]byte(id))
+	if key == nil {
+		return errNotFound
+	}
+	return json.Unmarshal(tx.Bucket(bucket).Get(key), v)
+}
+
+// deleteIndexed removes the record stored for id and its index entry.
+func deleteIndexed(tx *bolt.Tx, bucket, index []byte, id string) error {
+	idx := tx.Bucket(index)
+	key := idx.Get([]byte(id))
+	if key == nil {
+		return errNotFound
+	}
+	if err := tx.Bucket(bucket).Delete(key); err != nil {
+		return err
+	}
+	return idx.Delete([]byte(id))
+}
+
+// listBucket decodes every value in bucket, in key order.
+func listBucket[T any](tx *bolt.Tx, bucket []byte) ([]T, error) {
+	var out []T
+	err := tx.Bucket(bucket).ForEach(func(_, data []byte) error {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		out = append(out, v)
+		return nil
+	})
+	return out, err
+}
+
+func (s *boltStore) ListCriteria() ([]AlertCriteria, error) {
+	var criteria []AlertCriteria
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		criteria, err = listBucket[AlertCriteria](tx, boltCriteria)
+		return err
+	})
+	return criteria, err
+}
+
+func (s *boltStore) GetCriterion(id string) (AlertCriteria, error) {
+	var criterion AlertCriteria
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return getIndexed(tx, boltCriteria, boltCriteriaIDs, id, &criterion)
+	})
+	return criterion, err
+}
+
+func (s *boltStore) SaveCriterion(criterion AlertCriteria) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putIndexed(tx, boltCriteria, boltCriteriaIDs, criterion.ID, criterion)
+	})
+}
+
+func (s *boltStore) DeleteCriterion(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteIndexed(tx, boltCriteria, boltCriteriaIDs, id)
+	})
+}
+
+func (s *boltStore) ReplaceCriteria(criteria []AlertCriteria) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltCriteria, boltCriteriaIDs} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		for _, c := range criteria {
+			if err := putIndexed(tx, boltCriteria, boltCriteriaIDs, c.ID, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) AddAlert(alert Alert) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putIndexed(tx, boltAlerts, boltAlertIDs, alert.ID, alert)
+	})
+}
+
+func (s *boltStore) ListAlerts() ([]Alert, error) {
+	var alerts []Alert
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		alerts, err = listBucket[Alert](tx, boltAlerts)
+		return err
+	})
+	return alerts, err
+}
+
+func (s *boltStore) GetAlert(id string) (Alert, error) {
+	var alert Alert
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return getIndexed(tx, boltAlerts, boltAlertIDs, id, &alert)
+	})
+	return alert, err
+}
+
+func (s *boltStore) UpdateAlert(alert Alert) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(boltAlertIDs).Get([]byte(alert.ID)) == nil {
+			return errNotFound
+		}
+		return putIndexed(tx, boltAlerts, boltAlertIDs, alert.ID, alert)
+	})
+}
+
+func (s *boltStore) DeleteAlert(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteIndexed(tx, boltAlerts, boltAlertIDs, id)
+	})
+}
+
+// ArchiveAlerts moves alerts into the archive bucket, where they are kept
+// but no longer listed.
+func (s *boltStore) ArchiveAlerts(before time.Time, keep int) ([]Alert, error) {
+	var archived []Alert
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		alerts, archive, idx := tx.Bucket(boltAlerts), tx.Bucket(boltAlertsArchive), tx.Bucket(boltAlertIDs)
+		excess := alerts.Stats().KeyN - keep
+		if keep <= 0 {
+			excess = 0
+		}
+		c := alerts.Cursor()
+		for key, data := c.First(); key != nil; key, data = c.First() {
+			var alert Alert
+			if err := json.Unmarshal(data, &alert); err != nil {
+				return err
+			}
+			if excess <= 0 && !alert.Timestamp.Before(before) {
+				break
+			}
+			if err := archive.Put(bytes.Clone(key), bytes.Clone(data)); err != nil {
+				return err
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			if err := idx.Delete([]byte(alert.ID)); err != nil {
+				return err
+			}
+			archived = append(archived, alert)
+			excess--
+		}
+		return nil
+	})
+	return archived, err
+}
+
+func (s *boltStore) SaveDelivery(d Delivery) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putIndexed(tx, boltDeliveries, boltDeliveryIDs, d.ID, d)
+	})
+}
+
+func (s *boltStore) ListDeliveries() ([]Delivery, error) {
+	var deliveries []Delivery
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		deliveries, err = listBucket[Delivery](tx, boltDeliveries)
+		return err
+	})
+	return deliveries, err
+}
+
+func (s *boltStore) DeleteDelivery(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteIndexed(tx, boltDeliveries, boltDeliveryIDs, id)
+	})
+}
+
+func (s *boltStore) SavePushSubscription(sub PushSubscription) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putIndexed(tx, boltPushSubs, boltPushSubIDs, sub.ID, sub)
+	})
+}
+
+func (s *boltStore) ListPushSubscriptions() ([]PushSubscription, error) {
+	var subs []PushSubscription
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		subs, err = listBucket[PushSubscription](tx, boltPushSubs)
+		return err
+	})
+	return subs, err
+}
+
+func (s *boltStore) DeletePushSubscription(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteIndexed(tx, boltPushSubs, boltPushSubIDs, id)
+	})
+}
+
+func (s *boltStore) SaveEscalationPolicy(p EscalationPolicy) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putIndexed(tx, boltPolicies, boltPolicyIDs, p.ID, p)
+	})
+}
+
+func (s *boltStore) ListEscalationPolicies() ([]EscalationPolicy, error) {
+	var policies []EscalationPolicy
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		policies, err = listBucket[EscalationPolicy](tx, boltPolicies)
+		return err
+	})
+	return policies, err
+}
+
+func (s *boltStore) DeleteEscalationPolicy(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteIndexed(tx, boltPolicies, boltPolicyIDs, id)
+	})
+}
+
+func (s *boltStore) AddPosition(aircraft Aircraft) error {
+	data, err := json.Marshal(aircraft)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(boltPositions).CreateBucketIfNotExists([]byte(aircraft.ICAO))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		// Timestamp first for ordering, sequence to keep equal timestamps apart.
+		key := append(boltKey(uint64(aircraft.Timestamp.UnixNano())), boltKey(seq)...)
+		return b.Put(key, data)
+	})
+}
+
+func (s *boltStore) Positions(icao string, since time.Time) ([]Aircraft, error) {
+	var out []Aircraft
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltPositions).Bucket([]byte(icao))
+		if b == nil {
+			return nil
+		}
+		var err error
+		out, err = scanPositions(b, since, nil)
+		return err
+	})
+	return out, err
+}
+
+func (s *boltStore) PositionsInZone(zone Zone, since time.Time) ([]Aircraft, error) {
+	var out []Aircraft
+	err := s.db.View(func(tx *bolt.Tx) error {
+		positions := tx.Bucket(boltPositions)
+		return positions.ForEachBucket(func(icao []byte) error {
+			list, err := scanPositions(positions.Bucket(icao), since, func(p Aircraft) bool {
+				return zone.contains(p.Latitude, p.Longitude)
+			})
+			out = append(out, list...)
+			return err
+		})
+	})
+	sort.Slice(out, func(a, b int) bool { return out[a].Timestamp.Before(out[b].Timestamp) })
+	return out, err
+}
+
+func (s *boltStore) LatestPositions(since time.Time) ([]Aircraft, error) {
+	var out []Aircraft
+	err := s.db.View(func(tx *bolt.Tx) error {
+		positions := tx.Bucket(boltPositions)
+		return positions.ForEachBucket(func(icao []byte) error {
+			_, data := positions.Bucket(icao).Cursor().Last()
+			if data == nil {
+				return nil
+			}
+			var p Aircraft
+			if err := json.Unmarshal(data, &p); err != nil {
+				return err
+			}
+			if !p.Timestamp.Before(since) {
+				out = append(out, p)
+			}
+			return nil
+		})
+	})
+	return out, err
+}
+
+// scanPositions decodes the positions in b recorded since the given time,
+// optionally keeping only those accepted by keep.
+func scanPositions(b *bolt.Bucket, since time.Time, keep func(Aircraft) bool) ([]Aircraft, error) {
+	var out []Aircraft
+	c := b.Cursor()
+	for key, data := c.Seek(boltKey(uint64(since.UnixNano()))); key != nil; key, data = c.Next() {
+		var p Aircraft
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
+		}
+		if keep == nil || keep(p) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (s *boltStore) PrunePositions(before time.Time) error {
+	cutoff := boltKey(uint64(before.UnixNano()))
+	return s.db.Update(func(tx *bolt.Tx) error {
+		positions := tx.Bucket(boltPositions)
+		var empty [][]byte
+		err := positions.ForEachBucket(func(icao []byte) error {
+			b := positions.Bucket(icao)
+			c := b.Cursor()
+			for key, _ := c.First(); key != nil && bytes.Compare(key[:8], cutoff) < 0; key, _ = c.First() {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+			if k, _ := c.First(); k == nil {
+				empty = append(empty, bytes.Clone(icao))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, icao := range empty {
+			if err := positions.DeleteBucket(icao); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) Ping(context.Context) error {
+	return s.db.View(func(*bolt.Tx) error { return nil })
+}
+
+func (s *boltStore) Close() error { return s.db.Close() }
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore is the default Store, keeping everything in RAM.
+type memoryStore struct {
+	mu        sync.RWMutex
+	criteria  []AlertCriteria
+	alerts    alertRing
+	positions map[string][]Aircraft
+	queue     []Delivery
+	pushSubs  []PushSubscription
+	policies  []EscalationPolicy
+}
+
+// newMemoryStore returns an empty store holding at most alertLimit alerts;
+// zero means no limit.
+func newMemoryStore(alertLimit int) *memoryStore {
+	return &memoryStore{alerts: alertRing{limit: alertLimit}, positions: map[string][]Aircraft{}}
+}
+
+func (s *memoryStore) ListCriteria() ([]AlertCriteria, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]AlertCriteria(nil), s.criteria...), nil
+}
+
+func (s *memoryStore) GetCriterion(id string) (AlertCriteria, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.criteria {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return AlertCriteria{}, errNotFound
+}
+
+func (s *memoryStore) SaveCriterion(criterion AlertCriteria) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.criteria {
+		if s.criteria[i].ID == criterion.ID {
+			s.criteria[i] = criterion
+			return nil
+		}
+	}
+	s.criteria = append(s.criteria, criterion)
+	return nil
+}
+
+func (s *memoryStore) DeleteCriterion(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.criteria {
+		if s.criteria[i].ID == id {
+			s.criteria = append(s.criteria[:i], s.criteria[i+1:]...)
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) ReplaceCriteria(criteria []AlertCriteria) error {
+	replaced := make([]AlertCriteria, 0, len(criteria))
+	at := map[string]int{}
+	for _, c := range criteria {
+		if i, ok := at[c.ID]; ok {
+			replaced[i] = c
+			continue
+		}
+		at[c.ID] = len(replaced)
+		replaced = append(replaced, c)
+	}
+	s.mu.Lock()
+	s.criteria = replaced
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryStore) AddAlert(alert Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if oldest, full := s.alerts.push(alert); full {
+		spillAlert(oldest)
+	}
+	return nil
+}
+
+func (s *memoryStore) ListAlerts() ([]Alert, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.alerts.list(), nil
+}
+
+func (s *memoryStore) GetAlert(id string) (Alert, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i := s.alerts.find(id); i >= 0 {
+		return s.alerts.buf[i], nil
+	}
+	return Alert{}, errNotFound
+}
+
+func (s *memoryStore) UpdateAlert(alert Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.alerts.find(alert.ID); i >= 0 {
+		s.alerts.buf[i] = alert
+		return nil
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) DeleteAlert(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	alerts := s.alerts.list()
+	for i := range alerts {
+		if alerts[i].ID == id {
+			s.alerts.reset(append(alerts[:i], alerts[i+1:]...))
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) ArchiveAlerts(before time.Time, keep int) ([]Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	alerts := s.alerts.list()
+	n := 0
+	for n < len(alerts) && alerts[n].Timestamp.Before(before) {
+		n++
+	}
+	if keep > 0 && len(alerts)-n > keep {
+		n = len(alerts) - keep
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	s.alerts.reset(append([]Alert(nil), alerts[n:]...))
+	return alerts[:n], nil
+}
+
+func (s *memoryStore) SaveDelivery(d Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.queue {
+		if s.queue[i].ID == d.ID {
+			s.queue[i] = d
+			return nil
+		}
+	}
+	s.queue = append(s.queue, d)
+	return nil
+}
+
+func (s *memoryStore) ListDeliveries() ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Delivery(nil), s.queue...), nil
+}
+
+func (s *memoryStore) SavePushSubscription(sub PushSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.pushSubs {
+		if s.pushSubs[i].ID == sub.ID {
+			s.pushSubs[i] = sub
+			return nil
+		}
+	}
+	s.pushSubs = append(s.pushSubs, sub)
+	return nil
+}
+
+func (s *memoryStore) ListPushSubscriptions() ([]PushSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]PushSubscription(nil), s.pushSubs...), nil
+}
+
+func (s *memoryStore) DeletePushSubscription(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.pushSubs {
+		if s.pushSubs[i].ID == id {
+			s.pushSubs = append(s.pushSubs[:i], s.pushSubs[i+1:]...)
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) SaveEscalationPolicy(p EscalationPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.policies {
+		if s.policies[i].ID == p.ID {
+			s.policies[i] = p
+			return nil
+		}
+	}
+	s.policies = append(s.policies, p)
+	return nil
+}
+
+func (s *memoryStore) ListEscalationPolicies() ([]EscalationPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]EscalationPolicy(nil), s.policies...), nil
+}
+
+func (s *memoryStore) DeleteEscalationPolicy(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.policies {
+		if s.policies[i].ID == id {
+			s.policies = append(s.policies[:i], s.policies[i+1:]...)
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) DeleteDelivery(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.queue {
+		if s.queue[i].ID == id {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+func (s *memoryStore) AddPosition(aircraft Aircraft) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.positions[aircraft.ICAO] = append(s.positions[aircraft.ICAO], aircraft)
+	return nil
+}
+
+func (s *memoryStore) Positions(icao string, since time.Time) ([]Aircraft, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Aircraft
+	for _, p := range s.positions[icao] {
+		if !p.Timestamp.Before(since) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryStore) PrunePositions(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for icao, points := range s.positions {
+		i := 0
+		for i < len(points) && points[i].Timestamp.Before(before) {
+			i++
+		}
+		if i == len(points) {
+			delete(s.positions, icao)
+		} else if i > 0 {
+			s.positions[icao] = append([]Aircraft(nil), points[i:]...)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) Ping(context.Context) error { return nil }
+
+func (s *memoryStore) Close() error { return nil }
+
+func (s *memoryStore) PositionsInZone(zone Zone, since time.Time) ([]Aircraft, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Aircraft
+	for _, points := range s.positions {
+		for _, p := range points {
+			if !p.Timestamp.Before(since) && zone.contains(p.Latitude, p.Longitude) {
+				out = append(out, p)
+			}
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Timestamp.Before(out[b].Timestamp) })
+	return out, nil
+}
+
+func (s *memoryStore) LatestPositions(since time.Time) ([]Aircraft, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Aircraft
+	for _, points := range s.positions {
+		if last := points[len(points)-1]; !last.Timestamp.Before(since) {
+			out = append(out, last)
+		}
+	}
+	return out, nil
+}
+
+// alertRing holds the newest alerts, up to limit (zero means no limit).
+// Once full, each new alert takes the place of the oldest, so a criterion
+// matching everything cannot grow the process without bound.
+type alertRing struct {
+	buf   []Alert
+	start int // Index of the oldest alert
+	limit int
+}
+
+// push adds an alert, returning the oldest one if it made room for it.
+func (r *alertRing) push(alert Alert) (Alert, bool) {
+	if r.limit == 0 || len(r.buf) < r.limit {
+		r.buf = append(r.buf, alert)
+		return Alert{}, false
+	}
+	oldest := r.buf[r.start]
+	r.buf[r.start] = alert
+	r.start = (r.start + 1) % len(r.buf)
+	return oldest, true
+}
+
+// list returns a copy of the alerts, oldest first.
+func (r *alertRing) list() []Alert {
+	alerts := make([]Alert, 0, len(r.buf))
+	alerts = append(alerts, r.buf[r.start:]...)
+	return append(alerts, r.buf[:r.start]...)
+}
+
+// find returns the index in buf of the alert with the ID, or -1.
+func (r *alertRing) find(id string) int {
+	for i := range r.buf {
+		if r.buf[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// reset replaces the contents with alerts, oldest first, which must not
+// number more than the limit.
+func (r *alertRing) reset(alerts []Alert) {
+	r.buf = alerts
+	r.start = 0
+}
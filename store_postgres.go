@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresStore keeps criteria, alerts and positions in PostgreSQL. Records
+// are stored as JSONB next to the columns needed for querying. With PostGIS
+// enabled, positions also get a geography column with a spatial index.
+type postgresStore struct {
+	db      *sql.DB
+	postgis bool
+}
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS criteria (
+	seq  BIGSERIAL,
+	id   TEXT PRIMARY KEY,
+	data JSONB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS alerts (
+	seq  BIGSERIAL,
+	id   TEXT PRIMARY KEY,
+	ts   TIMESTAMPTZ NOT NULL,
+	data JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS alerts_ts ON alerts (ts);
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT false;
+CREATE TABLE IF NOT EXISTS deliveries (
+	seq  BIGSERIAL,
+	id   TEXT PRIMARY KEY,
+	data JSONB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+	seq  BIGSERIAL,
+	id   TEXT PRIMARY KEY,
+	data JSONB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS escalation_policies (
+	seq  BIGSERIAL,
+	id   TEXT PRIMARY KEY,
+	data JSONB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS positions (
+	icao TEXT NOT NULL,
+	ts   TIMESTAMPTZ NOT NULL,
+	lat  DOUBLE PRECISION NOT NULL,
+	lon  DOUBLE PRECISION NOT NULL,
+	data JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS positions_icao_ts ON positions (icao, ts);
+CREATE INDEX IF NOT EXISTS positions_ts ON positions (ts);
+`
+
+const postgisSchema = `
+CREATE EXTENSION IF NOT EXISTS postgis;
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS geom geography(Point, 4326);
+CREATE INDEX IF NOT EXISTS positions_geom ON positions USING GIST (geom);
+`
+
+func newPostgresStore(dsn string, postgis bool) (*postgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connecting to postgres: %w", err)
+	}
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating postgres schema: %w", err)
+	}
+	if postgis {
+		if _, err := db.Exec(postgisSchema); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("enabling postgis: %w", err)
+		}
+	}
+	return &postgresStore{db: db, postgis: postgis}, nil
+}
+
+func (s *postgresStore) ListCriteria() ([]AlertCriteria, error) {
+	rows, err := s.db.Query(`SELECT data FROM criteria ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	return scanJSONRows[AlertCriteria](rows)
+}
+
+func (s *postgresStore) GetCriterion(id string) (AlertCriteria, error) {
+	var criterion AlertCriteria
+	err := scanJSONRow(s.db.QueryRow(`SELECT data FROM criteria WHERE id = $1`, id), &criterion)
+	return criterion, err
+}
+
+func (s *postgresStore) SaveCriterion(criterion AlertCriteria) error {
+	data, err := json.Marshal(criterion)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO criteria (id, data) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`, criterion.ID, data)
+	return err
+}
+
+func (s *postgresStore) DeleteCriterion(id string) error {
+	return checkAffected(s.db.Exec(`DELETE FROM criteria WHERE id = $1`, id))
+}
+
+func (s *postgresStore) ReplaceCriteria(criteria []AlertCriteria) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM criteria`); err != nil {
+		return err
+	}
+	for _, c := range criteria {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO criteria (id, data) VALUES ($1, $2)
+			ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`, c.ID, data); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *postgresStore) AddAlert(alert Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO alerts (id, ts, data) VALUES ($1, $2, $3)`, alert.ID, alert.Timestamp, data)
+	return err
+}
+
+func (s *postgresStore) ListAlerts() ([]Alert, error) {
+	rows, err := s.db.Query(`SELECT data FROM alerts WHERE NOT archived ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	return scanJSONRows[Alert](rows)
+}
+
+// ArchiveAlerts flags alerts as archived; they stay in the table but are no
+// longer listed.
+func (s *postgresStore) ArchiveAlerts(before time.Time, keep int) ([]Alert, error) {
+	limit := sql.NullInt64{Int64: int64(keep), Valid: keep > 0}
+	rows, err := s.db.Query(`UPDATE alerts SET archived = true
+		WHERE NOT archived AND (ts < $1 OR seq NOT IN (
+			SELECT seq FROM alerts WHERE NOT archived ORDER BY seq DESC LIMIT $2))
+		RETURNING data`, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := scanJSONRows[Alert](rows)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(archived, func(i, j int) bool { return archived[i].Timestamp.Before(archived[j].Timestamp) })
+	return archived, nil
+}
+
+func (s *postgresStore) GetAlert(id string) (Alert, error) {
+	var alert Alert
+	err := scanJSONRow(s.db.QueryRow(`SELECT data FROM alerts WHERE id = $1`, id), &alert)
+	return alert, err
+}
+
+func (s *postgresStore) UpdateAlert(alert Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	return checkAffected(s.db.Exec(`UPDATE alerts SET data = $2 WHERE id = $1`, alert.ID, data))
+}
+
+func (s *postgresStore) DeleteAlert(id string) error {
+	return checkAffected(s.db.Exec(`DELETE FROM alerts WHERE id = $1 AND NOT archived`, id))
+}
+
+func (s *postgresStore) SaveDelivery(d Delivery) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO deliveries (id, data) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`, d.ID, data)
+	return err
+}
+
+func (s *postgresStore) ListDeliveries() ([]Delivery, error) {
+	rows, err := s.db.Query(`SELECT data FROM deliveries ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	return scanJSONRows[Delivery](rows)
+}
+
+func (s *postgresStore) DeleteDelivery(id string) error {
+	return checkAffected(s.db.Exec(`DELETE FROM deliveries WHERE id = $1`, id))
+}
+
+func (s *postgresStore) SavePushSubscription(sub PushSubscription) error {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO push_subscriptions (id, data) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`, sub.ID, data)
+	return err
+}
+
+func (s *postgresStore) ListPushSubscriptions() ([]PushSubscription, error) {
+	rows, err := s.db.Query(`SELECT data FROM push_subscriptions ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	return scanJSONRows[PushSubscription](rows)
+}
+
+func (s *postgresStore) DeletePushSubscription(id string) error {
+	return checkAffected(s.db.Exec(`DELETE FROM push_subscriptions WHERE id = $1`, id))
+}
+
+func (s *postgresStore) SaveEscalationPolicy(p EscalationPolicy) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO escalation_policies (id, data) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`, p.ID, data)
+	return err
+}
+
+func (s *postgresStore) ListEscalationPolicies() ([]EscalationPolicy, error) {
+	rows, err := s.db.Query(`SELECT data FROM escalation_policies ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	return scanJSONRows[EscalationPolicy](rows)
+}
+
+func (s *postgresStore) DeleteEscalationPolicy(id string) error {
+	return checkAffected(s.db.Exec(`DELETE FROM escalation_policies WHERE id = $1`, id))
+}
+
+func (s *postgresStore) AddPosition(aircraft Aircraft) error {
+	data, err := json.Marshal(aircraft)
+	if err != nil {
+		return err
+	}
+	if s.postgis {
+		_, err = s.db.Exec(`INSERT INTO positions (icao, ts, lat, lon, data, geom)
+			VALUES ($1, $2, $3, $4, $5, ST_SetSRID(ST_MakePoint($4, $3), 4326)::geography)`,
+			aircraft.ICAO, aircraft.Timestamp, aircraft.Latitude, aircraft.Longitude, data)
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO positions (icao, ts, lat, lon, data) VALUES ($1, $2, $3, $4, $5)`,
+		aircraft.ICAO, aircraft.Timestamp, aircraft.Latitude, aircraft.Longitude, data)
+	return err
+}
+
+func (s *postgresStore) Positions(icao string, since time.Time) ([]Aircraft, error) {
+	rows, err := s.db.Query(`SELECT data FROM positions WHERE icao = $1 AND ts >= $2 ORDER BY ts`, icao, since)
+	if err != nil {
+		return nil, err
+	}
+	return scanJSONRows[Aircraft](rows)
+}
+
+func (s *postgresStore) PrunePositions(before time.Time) error {
+	_, err := s.db.Exec(`DELETE FROM positions WHERE ts < $1`, before)
+	return err
+}
+
+// PositionsInZone returns the positions recorded inside zone since the
+// given time, oldest first. With PostGIS the filter runs in the database
+// against the spatial index; otherwise candidates are filtered here.
+func (s *postgresStore) PositionsInZone(zone Zone, since time.Time) ([]Aircraft, error) {
+	if !s.postgis {
+		rows, err := s.db.Query(`SELECT data FROM positions WHERE ts >= $1 ORDER BY ts`, since)
+		if err != nil {
+			return nil, err
+		}
+		positions, err := scanJSONRows[Aircraft](rows)
+		if err != nil {
+			return nil, err
+		}
+		inside := positions[:0]
+		for _, p := range positions {
+			if zone.contains(p.Latitude, p.Longitude) {
+				inside = append(inside, p)
+			}
+		}
+		return inside, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if zone.Center != nil && zone.Radius > 0 {
+		rows, err = s.db.Query(`SELECT data FROM positions
+			WHERE ts >= $1 AND ST_DWithin(geom, ST_SetSRID(ST_MakePoint($3, $2), 4326)::geography, $4)
+			ORDER BY ts`, since, zone.Center[0], zone.Center[1], zone.Radius*metersPerNM)
+	} else {
+		rows, err = s.db.Query(`SELECT data FROM positions
+			WHERE ts >= $1 AND ST_Covers(ST_GeogFromText($2), geom)
+			ORDER BY ts`, since, polygonWKT(zone.Polygon))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scanJSONRows[Aircraft](rows)
+}
+
+func (s *postgresStore) LatestPositions(since time.Time) ([]Aircraft, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT ON (icao) data FROM positions WHERE ts >= $1 ORDER BY icao, ts DESC`, since)
+	if err != nil {
+		return nil, err
+	}
+	return scanJSONRows[Aircraft](rows)
+}
+
+// polygonWKT renders [lat, lon] vertices as a closed WKT polygon.
+func polygonWKT(polygon [][2]float64) string {
+	points := make([]string, 0, len(polygon)+1)
+	for i := 0; i <= len(polygon); i++ {
+		p := polygon[i%len(polygon)]
+		points = append(points, fmt.Sprintf("%f %f", p[1], p[0]))
+	}
+	return "SRID=4326;POLYGON((" + strings.Join(points, ",") + "))"
+}
+
+func (s *postgresStore) Ping(ctx context.Context) error { return s.db.PingContext(ctx) }
+
+func (s *postgresStore) Close() error { return s.db.Close() }
+
+// scanJSONRows decodes a single JSON column from every row.
+func scanJSONRows[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+	var out []T
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// scanJSONRow decodes a single JSON column, mapping no rows to errNotFound.
+func scanJSONRow(row *sql.Row, v interface{}) error {
+	var data []byte
+	if err := row.Scan(&data); errors.Is(err, sql.ErrNoRows) {
+		return errNotFound
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// checkAffected maps an update or delete that touched no rows to errNotFound.
+func checkAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errNotFound
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+const (
+	maxTemplateLength = 1024
+	// maxMessageLength caps a rendered message; longer ones fall back to
+	// the default message.
+	maxMessageLength = 4096
+	// maxCachedTemplates bounds the compiled template cache, which dry
+	// runs and edits would otherwise grow with every template tried.
+	maxCachedTemplates = 256
+)
+
+var (
+	templateMu    sync.Mutex
+	templateCache = map[string]*template.Template{}
+
+	errMessageTooLong = fmt.Errorf("rendered message longer than %d bytes", maxMessageLength)
+)
+
+// alertTemplateData is the data available to criterion message templates,
+// e.g. "{{.Callsign}} at {{.Altitude}}ft, {{.Distance}}nm {{.Bearing}}".
+type alertTemplateData struct {
+	ICAO       string
+	Callsign   string
+	Squawk     string
+	Latitude   float64
+	Longitude  float64
+	Altitude   int
+	Speed      float64
+	Track      float64
+	Event      string
+	Severity   string
+	Zone       string
+	Distance   float64 // Nautical miles from the reference point, rounded to 0.1
+	Bearing    string  // Compass direction of the aircraft from the reference point
+	BearingDeg float64
+	Message    string // The default alert message
+
+	// From the registration database, empty when it has no entry.
+	Registration string
+	Type         string
+	Operator     string
+	// From the routes API, empty when it has no route, e.g. "LHR→JFK",
+	// "LHR" and "JFK".
+	Route       string
+	Origin      string
+	Destination string
+}
+
+// compileTemplate parses a message template, reusing a cached copy.
+func compileTemplate(text string) (*template.Template, error) {
+	if len(text) > maxTemplateLength {
+		return nil, fmt.Errorf("message template longer than %d characters", maxTemplateLength)
+	}
+	templateMu.Lock()
+	defer templateMu.Unlock()
+	if tmpl, ok := templateCache[text]; ok {
+		return tmpl, nil
+	}
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// Templates run under mu for every matching alert, so they get no
+	// loops or calls that could make rendering arbitrarily slow.
+	if len(tmpl.Templates()) > 1 {
+		return nil, errors.New("message template must not use define or block")
+	}
+	if err := checkTemplateNode(tmpl.Tree.Root); err != nil {
+		return nil, err
+	}
+	if len(templateCache) >= maxCachedTemplates {
+		clear(templateCache)
+	}
+	templateCache[text] = tmpl
+	return tmpl, nil
+}
+
+// checkTemplateNode rejects range and template actions anywhere in a parsed
+// template.
+func checkTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		return checkTemplateBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkTemplateBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return errors.New("message template must not use range")
+	case *parse.TemplateNode:
+		return errors.New("message template must not use template")
+	}
+	return nil
+}
+
+func checkTemplateBranch(b *parse.BranchNode) error {
+	if err := checkTemplateNode(b.List); err != nil {
+		return err
+	}
+	return checkTemplateNode(b.ElseList)
+}
+
+// limitedBuffer is a buffer that fails writes past maxMessageLength.
+type limitedBuffer struct{ buf bytes.Buffer }
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > maxMessageLength {
+		return 0, errMessageTooLong
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string { return b.buf.String() }
+
+// renderAlertMessage renders the criterion's message template, falling back
+// to the default message if there is none or rendering fails. Distance and
+// bearing are measured from the criterion's zone, if any, or else from the
+// receiver. Callers must hold mu.
+func renderAlertMessage(criterion *AlertCriteria, aircraft Aircraft, event, message string) string {
+	if criterion.MessageTemplate == "" {
+		return message
+	}
+	tmpl, err := compileTemplate(criterion.MessageTemplate)
+	if err != nil {
+		return message
+	}
+
+	data := alertTemplateData{
+		ICAO:      aircraft.ICAO,
+		Callsign:  aircraft.Callsign,
+		Squawk:    aircraft.Squawk,
+		Latitude:  aircraft.Latitude,
+		Longitude: aircraft.Longitude,
+		Altitude:  aircraft.Altitude,
+		Speed:     aircraft.Speed,
+		Track:     aircraft.Track,
+		Event:     event,
+		Severity:  criterion.Severity,
+		Zone:      criterion.Zone,
+		Message:   message,
+	}
+	if reg := lookupRegistration(aircraft.ICAO); reg != nil {
+		data.Registration, data.Type, data.Operator = reg.Registration, reg.Type, reg.Operator
+	}
+	if route := lookupRoute(aircraft); route != nil {
+		data.Route = describeRoute(route)
+		data.Origin, data.Destination = route.Origin, route.Destination
+		if route.OriginIATA != "" && route.DestinationIATA != "" {
+			data.Origin, data.Destination = route.OriginIATA, route.DestinationIATA
+		}
+	}
+	lat, lon, from := receiver.Latitude, receiver.Longitude, receiver.set()
+	if zone, ok := zones[criterion.Zone]; ok {
+		lat, lon = zone.center()
+		from = true
+	}
+	if from {
+		data.Distance = math.Round(distanceNM(lat, lon, aircraft.Latitude, aircraft.Longitude)*10) / 10
+		data.BearingDeg = math.Round(bearingDeg(lat, lon, aircraft.Latitude, aircraft.Longitude))
+		data.Bearing = compassPoint(data.BearingDeg)
+	}
+
+	var buf limitedBuffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return message
+	}
+	return buf.String()
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// TFRConfig polls the FAA for active Temporary Flight Restrictions and keeps
+// a zone for each, named "TFR <NOTAM>", e.g. "TFR 4/1234". With criteria
+// set, each zone also gets a criterion alerting when aircraft enter or leave
+// it; zones and criteria go once the restriction lapses.
+type TFRConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URL returns the restrictions as GeoJSON with the properties of the
+	// FAA's TFR map layer (NOTAM_KEY, TITLE, LEGAL, STATE, CNS_LOCATION_ID).
+	URL      string        `yaml:"url"`
+	Interval time.Duration `yaml:"interval"`
+	// Types limits the import to these restriction types, e.g.
+	// [SECURITY, VIP]; empty imports every one.
+	Types    []string `yaml:"types"`
+	Criteria bool     `yaml:"criteria"`
+	Severity string   `yaml:"severity"` // Of the created criteria
+}
+
+func defaultTFRConfig() TFRConfig {
+	return TFRConfig{
+		URL:      "https://tfr.faa.gov/geoserver/TFR/ows?service=WFS&version=1.1.0&request=GetFeature&typeName=TFR:V_TFR_LOC&maxFeatures=1000&outputFormat=application/json&srsname=EPSG:4326",
+		Interval: 15 * time.Minute,
+		Severity: severityWarning,
+	}
+}
+
+func (c TFRConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return errors.New("tfr.url must be an http or https URL")
+	}
+	if c.Interval < time.Minute {
+		return errors.New("tfr.interval must be at least 1m")
+	}
+	switch c.Severity {
+	case severityInfo, severityWarning, severityCritical:
+	default:
+		return fmt.Errorf("tfr.severity: unknown severity %q", c.Severity)
+	}
+	return nil
+}
+
+// tfrPreset marks the criteria created for restrictions, which the poller
+// removes again once their zone is gone.
+const tfrPreset = "tfr"
+
+var (
+	// tfrZones holds the zones of the active restrictions, so a reload or
+	// restore replacing zones can put them back. Guarded by mu.
+	tfrZones = map[string]Zone{}
+	// tfrUpdated and tfrError record the last poll. Guarded by mu.
+	tfrUpdated time.Time
+	tfrError   string
+)
+
+// TFRStatus is the reply of GET /api/v1/tfrs.
+type TFRStatus struct {
+	Updated *time.Time `json:"updated,omitempty"` // Last successful poll
+	Error   string     `json:"error,omitempty"`   // Of the last poll, if it failed
+	Zones   []Zone     `json:"zones"`
+}
+
+// runTFRPoller fetches the restrictions now and then every cfg.Interval.
+func runTFRPoller(cfg TFRConfig) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		fetched, err := fetchTFRs(client, cfg)
+		mu.Lock()
+		if err != nil {
+			log.Printf("TFR: %v", err)
+			tfrError = err.Error()
+		} else {
+			applyTFRs(cfg, fetched)
+			tfrUpdated, tfrError = time.Now(), ""
+		}
+		mu.Unlock()
+		<-ticker.C
+	}
+}
+
+func fetchTFRs(client *http.Client, cfg TFRConfig) ([]Zone, error) {
+	resp, err := client.Get(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching restrictions: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching restrictions: status %s", resp.Status)
+	}
+	list, err := readTFRs(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading restrictions: %w", err)
+	}
+	if len(cfg.Types) == 0 {
+		return list, nil
+	}
+	kept := list[:0]
+	for _, z := range list {
+		if slices.ContainsFunc(cfg.Types, func(t string) bool { return strings.EqualFold(t, z.TFR.Type) }) {
+			kept = append(kept, z)
+		}
+	}
+	return kept, nil
+}
+
+// readTFRs turns a GeoJSON feature collection of restrictions into zones.
+// A restriction drawn as several polygons gets a zone for each, the later
+// ones suffixed " (2)", " (3)" and so on. Features without an area are
+// skipped. The layer carries no vertical limits, so the zones have none.
+func readTFRs(r io.Reader) ([]Zone, error) {
+	var collection struct {
+		Features []struct {
+			Properties struct {
+				NotamKey string `json:"NOTAM_KEY"`
+				Title    string `json:"TITLE"`
+				Type     string `json:"LEGAL"`
+				State    string `json:"STATE"`
+				Facility string `json:"CNS_LOCATION_ID"`
+			} `json:"properties"`
+			Geometry *struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, err
+	}
+
+	var list []Zone
+	parts := map[string]int{}
+	for _, f := range collection.Features {
+		p := f.Properties
+		// NOTAM_KEY is the NOTAM number followed by its series, e.g.
+		// "4/1234-1-FDC-F".
+		id, _, _ := strings.Cut(strings.TrimSpace(p.NotamKey), "-")
+		if id == "" || f.Geometry == nil {
+			continue
+		}
+		var polygons [][][][2]float64
+		switch f.Geometry.Type {
+		case "Polygon":
+			var polygon [][][2]float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &polygon); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+			polygons = append(polygons, polygon)
+		case "MultiPolygon":
+			if err := json.Unmarshal(f.Geometry.Coordinates, &polygons); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+		default:
+			continue
+		}
+		info := &TFRInfo{
+			NotamID:  id,
+			Type:     strings.ToUpper(strings.TrimSpace(p.Type)),
+			Title:    strings.TrimSpace(p.Title),
+			State:    strings.TrimSpace(p.State),
+			Facility: strings.TrimSpace(p.Facility),
+		}
+		for _, polygon := range polygons {
+			if len(polygon) == 0 {
+				continue
+			}
+			// GeoJSON points are [lon, lat]; only the outer ring is kept.
+			z := Zone{Class: "TFR", TFR: info}
+			for _, pt := range polygon[0] {
+				z.Polygon = append(z.Polygon, [2]float64{pt[1], pt[0]})
+			}
+			parts[id]++
+			z.Name = "TFR " + id
+			if n := parts[id]; n > 1 {
+				z.Name = fmt.Sprintf("%s (%d)", z.Name, n)
+			}
+			if err := z.validate(); err != nil {
+				return nil, err
+			}
+			list = append(list, z)
+		}
+	}
+	return list, nil
+}
+
+// applyTFRs replaces the restriction zones with fetched, leaving zones from
+// the configuration alone, and brings the created criteria in line.
+// Callers must hold mu.
+func applyTFRs(cfg TFRConfig, fetched []Zone) {
+	previous := len(tfrZones)
+	for name := range tfrZones {
+		if zones[name].TFR != nil {
+			delete(zones, name)
+		}
+	}
+	tfrZones = make(map[string]Zone, len(fetched))
+	for _, z := range fetched {
+		if _, taken := zones[z.Name]; taken {
+			log.Printf("TFR: zone %q already exists, skipping the restriction", z.Name)
+			continue
+		}
+		zones[z.Name] = z
+		tfrZones[z.Name] = z
+	}
+	indexZones()
+	if len(tfrZones) != previous {
+		log.Printf("TFR: %d restriction zones active", len(tfrZones))
+	}
+	if cfg.Criteria {
+		if err := syncTFRCriteria(cfg); err != nil {
+			log.Printf("TFR: error updating criteria: %v", err)
+		}
+	}
+}
+
+// syncTFRCriteria creates a criterion for each restriction zone without
+// one and deletes those whose zone has lapsed. Existing criteria are left
+// as they are, so an operator can disable or adjust them. Callers must hold
+// mu.
+func syncTFRCriteria(cfg TFRConfig) error {
+	criteria, err := store.ListCriteria()
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, criterion := range criteria {
+		if criterion.Preset != tfrPreset {
+			continue
+		}
+		if _, ok := tfrZones[criterion.Zone]; !ok {
+			if err := store.DeleteCriterion(criterion.ID); err != nil && !errors.Is(err, errNotFound) {
+				return err
+			}
+			continue
+		}
+		existing[criterion.Zone] = true
+	}
+
+	names := make([]string, 0, len(tfrZones))
+	for name := range tfrZones {
+		if !existing[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tags := []string{"tfr"}
+		if t := tfrZones[name].TFR.Type; t != "" {
+			tags = append(tags, strings.ToLower(strings.ReplaceAll(t, " ", "-")))
+		}
+		criterion := AlertCriteria{
+			ID:       tfrCriterionID(name),
+			Zone:     name,
+			Severity: cfg.Severity,
+			Tags:     tags,
+			Preset:   tfrPreset,
+			Enabled:  true,
+		}
+		if err := validateCriterion(&criterion); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := store.SaveCriterion(criterion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tfrCriterionID derives the criterion ID from the zone name, so the same
+// restriction keeps the same criterion, e.g. "tfr-4-1234-2" for
+// "TFR 4/1234 (2)".
+func tfrCriterionID(zone string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(zone) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// restoreTFRZones adds the restriction zones back after zones were
+// replaced, unless a zone of the same name took their place. Callers must
+// hold mu.
+func restoreTFRZones() {
+	for name, z := range tfrZones {
+		if _, taken := zones[name]; !taken {
+			zones[name] = z
+		}
+	}
+}
+
+// handleTFRs lists the zones of the active restrictions and the state of
+// the last poll.
+func handleTFRs(c *jacked.Context) error {
+	mu.RLock()
+	if !activeConfig.TFR.Enabled {
+		mu.RUnlock()
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "TFR polling is not enabled in the server configuration"})
+	}
+	status := TFRStatus{Error: tfrError, Zones: make([]Zone, 0, len(tfrZones))}
+	if !tfrUpdated.IsZero() {
+		updated := tfrUpdated
+		status.Updated = &updated
+	}
+	for _, z := range tfrZones {
+		status.Zones = append(status.Zones, z)
+	}
+	mu.RUnlock()
+	sort.Slice(status.Zones, func(i, j int) bool { return status.Zones[i].Name < status.Zones[j].Name })
+	return c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSConfig makes the HTTP and gRPC servers speak TLS, with certificate
+// files or with certificates obtained from Let's Encrypt.
+type TLSConfig struct {
+	CertFile string         `yaml:"cert_file"` // PEM certificate chain, re-read when it changes
+	KeyFile  string         `yaml:"key_file"`
+	Autocert AutocertConfig `yaml:"autocert"`
+	// HTTPListen serves plain HTTP on a second address, redirecting to
+	// HTTPS and answering ACME HTTP-01 challenges. Autocert needs it on
+	// port 80 and defaults it to ":80".
+	HTTPListen string `yaml:"http_listen"`
+}
+
+// AutocertConfig obtains and renews certificates with ACME HTTP-01.
+type AutocertConfig struct {
+	Domains      []string `yaml:"domains"` // Names to request certificates for; empty disables autocert
+	Email        string   `yaml:"email"`   // Contact for expiry notices from the CA
+	CacheDir     string   `yaml:"cache_dir"`
+	DirectoryURL string   `yaml:"directory_url"` // Default Let's Encrypt; e.g. its staging URL for testing
+}
+
+func defaultTLSConfig() TLSConfig {
+	return TLSConfig{Autocert: AutocertConfig{CacheDir: "autocert"}}
+}
+
+func (c TLSConfig) enabled() bool {
+	return c.CertFile != "" || len(c.Autocert.Domains) > 0
+}
+
+func (c *TLSConfig) validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("tls.cert_file and tls.key_file must be set together")
+	}
+	if c.CertFile != "" && len(c.Autocert.Domains) > 0 {
+		return errors.New("tls: use either cert_file and key_file or autocert, not both")
+	}
+	if len(c.Autocert.Domains) > 0 && c.HTTPListen == "" {
+		c.HTTPListen = ":80"
+	}
+	return nil
+}
+
+// tlsEnabled is set when the server speaks HTTPS, so responses can carry
+// HSTS.
+var tlsEnabled bool
+
+// newTLS returns the TLS configuration of the servers and the handler of
+// the plain HTTP listener.
+func newTLS(cfg TLSConfig, listen string) (*tls.Config, http.Handler, error) {
+	if len(cfg.Autocert.Domains) > 0 {
+		if err := os.MkdirAll(cfg.Autocert.CacheDir, 0o700); err != nil {
+			return nil, nil, err
+		}
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Autocert.Domains...),
+			Cache:      autocert.DirCache(cfg.Autocert.CacheDir),
+			Email:      cfg.Autocert.Email,
+		}
+		if cfg.Autocert.DirectoryURL != "" {
+			m.Client = &acme.Client{DirectoryURL: cfg.Autocert.DirectoryURL}
+		}
+		return m.TLSConfig(), m.HTTPHandler(redirectHTTPS(listen)), nil
+	}
+
+	certs := &certFiles{cert: cfg.CertFile, key: cfg.KeyFile}
+	if _, err := certs.GetCertificate(nil); err != nil {
+		return nil, nil, err
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: certs.GetCertificate,
+	}
+	return tlsCfg, redirectHTTPS(listen), nil
+}
+
+// redirectHTTPS sends plain HTTP requests to the same URL on the HTTPS
+// listen address.
+func redirectHTTPS(listen string) http.Handler {
+	_, port, _ := net.SplitHostPort(listen)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// certFiles serves a certificate from files, loading it again when the
+// files change, so renewals by an external client need no restart.
+type certFiles struct {
+	cert, key string
+
+	mu       sync.Mutex
+	loaded   *tls.Certificate
+	modified time.Time
+}
+
+func (c *certFiles) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var modified time.Time
+	for _, path := range []string{c.cert, c.key} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return c.fallback(err)
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+	if c.loaded != nil && modified.Equal(c.modified) {
+		return c.loaded, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.cert, c.key)
+	if err != nil {
+		return c.fallback(fmt.Errorf("loading %s: %w", c.cert, err))
+	}
+	if c.loaded != nil {
+		log.Printf("TLS: loaded the renewed certificate from %s", c.cert)
+	}
+	c.loaded, c.modified = &cert, modified
+	return c.loaded, nil
+}
+
+// fallback keeps serving the last good certificate while the files are
+// being replaced. Callers must hold c.mu.
+func (c *certFiles) fallback(err error) (*tls.Certificate, error) {
+	if c.loaded == nil {
+		return nil, err
+	}
+	return c.loaded, nil
+}
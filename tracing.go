@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// TracingConfig exports OpenTelemetry traces of each update's way from
+// ingest through evaluation and broadcast to the notifications it causes,
+// over OTLP/HTTP.
+type TracingConfig struct {
+	Endpoint    string            `yaml:"endpoint"` // Collector URL, e.g. http://localhost:4318; empty disables tracing
+	Headers     map[string]string `yaml:"headers"`  // Sent with every export, e.g. an API key
+	SampleRatio float64           `yaml:"sample_ratio"`
+	ServiceName string            `yaml:"service_name"`
+}
+
+func defaultTracingConfig() TracingConfig {
+	return TracingConfig{SampleRatio: 1, ServiceName: "aircraft-alert"}
+}
+
+func (c TracingConfig) validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return errors.New("tracing.sample_ratio must be between 0 and 1")
+	}
+	return nil
+}
+
+// tracer starts the spans. Until startTracing installs a provider it is a
+// no-op, so untraced servers pay next to nothing.
+var (
+	tracer         = otel.Tracer("aircraft-alert")
+	tracerProvider *sdktrace.TracerProvider
+	propagator     = propagation.TraceContext{}
+)
+
+// startTracing installs an OTLP exporter. Feeders sending a W3C traceparent
+// header (or gRPC metadata) get their trace continued.
+func startTracing(cfg TracingConfig) error {
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(cfg.Endpoint),
+		otlptracehttp.WithHeaders(cfg.Headers))
+	if err != nil {
+		return fmt.Errorf("tracing: %w", err)
+	}
+	tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	log.Printf("Exporting traces to %s", cfg.Endpoint)
+	return nil
+}
+
+// stopTracing flushes the spans not yet exported.
+func stopTracing(ctx context.Context) {
+	if tracerProvider == nil {
+		return
+	}
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		log.Printf("Tracing: %v", err)
+	}
+}
+
+// requestTraceContext continues the trace a feeder sent along with its
+// HTTP request.
+func requestTraceContext(r *http.Request) context.Context {
+	return propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+}
+
+// grpcTraceContext continues the trace a feeder sent in gRPC metadata.
+func grpcTraceContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return propagator.Extract(ctx, metadataCarrier(md))
+}
+
+// metadataCarrier reads trace headers from incoming gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// traceParent returns the W3C traceparent of the span in ctx, "" when it is
+// not sampled. Alerts carry it so their notifications join the trace.
+func traceParent(ctx context.Context) string {
+	if !trace.SpanContextFromContext(ctx).IsSampled() {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// alertContext returns a context continuing the trace of the update that
+// raised the alert.
+func alertContext(ctx context.Context, alert Alert) context.Context {
+	if alert.Trace == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": alert.Trace})
+}
+
+// endSpan records err, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func icaoAttr(icao string) attribute.KeyValue {
+	return attribute.String("aircraft.icao", icao)
+}
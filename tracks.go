@@ -0,0 +1,57 @@
+package main
+
+import "time"
+
+const (
+	trackRetention  = 30 * time.Minute
+	maxTrackPoints  = 720
+	trackPruneEvery = time.Minute
+)
+
+// tracks holds recent positions per ICAO, oldest first. Guarded by mu.
+var tracks = map[string][]Aircraft{}
+
+// recordTrackPoint appends the aircraft to its track history, dropping points
+// older than trackRetention. Callers must hold mu.
+func recordTrackPoint(aircraft Aircraft) {
+	points := append(tracks[aircraft.ICAO], aircraft)
+	cutoff := aircraft.Timestamp.Add(-trackRetention)
+	start := 0
+	for start < len(points) && points[start].Timestamp.Before(cutoff) {
+		start++
+	}
+	if len(points)-start > maxTrackPoints {
+		start = len(points) - maxTrackPoints
+	}
+	tracks[aircraft.ICAO] = points[start:]
+}
+
+// recentTrack returns the points recorded for icao since the given time.
+// Callers must hold mu.
+func recentTrack(icao string, since time.Time) []Aircraft {
+	points := tracks[icao]
+	for i, p := range points {
+		if !p.Timestamp.Before(since) {
+			return points[i:]
+		}
+	}
+	return nil
+}
+
+// pruneTracks periodically forgets aircraft that have not reported within
+// trackRetention.
+func pruneTracks() {
+	ticker := time.NewTicker(trackPruneEvery)
+	defer ticker.Stop()
+	for range ticker.C {
+		cutoff := time.Now().Add(-trackRetention)
+		mu.Lock()
+		for icao, points := range tracks {
+			if len(points) == 0 || points[len(points)-1].Timestamp.Before(cutoff) {
+				delete(tracks, icao)
+				forgetAircraftState(icao)
+			}
+		}
+		mu.Unlock()
+	}
+}
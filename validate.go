@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// What ingest does with an update failing the checks of its fields.
+const (
+	validationReject = "reject" // Refuse it with the problems
+	validationFlag   = "flag"   // Accept it and report the problems
+)
+
+// ValidationConfig sets the checks aircraft updates pass at ingest. An
+// update without a valid ICAO address or position is always refused; with
+// mode flag, other problems are reported to the feeder and counted but the
+// update is accepted.
+type ValidationConfig struct {
+	Mode        string  `yaml:"mode"`         // reject or flag
+	MinAltitude int     `yaml:"min_altitude"` // Feet
+	MaxAltitude int     `yaml:"max_altitude"` // Feet
+	MaxSpeed    float64 `yaml:"max_speed"`    // Knots
+	// MaxImpliedSpeed refuses a position this far, in knots, from the
+	// aircraft's last one as a corrupt message; 0 turns the check off.
+	MaxImpliedSpeed float64 `yaml:"max_implied_speed"`
+}
+
+func defaultValidationConfig() ValidationConfig {
+	return ValidationConfig{Mode: validationReject, MinAltitude: -2000, MaxAltitude: 60000, MaxSpeed: 2000, MaxImpliedSpeed: 2500}
+}
+
+func (c ValidationConfig) validate() error {
+	if c.Mode != validationReject && c.Mode != validationFlag {
+		return fmt.Errorf("unknown validation.mode %q", c.Mode)
+	}
+	if c.MinAltitude >= c.MaxAltitude {
+		return fmt.Errorf("validation.min_altitude must be below validation.max_altitude")
+	}
+	if c.MaxSpeed <= 0 {
+		return fmt.Errorf("validation.max_speed must be positive")
+	}
+	if c.MaxImpliedSpeed < 0 {
+		return fmt.Errorf("validation.max_implied_speed must not be negative")
+	}
+	return nil
+}
+
+var validationConfig = defaultValidationConfig()
+
+// validationStats counts the updates that failed validation, for
+// GET /api/v1/stats.
+var validationStats struct {
+	rejected atomic.Uint64
+	flagged  atomic.Uint64
+	outliers atomic.Uint64
+}
+
+// ValidationStats counts the updates that failed the ingest checks.
+type ValidationStats struct {
+	Rejected uint64 `json:"rejected"` // Refused to the feeder
+	Flagged  uint64 `json:"flagged"`  // Accepted with validation.mode: flag
+	Outliers uint64 `json:"outliers"` // Rejected for implying an impossible speed
+}
+
+// FieldError is a problem with one field of a request body.
+type FieldError struct {
+	Field   string `json:"field"` // JSON name, e.g. alt_baro
+	Message string `json:"message"`
+}
+
+var (
+	// icaoPattern matches a 24-bit address; a leading ~ marks addresses
+	// that are not ICAO-assigned, as readsb reports for TIS-B targets.
+	icaoPattern     = regexp.MustCompile(`^~?[0-9A-F]{6}$`)
+	callsignPattern = regexp.MustCompile(`^[A-Z0-9]{1,8}$`)
+	squawkPattern   = regexp.MustCompile(`^[0-7]{4}$`)
+)
+
+// checkAircraft tidies an update from a feeder in place (upper case, no
+// surrounding spaces, none of the fields the server derives) and checks its
+// fields. It returns
+// the problems found and whether the update may still be accepted, which
+// validationConfig decides for problems other than the address and
+// position.
+func checkAircraft(a *Aircraft) ([]FieldError, bool) {
+	a.ICAO = strings.ToUpper(strings.TrimSpace(a.ICAO))
+	a.Callsign = strings.ToUpper(strings.TrimSpace(a.Callsign))
+	a.Squawk = strings.TrimSpace(a.Squawk)
+	a.Estimated, a.AltitudeAGL = false, nil
+	a.Airport, a.AirportDistance = "", 0
+
+	var problems []FieldError
+	fatal := false
+	add := func(field, format string, v ...any) {
+		problems = append(problems, FieldError{Field: field, Message: fmt.Sprintf(format, v...)})
+	}
+	if !icaoPattern.MatchString(a.ICAO) {
+		add("icao", "must be 6 hex digits")
+		fatal = true
+	}
+	if math.IsNaN(a.Latitude) || a.Latitude < -90 || a.Latitude > 90 {
+		add("lat", "must be between -90 and 90")
+		fatal = true
+	}
+	if math.IsNaN(a.Longitude) || a.Longitude < -180 || a.Longitude > 180 {
+		add("lon", "must be between -180 and 180")
+		fatal = true
+	}
+	if !fatal {
+		if dist, implied, ok := checkOutlier(*a, time.Now()); ok {
+			add("lat", "position is %.1f nm from the last one, implying %.0f kt", dist, implied)
+			validationStats.outliers.Add(1)
+			fatal = true
+		}
+	}
+	cfg := validationConfig
+	if a.Altitude < cfg.MinAltitude || a.Altitude > cfg.MaxAltitude {
+		add("alt_baro", "must be between %d and %d", cfg.MinAltitude, cfg.MaxAltitude)
+	}
+	if math.IsNaN(a.Speed) || a.Speed < 0 || a.Speed > cfg.MaxSpeed {
+		add("gs", "must be between 0 and %g", cfg.MaxSpeed)
+	}
+	if math.IsNaN(a.Track) || a.Track < 0 || a.Track > 360 {
+		add("track", "must be between 0 and 360")
+	}
+	if a.Callsign != "" && !callsignPattern.MatchString(a.Callsign) {
+		add("callsign", "must be up to 8 letters and digits")
+	}
+	if a.Squawk != "" && !squawkPattern.MatchString(a.Squawk) {
+		add("squawk", "must be 4 octal digits")
+	}
+
+	switch {
+	case len(problems) == 0:
+		return nil, true
+	case fatal || cfg.Mode == validationReject:
+		validationStats.rejected.Add(1)
+		return problems, false
+	default:
+		validationStats.flagged.Add(1)
+		return problems, true
+	}
+}
+
+// fieldErrorSummary joins problems into one line for logs and gRPC errors.
+func fieldErrorSummary(problems []FieldError) string {
+	parts := make([]string, len(problems))
+	for i, p := range problems {
+		parts[i] = p.Field + " " + p.Message
+	}
+	return strings.Join(parts, "; ")
+}
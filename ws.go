@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsMaxMessage = 4096
+)
+
+// The SSE endpoint already allows any origin, so WebSocket does too.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// wsMessage is the JSON frame exchanged on /api/v1/ws. The server sends the
+// hub's events as {"type": "alert", "aircraftUpdate", "aircraftRemoved",
+// "expired" or "server_shutdown", "data": {...}}; clients send {"type": "subscribe", "filter": {...}} to
+// narrow them down.
+type wsMessage struct {
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	Filter *streamFilter   `json:"filter,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// streamFilter selects the live events a client receives. Empty fields
+// match everything.
+type streamFilter struct {
+	Events      []string    `json:"events,omitempty"`       // alert, aircraftUpdate, aircraftRemoved, expired
+	ICAO        []string    `json:"icao,omitempty"`         // Hex addresses
+	BBox        *[4]float64 `json:"bbox,omitempty"`         // [min_lat, min_lon, max_lat, max_lon]
+	MinSeverity string      `json:"min_severity,omitempty"` // Alerts only
+}
+
+func (f *streamFilter) validate() error {
+	for _, e := range f.Events {
+		if e != "alert" && e != "aircraftUpdate" && e != "aircraftRemoved" && e != "expired" {
+			return fmt.Errorf("unknown event %q", e)
+		}
+	}
+	for i, icao := range f.ICAO {
+		f.ICAO[i] = strings.ToUpper(icao)
+	}
+	if b := f.BBox; b != nil && (b[0] > b[2] || b[1] > b[3]) {
+		return errors.New("bbox must be [min_lat, min_lon, max_lat, max_lon]")
+	}
+	if _, ok := severityRank[f.MinSeverity]; f.MinSeverity != "" && !ok {
+		return fmt.Errorf("unknown min_severity %q", f.MinSeverity)
+	}
+	return nil
+}
+
+// streamEvent is a hub event as the filters see it. The hub tests every
+// event against each client's filter, so the data is decoded at most once.
+type streamEvent struct {
+	name string
+	data []byte
+
+	decoded  bool
+	valid    bool
+	aircraft Aircraft
+	severity string
+}
+
+func newStreamEvent(message []byte) *streamEvent {
+	name, data := parseSSEFrame(message)
+	return &streamEvent{name: name, data: data}
+}
+
+// decode reads the aircraft, and an alert's severity, from the data on first
+// use. It reports false if the data is not valid JSON.
+func (e *streamEvent) decode() bool {
+	if !e.decoded {
+		e.decoded = true
+		if e.name == "alert" {
+			var alert Alert
+			e.valid = json.Unmarshal(e.data, &alert) == nil
+			e.aircraft, e.severity = alert.Aircraft, alert.Severity
+		} else {
+			e.valid = json.Unmarshal(e.data, &e.aircraft) == nil
+		}
+	}
+	return e.valid
+}
+
+// matches reports whether an event passes the filter. data is the event's
+// JSON: an Aircraft for aircraftUpdate, an Alert for alert and just the
+// ICAO address for aircraftRemoved and expired, which ignore the bounding
+// box so clients drop aircraft wherever they last drew them.
+func (f *streamFilter) matches(event string, data []byte) bool {
+	return f.matchesEvent(&streamEvent{name: event, data: data})
+}
+
+func (f *streamFilter) matchesEvent(e *streamEvent) bool {
+	if e.name == "server_shutdown" {
+		return true // Always sent, as the last event before the stream closes
+	}
+	if len(f.Events) > 0 && !slices.Contains(f.Events, e.name) {
+		return false
+	}
+	if len(f.ICAO) == 0 && f.BBox == nil && f.MinSeverity == "" {
+		return true
+	}
+	if !e.decode() {
+		return false
+	}
+	aircraft := e.aircraft
+	if len(f.ICAO) > 0 && !slices.Contains(f.ICAO, aircraft.ICAO) {
+		return false
+	}
+	if b := f.BBox; b != nil && e.name != "aircraftRemoved" && e.name != "expired" && (aircraft.Latitude < b[0] || aircraft.Latitude > b[2] || aircraft.Longitude < b[1] || aircraft.Longitude > b[3]) {
+		return false
+	}
+	return f.MinSeverity == "" || e.name != "alert" || severityRank[e.severity] >= severityRank[f.MinSeverity]
+}
+
+// parseStreamFilter reads a filter from query parameters, e.g.
+// ?events=alert&min_severity=warning&bbox=51,-1,52,1&icao=AABBCC. Lists may
+// be comma-separated or repeated.
+func parseStreamFilter(query url.Values) (*streamFilter, error) {
+	list := func(name string) []string {
+		var out []string
+		for _, v := range query[name] {
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					out = append(out, item)
+				}
+			}
+		}
+		return out
+	}
+	f := &streamFilter{Events: list("events"), ICAO: list("icao"), MinSeverity: query.Get("min_severity")}
+	if v := query.Get("bbox"); v != "" {
+		bbox, err := parseBBox(v)
+		if err != nil {
+			return nil, err
+		}
+		f.BBox = (*[4]float64)(&bbox)
+	}
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseBBox parses a min_lat,min_lon,max_lat,max_lon query parameter.
+func parseBBox(v string) (geoBox, error) {
+	var bbox geoBox
+	parts := strings.Split(v, ",")
+	if len(parts) != 4 {
+		return bbox, errors.New("bbox must be min_lat,min_lon,max_lat,max_lon")
+	}
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return bbox, errors.New("bbox must be min_lat,min_lon,max_lat,max_lon")
+		}
+		bbox[i] = n
+	}
+	for _, lat := range []float64{bbox[0], bbox[2]} {
+		if !(lat >= -90 && lat <= 90) {
+			return bbox, errors.New("bbox latitudes must be between -90 and 90")
+		}
+	}
+	for _, lon := range []float64{bbox[1], bbox[3]} {
+		if !(lon >= -180 && lon <= 180) {
+			return bbox, errors.New("bbox longitudes must be between -180 and 180")
+		}
+	}
+	return bbox, nil
+}
+
+// parseSSEFrame splits a hub message ("event: X\ndata: Y\n\n") into its
+// event name and data.
+func parseSSEFrame(message []byte) (event string, data []byte) {
+	for _, line := range bytes.Split(bytes.TrimSpace(message), []byte("\n")) {
+		if v, ok := bytes.CutPrefix(line, []byte("event: ")); ok {
+			event = string(v)
+		} else if v, ok := bytes.CutPrefix(line, []byte("data: ")); ok {
+			data = v
+		}
+	}
+	return event, data
+}
+
+// handleWebSocket streams the same events as /api/v1/events over a WebSocket.
+// The initial filter comes from the same query parameters as /api/v1/events;
+// clients may send subscribe messages at any time to replace it.
+func handleWebSocket(c *jacked.Context) error {
+	initial, err := parseStreamFilter(c.Request.URL.Query())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	conn, err := wsUpgrader.Upgrade(c.Response, c.Request, nil)
+	if err != nil {
+		logRequestf(c.Request, "WebSocket: upgrade failed for %s: %v", c.Request.RemoteAddr, err)
+		return nil // Upgrade has already replied
+	}
+	defer conn.Close()
+
+	client := hub.newClient(c.Request.RemoteAddr + " (ws)")
+	client.Filter.Store(initial)
+	hub.register <- client
+	defer func() { hub.unregister <- client }()
+
+	var (
+		replies = make(chan wsMessage, 4)
+		done    = make(chan struct{}) // Closed when the reader stops
+		closed  = make(chan struct{}) // Closed when the writer stops
+	)
+	defer close(closed)
+	reply := func(msg wsMessage) {
+		select {
+		case replies <- msg:
+		case <-closed:
+		}
+	}
+	go func() {
+		defer close(done)
+		conn.SetReadLimit(wsMaxMessage)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error { return conn.SetReadDeadline(time.Now().Add(wsPongWait)) })
+		for {
+			var msg wsMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+					reply(wsMessage{Type: "error", Error: "Invalid message"})
+					continue
+				}
+				return
+			}
+			if msg.Type != "subscribe" {
+				reply(wsMessage{Type: "error", Error: "Unknown message type"})
+				continue
+			}
+			f := msg.Filter
+			if f == nil {
+				f = &streamFilter{}
+			}
+			if err := f.validate(); err != nil {
+				reply(wsMessage{Type: "error", Error: err.Error()})
+				continue
+			}
+			// Events the hub queued under the old filter still go out.
+			client.Filter.Store(f)
+			reply(wsMessage{Type: "subscribed", Filter: f})
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	log.Printf("WebSocket: client %s connected", client.ID)
+	for {
+		var err error
+		select {
+		case message, open := <-client.Send:
+			if !open {
+				if hub.stopped.Load() {
+					conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "Server shutting down"), time.Now().Add(wsWriteWait))
+				}
+				return nil
+			}
+			event, data := parseSSEFrame(message)
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = conn.WriteJSON(wsMessage{Type: event, Data: data})
+		case reply := <-replies:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = conn.WriteJSON(reply)
+		case <-ping.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+		case <-done:
+			log.Printf("WebSocket: client %s disconnected", client.ID)
+			return nil
+		}
+		if err != nil {
+			log.Printf("WebSocket: error writing to client %s: %v", client.ID, err)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sudo-Ivan/jacked-api/jacked"
+)
+
+// Zone is a named geographic area, either a circle (Center and Radius) or a
+// polygon of [lat, lon] vertices, optionally between a floor and a ceiling.
+type Zone struct {
+	Name    string       `json:"name"`
+	Center  *[2]float64  `json:"center,omitempty"` // [lat, lon]
+	Radius  float64      `json:"radius,omitempty"` // Nautical miles
+	Polygon [][2]float64 `json:"polygon,omitempty"`
+	// Vertical limits in feet, checked by criteria and enrichment; a zero
+	// ceiling means no upper limit.
+	Floor   int `json:"floor,omitempty"`
+	Ceiling int `json:"ceiling,omitempty"`
+	// Class is the airspace class of zones imported from OpenAIR, e.g. R.
+	Class string `json:"class,omitempty"`
+	// TFR is set on zones created from FAA flight restrictions.
+	TFR *TFRInfo `json:"tfr,omitempty"`
+}
+
+var (
+	// zones holds the configured zones by name. Guarded by mu.
+	zones = map[string]Zone{}
+	// zonePresence records which (criterion, ICAO) pairs are currently
+	// inside their criterion's zone. Guarded by mu.
+	zonePresence = map[string]bool{}
+)
+
+func (z Zone) validate() error {
+	if z.Name == "" {
+		return errors.New("zone name is required")
+	}
+	if z.Ceiling != 0 && z.Ceiling <= z.Floor {
+		return fmt.Errorf("zone %q: ceiling must be above the floor", z.Name)
+	}
+	switch {
+	case z.Center != nil && z.Radius > 0:
+		return nil
+	case len(z.Polygon) >= 3:
+		return nil
+	default:
+		return fmt.Errorf("zone %q needs either a center and radius or at least 3 polygon points", z.Name)
+	}
+}
+
+// contains reports whether the point lies inside the zone.
+func (z Zone) contains(lat, lon float64) bool {
+	if z.Center != nil && z.Radius > 0 {
+		return distanceNM(z.Center[0], z.Center[1], lat, lon) <= z.Radius
+	}
+	return pointInPolygon(lat, lon, z.Polygon)
+}
+
+// containsAircraft reports whether the aircraft is inside the zone, within
+// its vertical limits.
+func (z Zone) containsAircraft(aircraft Aircraft) bool {
+	if aircraft.Altitude < z.Floor || (z.Ceiling != 0 && aircraft.Altitude > z.Ceiling) {
+		return false
+	}
+	return z.contains(aircraft.Latitude, aircraft.Longitude)
+}
+
+// center returns the reference point of the zone: the circle centre or the
+// average of the polygon vertices.
+func (z Zone) center() (lat, lon float64) {
+	if z.Center != nil {
+		return z.Center[0], z.Center[1]
+	}
+	for _, p := range z.Polygon {
+		lat += p[0]
+		lon += p[1]
+	}
+	n := float64(len(z.Polygon))
+	return lat / n, lon / n
+}
+
+// readZones reads and validates the zones in the JSON file at path.
+func readZones(path string) (map[string]Zone, error) {
+	var list []Zone
+	if err := loadJSONFile(path, &list); err != nil {
+		return nil, err
+	}
+	loaded := make(map[string]Zone, len(list))
+	for _, z := range list {
+		if err := z.validate(); err != nil {
+			return nil, err
+		}
+		loaded[z.Name] = z
+	}
+	return loaded, nil
+}
+
+// zoneTransition turns the per-update match result of a zone criterion into
+// zone_entered and zone_exited events, each produced once per crossing.
+// Callers must hold mu.
+func zoneTransition(ac *AlertCriteria, aircraft Aircraft, inside bool) (event, message string, ok bool) {
+	key := ac.ID + "|" + aircraft.ICAO
+	if zonePresence[key] == inside {
+		return "", "", false
+	}
+	if inside {
+		zonePresence[key] = true
+		return eventZoneEntered, fmt.Sprintf("Aircraft entered zone %s: %s (%s)", ac.Zone, aircraft.Callsign, aircraft.ICAO), true
+	}
+	delete(zonePresence, key)
+	return eventZoneExited, fmt.Sprintf("Aircraft left zone %s: %s (%s)", ac.Zone, aircraft.Callsign, aircraft.ICAO), true
+}
+
+// forgetAircraftState drops per-criterion detector state for an aircraft
+// that is no longer tracked. State keys are "criterionID|ICAO[|ICAO]".
+// Callers must hold mu.
+func forgetAircraftState(icao string) {
+	for _, state := range []map[string]bool{zonePresence, loitering, signalLost, onGround, proximityPairs} {
+		for key := range state {
+			if slices.Contains(strings.Split(key, "|")[1:], icao) {
+				delete(state, key)
+			}
+		}
+	}
+	for _, kind := range []string{anomalyPositionJump, anomalySpeedMismatch, anomalyAltitudeJump, anomalyDuplicateICAO} {
+		delete(lastAnomaly, icao+"|"+kind)
+	}
+}
+
+// handleZonePositions returns the recorded positions inside a zone, by
+// default over the last hour or since ?since= (Unix seconds or RFC 3339).
+func handleZonePositions(c *jacked.Context) error {
+	since := time.Now().Add(-time.Hour)
+	if v := c.Request.URL.Query().Get("since"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid since timestamp"})
+		}
+		since = t
+	}
+
+	mu.RLock()
+	zone, ok := zones[c.Param("name")]
+	mu.RUnlock()
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Zone not found"})
+	}
+	positions, err := store.PositionsInZone(zone, since)
+	if err != nil {
+		return storeError(c, err)
+	}
+	if positions == nil {
+		positions = []Aircraft{}
+	}
+	return c.JSON(http.StatusOK, positions)
+}